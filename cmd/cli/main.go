@@ -4,15 +4,18 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"iter"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/yourorg/unpass/internal/audit"
 	"github.com/yourorg/unpass/internal/config"
 	"github.com/yourorg/unpass/internal/database"
 	"github.com/yourorg/unpass/internal/detector"
+	"github.com/yourorg/unpass/internal/input"
 	"github.com/yourorg/unpass/internal/parser"
 	"github.com/yourorg/unpass/internal/providers"
 	"github.com/yourorg/unpass/internal/report"
@@ -20,10 +23,30 @@ import (
 )
 
 var (
-	inputFile    string
-	outputFile   string
-	databasePath string
-	format       string
+	inputFiles    []string
+	outputFile    string
+	databasePath  string
+	format        string
+	passphraseEnv string
+	passphraseFd  int
+	keyFile       string
+	noPassword    bool
+	inputFormat   string
+	batchSize     int
+	csvMapRules   []string
+	csvMapFile    string
+	csvPreset     string
+	pwnedOutput   string
+	noPrefixIndex bool
+	tempDir       string
+	pwnedOnline   bool
+	pwnedRangeURL string
+	pwnedCacheDir string
+	pwnedCacheTTL time.Duration
+	pwnedWorkers  int
+	noPwnedPad    bool
+	minStrength   int
+	minCrackTime  time.Duration
 )
 
 func main() {
@@ -45,18 +68,86 @@ var auditCmd = &cobra.Command{
 	RunE:  runAudit,
 }
 
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Manage local audit databases",
+}
+
+var importPwnedCmd = &cobra.Command{
+	Use:   "import-pwned <hashes.txt>",
+	Short: "Compile a Pwned Passwords SHA-1 list into a searchable offline database",
+	Long: `Compile a Pwned Passwords download ("SHA1:count" per line) into a sorted binary file.
+The audit command looks hashes up by binary search over a memory-mapped file, so the full corpus can be checked offline without loading it into memory.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImportPwned,
+}
+
 func init() {
-	auditCmd.Flags().StringVarP(&inputFile, "file", "f", "", "Input credential file (JSON format)")
+	auditCmd.Flags().StringArrayVarP(&inputFiles, "file", "f", nil, "Input credential file, glob or directory (JSON, 1PUX, Proton Pass, CXF, KDBX or CSV format, or a Firefox profile directory; repeatable, \"-\" for stdin)")
 	auditCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file (default: stdout)")
 	auditCmd.Flags().StringVarP(&databasePath, "database", "d", "database", "Database directory path")
 	auditCmd.Flags().StringVarP(&format, "format", "", "table", "Output format (json, table)")
+	auditCmd.Flags().StringVar(&passphraseEnv, "passphrase-env", "", "Read the export passphrase from this environment variable")
+	auditCmd.Flags().IntVar(&passphraseFd, "passphrase-fd", -1, "Read the export passphrase from this file descriptor")
+	auditCmd.Flags().StringVar(&inputFormat, "input-format", "", "Input format (json, enpass, bitwarden, 1password, protonpass, cxf, firefox, keepass, browser-csv, csv; default: auto-detect)")
+	auditCmd.Flags().IntVar(&batchSize, "batch-size", 0, "Credentials handed to detectors per batch (default: 1000)")
+	auditCmd.Flags().StringVar(&keyFile, "keyfile", "", "KeePass key file")
+	auditCmd.Flags().BoolVar(&noPassword, "no-password", false, "Open the KeePass database with the key file only")
+	auditCmd.Flags().StringArrayVar(&csvMapRules, "map", nil, "CSV column mapping, e.g. \"url=Login URL,totp=OTP Secret,tags=Folder\" (repeatable)")
+	auditCmd.Flags().StringVar(&csvMapFile, "map-file", "", "Read CSV column mapping rules from this file")
+	auditCmd.Flags().StringVar(&csvPreset, "csv-preset", "", "CSV mapping preset ("+strings.Join(providers.CSVPresetNames(), ", ")+")")
+	auditCmd.Flags().BoolVar(&pwnedOnline, "pwned-online", false, "Check passwords against the online Pwned Passwords range API instead of the local database (only 5-character SHA-1 prefixes are sent)")
+	auditCmd.Flags().StringVar(&pwnedRangeURL, "pwned-range-url", "", "Range API base URL, e.g. an internal mirror (implies --pwned-online; default: "+database.DefaultPwnedRangeURL+")")
+	auditCmd.Flags().StringVar(&pwnedCacheDir, "pwned-cache-dir", "", "Cache range API responses in this directory (default: user cache directory)")
+	auditCmd.Flags().DurationVar(&pwnedCacheTTL, "pwned-cache-ttl", 0, "How long cached range responses stay valid (default: 24h, 0s disables the cache)")
+	auditCmd.Flags().IntVar(&pwnedWorkers, "pwned-workers", 0, "Concurrent range API requests (default: 8)")
+	auditCmd.Flags().BoolVar(&noPwnedPad, "no-pwned-padding", false, "Do not request padded range responses")
+	auditCmd.Flags().IntVar(&minStrength, "min-strength", -1, "Report passwords whose strength score (0-4) is below this value (default: 3)")
+	auditCmd.Flags().DurationVar(&minCrackTime, "min-crack-time", 0, "Also report passwords estimated to be cracked faster than this, e.g. 8760h")
 	auditCmd.MarkFlagRequired("file")
 	rootCmd.AddCommand(auditCmd)
+
+	importPwnedCmd.Flags().StringVarP(&databasePath, "database", "d", "database", "Database directory path")
+	importPwnedCmd.Flags().StringVarP(&pwnedOutput, "output", "o", "", "Output file (default: <database>/"+database.PwnedPasswordIndexFile+")")
+	importPwnedCmd.Flags().BoolVar(&noPrefixIndex, "no-prefix-index", false, "Do not write the 512KB hash prefix index")
+	importPwnedCmd.Flags().StringVar(&tempDir, "temp-dir", "", "Directory for sort runs when the input is not sorted (default: output directory)")
+	dbCmd.AddCommand(importPwnedCmd)
+	rootCmd.AddCommand(dbCmd)
+}
+
+func runImportPwned(cmd *cobra.Command, args []string) error {
+	output := pwnedOutput
+	if output == "" {
+		output = filepath.Join(databasePath, database.PwnedPasswordIndexFile)
+	}
+
+	compiler := database.NewPwnedPasswordCompiler()
+	compiler.SetPrefixIndex(!noPrefixIndex)
+	compiler.SetTempDir(tempDir)
+
+	stats, err := compiler.Compile(args[0], output)
+	if err != nil {
+		return fmt.Errorf("failed to import pwned passwords: %w", err)
+	}
+
+	fmt.Printf("Imported %d hashes into %s", stats.Hashes, output)
+	if stats.Duplicates > 0 {
+		fmt.Printf(" (%d duplicate lines merged)", stats.Duplicates)
+	}
+	if !stats.Sorted {
+		fmt.Print(" using external sort")
+	}
+	fmt.Println()
+	return nil
 }
 
 func runAudit(cmd *cobra.Command, args []string) error {
 	cfg := config.DefaultConfig()
 	engine := audit.NewEngine()
+	if batchSize > 0 {
+		cfg.Audit.BatchSize = batchSize
+	}
+	engine.SetBatchSize(cfg.Audit.BatchSize)
 
 	// Initialize database loader
 	dbLoader := database.NewDatabaseLoader(databasePath)
@@ -78,20 +169,98 @@ func runAudit(cmd *cobra.Command, args []string) error {
 		engine.RegisterDetector(passkeyDetector)
 	}
 
-	// Parse input file
-	credentials, err := parseInputFile(inputFile)
+	if cfg.Detectors.Pwned {
+		pwnedDetector, err := newPwnedDetector(cmd, cfg, dbLoader)
+		if err != nil {
+			return fmt.Errorf("failed to initialize pwned password detector: %w", err)
+		}
+		defer pwnedDetector.Close()
+		engine.RegisterDetector(pwnedDetector)
+	}
+
+	if cfg.Detectors.Reuse {
+		reuseDetector, err := detector.NewPasswordReuseDetector(dbLoader)
+		if err != nil {
+			return fmt.Errorf("failed to initialize password reuse detector: %w", err)
+		}
+		engine.RegisterDetector(reuseDetector)
+	}
+
+	if cfg.Detectors.Similar {
+		similarDetector, err := detector.NewSimilarPasswordDetector(dbLoader)
+		if err != nil {
+			return fmt.Errorf("failed to initialize similar password detector: %w", err)
+		}
+		engine.RegisterDetector(similarDetector)
+	}
+
+	if cfg.Detectors.Weak {
+		if minStrength >= 0 {
+			cfg.Strength.MinScore = minStrength
+		}
+		if minCrackTime > 0 {
+			cfg.Strength.MinCrackTime = minCrackTime
+		}
+		weakDetector := detector.NewWeakPasswordDetector()
+		weakDetector.SetMinScore(cfg.Strength.MinScore)
+		weakDetector.SetMinCrackTime(cfg.Strength.MinCrackTime)
+		weakDetector.SetGuessesPerSecond(cfg.Strength.GuessesPerSecond)
+		engine.RegisterDetector(weakDetector)
+	}
+
+	if cfg.Detectors.Identity {
+		identityDetector, err := detector.NewIdentityDetector(dbLoader)
+		if err != nil {
+			return fmt.Errorf("failed to initialize identity detector: %w", err)
+		}
+		engine.RegisterDetector(identityDetector)
+	}
+
+	if cfg.Detectors.InsecureURL {
+		engine.RegisterDetector(detector.NewInsecureURLDetector())
+	}
+
+	if cfg.Detectors.Lookalike {
+		lookalikeDetector, err := detector.NewLookalikeDetector(dbLoader)
+		if err != nil {
+			return fmt.Errorf("failed to initialize lookalike detector: %w", err)
+		}
+		engine.RegisterDetector(lookalikeDetector)
+	}
+
+	if cfg.Detectors.TOTP {
+		totpDetector, err := detector.NewTOTPDetector()
+		if err != nil {
+			return fmt.Errorf("failed to initialize TOTP detector: %w", err)
+		}
+		engine.RegisterDetector(totpDetector)
+	}
+
+	if cfg.Detectors.Secrets {
+		engine.RegisterDetector(detector.NewSecretsDetector())
+	}
+
+	// 加密导出文件的口令按需读取，只保存在内存中
+	passphrase := newPassphraseResolver(passphraseEnv, passphraseFd)
+	defer passphrase.Wipe()
+
+	// Parse input files
+	credentials, loader, dedup, err := streamInputs(inputFiles, passphrase.Func())
 	if err != nil {
 		return fmt.Errorf("failed to parse input file: %w", err)
 	}
-
-	fmt.Printf("Loaded %d credentials from %s\n", len(credentials), inputFile)
+	defer loader.Close()
 
 	// Run audit
-	auditReport, err := engine.Audit(context.Background(), credentials)
+	auditReport, err := engine.AuditStream(context.Background(), credentials)
 	if err != nil {
 		return fmt.Errorf("audit failed: %w", err)
 	}
 
+	if merged := dedup.Merged(); merged > 0 {
+		fmt.Printf("Merged %d duplicate credentials across sources\n", merged)
+	}
+
 	// Generate report
 	if err := generateReport(auditReport, outputFile, format); err != nil {
 		return fmt.Errorf("failed to generate report: %w", err)
@@ -100,36 +269,130 @@ func runAudit(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func parseInputFile(filename string) ([]types.Credential, error) {
-	file, err := os.Open(filename)
+// newPwnedDetector 指定在线查询时使用范围查询客户端，否则使用本地泄露密码库
+func newPwnedDetector(cmd *cobra.Command, cfg *config.Config, dbLoader *database.DatabaseLoader) (*detector.PwnedPasswordDetector, error) {
+	if pwnedRangeURL != "" {
+		cfg.Pwned.RangeURL = pwnedRangeURL
+	} else if pwnedOnline {
+		cfg.Pwned.RangeURL = database.DefaultPwnedRangeURL
+	}
+	if cfg.Pwned.RangeURL == "" {
+		return detector.NewPwnedPasswordDetector(dbLoader)
+	}
+
+	if cmd.Flags().Changed("pwned-cache-ttl") {
+		cfg.Pwned.CacheTTL = pwnedCacheTTL
+	}
+	if pwnedCacheDir != "" {
+		cfg.Pwned.CacheDir = pwnedCacheDir
+	} else if cacheDir, err := os.UserCacheDir(); err == nil && cfg.Pwned.CacheDir == "" {
+		cfg.Pwned.CacheDir = filepath.Join(cacheDir, "unpass", "pwned-range")
+	}
+	if pwnedWorkers > 0 {
+		cfg.Pwned.Workers = pwnedWorkers
+	}
+	if noPwnedPad {
+		cfg.Pwned.Padding = false
+	}
+
+	client := database.NewPwnedRangeClient(cfg.Pwned.RangeURL)
+	client.SetPadding(cfg.Pwned.Padding)
+	client.SetWorkers(cfg.Pwned.Workers)
+	client.SetRetries(cfg.Pwned.Retries)
+	if cfg.Pwned.CacheTTL > 0 {
+		client.SetCache(cfg.Pwned.CacheDir, cfg.Pwned.CacheTTL)
+	}
+	return detector.NewPwnedPasswordDetectorWithLookup(client), nil
+}
+
+// streamInputs 展开所有输入来源并返回去重后的凭据流，每个输入只解析（解密）一次
+// 多个来源时缓存凭据以合并跨来源的重复，读完所有输入后再交给审计引擎分批消费
+func streamInputs(patterns []string, passphrase parser.PassphraseFunc) (iter.Seq2[types.Credential, error], *input.Loader, *input.Deduplicator, error) {
+	csvMapping, err := newCSVMapping()
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
-	defer file.Close()
 
-	ext := strings.ToLower(filepath.Ext(filename))
-	if ext != ".json" {
-		return nil, fmt.Errorf("only JSON format is supported")
+	loader := input.NewLoader(newParserRegistry(passphrase, csvMapping), inputFormat)
+	inputs, err := loader.Expand(patterns)
+	if err != nil {
+		return nil, nil, nil, err
 	}
+	dedup := input.NewDeduplicator()
+	dedup.SetMerge(len(inputs) > 1)
+
+	loader.SetProgressFunc(func(progress input.Progress) {
+		if progress.Skipped != nil {
+			fmt.Fprintf(os.Stderr, "Skipping %s: unrecognized format\n", progress.Source.File)
+			return
+		}
+		fmt.Printf("Loaded %d credentials from %s (%s)\n", progress.Credentials, progress.Source.File, progress.Source.Provider)
+	})
 
-	// 创建解析器注册表
+	return dedup.Filter(loader.Stream(inputs)), loader, dedup, nil
+}
+
+// newCSVMapping 根据--csv-preset、--map-file和--map构建通用CSV映射，都未指定时返回nil
+func newCSVMapping() (*providers.CSVMapping, error) {
+	if csvPreset == "" && csvMapFile == "" && len(csvMapRules) == 0 {
+		return nil, nil
+	}
+
+	mapping := providers.NewCSVMapping()
+	if csvPreset != "" {
+		preset, exists := providers.CSVPreset(csvPreset)
+		if !exists {
+			return nil, fmt.Errorf("unknown CSV preset: %s (supported: %s)", csvPreset, strings.Join(providers.CSVPresetNames(), ", "))
+		}
+		mapping = preset
+	}
+
+	if csvMapFile != "" {
+		if err := mapping.ApplyFile(csvMapFile); err != nil {
+			return nil, err
+		}
+	}
+	for _, rules := range csvMapRules {
+		if err := mapping.Apply(rules); err != nil {
+			return nil, err
+		}
+	}
+
+	return mapping, nil
+}
+
+// newParserRegistry 创建解析器注册表
+func newParserRegistry(passphrase parser.PassphraseFunc, csvMapping *providers.CSVMapping) *parser.Registry {
 	parserRegistry := parser.NewRegistry()
 	parserRegistry.Register("json", parser.NewJSONParser())
-	
+
 	// 注册providers
 	parserRegistry.Register("enpass", providers.NewEnpassParser())
-
-	// 首先尝试通用JSON解析器
-	jsonParser := parser.NewJSONParser()
-	credentials, err := jsonParser.Parse(file)
-	if err == nil && len(credentials) > 0 {
-		return credentials, nil
+	bitwardenParser := providers.NewBitwardenParser()
+	bitwardenParser.SetPassphraseFunc(passphrase)
+	parserRegistry.Register("bitwarden", bitwardenParser)
+	parserRegistry.Register("1password", providers.NewOnePasswordParser())
+	protonPassParser := providers.NewProtonPassParser()
+	protonPassParser.SetPassphraseFunc(passphrase)
+	parserRegistry.Register("protonpass", protonPassParser)
+	parserRegistry.Register("cxf", providers.NewCXFParser())
+	firefoxParser := providers.NewFirefoxParser()
+	firefoxParser.SetPassphraseFunc(passphrase)
+	parserRegistry.Register("firefox", firefoxParser)
+	keepassParser := providers.NewKeePassParser()
+	keepassParser.SetKeyFile(keyFile)
+	if !noPassword {
+		keepassParser.SetPassphraseFunc(passphrase)
+	}
+	parserRegistry.Register("keepass", keepassParser)
+	parserRegistry.Register("browser-csv", providers.NewBrowserCSVParser())
+	csvParser := providers.NewGenericCSVParser()
+	if csvMapping != nil {
+		csvParser.SetMapping(csvMapping)
 	}
+	parserRegistry.Register("csv", csvParser)
 
-	// 如果通用JSON解析失败，尝试Enpass解析器
-	file.Seek(0, 0) // 重置文件指针
-	enpassParser := providers.NewEnpassParser()
-	return enpassParser.Parse(file)
+	return parserRegistry
 }
 
 func generateReport(auditReport *types.AuditReport, outputFile, format string) error {
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"golang.org/x/term"
+
+	"github.com/yourorg/unpass/internal/parser"
+)
+
+// passphraseResolver 按优先级解析解密口令：环境变量 > 文件描述符 > 交互式提示
+// 口令只读取一次并缓存在内存中，供同一次运行中的多个加密文件复用
+type passphraseResolver struct {
+	envName string
+	fd      int
+
+	once       sync.Once
+	passphrase []byte
+	err        error
+}
+
+func newPassphraseResolver(envName string, fd int) *passphraseResolver {
+	return &passphraseResolver{
+		envName: envName,
+		fd:      fd,
+	}
+}
+
+// Func 返回可交给解析器的口令回调
+func (r *passphraseResolver) Func() parser.PassphraseFunc {
+	return func(prompt string) ([]byte, error) {
+		r.once.Do(func() {
+			r.passphrase, r.err = r.resolve(prompt)
+		})
+		if r.err != nil {
+			return nil, r.err
+		}
+		// 返回副本，调用方可以安全清零
+		return bytes.Clone(r.passphrase), nil
+	}
+}
+
+// Wipe 清零缓存的口令
+func (r *passphraseResolver) Wipe() {
+	clear(r.passphrase)
+}
+
+func (r *passphraseResolver) resolve(prompt string) ([]byte, error) {
+	if r.envName != "" {
+		value, ok := os.LookupEnv(r.envName)
+		if !ok {
+			return nil, fmt.Errorf("environment variable %s is not set", r.envName)
+		}
+		return []byte(value), nil
+	}
+
+	if r.fd >= 0 {
+		file := os.NewFile(uintptr(r.fd), fmt.Sprintf("fd%d", r.fd))
+		if file == nil {
+			return nil, fmt.Errorf("invalid passphrase file descriptor: %d", r.fd)
+		}
+		defer file.Close()
+		return readPassphraseLine(file)
+	}
+
+	stdinFd := int(os.Stdin.Fd())
+	if !term.IsTerminal(stdinFd) {
+		return nil, fmt.Errorf("%w: use --passphrase-env or --passphrase-fd when stdin is not a terminal", parser.ErrPassphraseRequired)
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	passphrase, err := term.ReadPassword(stdinFd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, err
+	}
+	return passphrase, nil
+}
+
+// readPassphraseLine 读取第一行作为口令（去除行尾换行符）
+func readPassphraseLine(reader io.Reader) ([]byte, error) {
+	line, err := bufio.NewReader(reader).ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	line = bytes.TrimRight(line, "\r\n")
+	if len(line) == 0 {
+		return nil, fmt.Errorf("empty passphrase")
+	}
+	return line, nil
+}
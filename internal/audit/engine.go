@@ -3,44 +3,118 @@ package audit
 import (
 	"context"
 	"fmt"
+	"iter"
 	"time"
 	"github.com/yourorg/unpass/internal/detector"
 	"github.com/yourorg/unpass/internal/types"
 )
 
+// DefaultBatchSize 流式审计时每批交给检测器的凭据数
+const DefaultBatchSize = 1000
+
 type Engine struct {
 	detectors []detector.Detector
+	batchSize int
 }
 
 func NewEngine() *Engine {
 	return &Engine{
 		detectors: make([]detector.Detector, 0),
+		batchSize: DefaultBatchSize,
+	}
+}
+
+// SetBatchSize 设置每批处理的凭据数，小于1时使用默认值
+func (e *Engine) SetBatchSize(size int) {
+	if size < 1 {
+		size = DefaultBatchSize
 	}
+	e.batchSize = size
 }
 
 func (e *Engine) RegisterDetector(det detector.Detector) {
 	e.detectors = append(e.detectors, det)
 }
 
+// Audit 审计内存中的凭据切片
 func (e *Engine) Audit(ctx context.Context, creds []types.Credential) (*types.AuditReport, error) {
+	return e.AuditStream(ctx, func(yield func(types.Credential, error) bool) {
+		for _, cred := range creds {
+			if !yield(cred, nil) {
+				return
+			}
+		}
+	})
+}
+
+// AuditStream 分批消费凭据流，每批交给所有检测器后立即丢弃
+// 同一时间只有一批凭据（含明文密码）驻留在内存中
+func (e *Engine) AuditStream(ctx context.Context, creds iter.Seq2[types.Credential, error]) (*types.AuditReport, error) {
 	var allResults []types.DetectionResult
 	
-	for _, det := range e.detectors {
-		results, err := det.Detect(ctx, creds)
+	summary := types.AuditSummary{
+		ByType: make(map[types.DetectionType]int),
+	}
+	sources := newSourceCounter()
+	batch := make([]types.Credential, 0, e.batchSize)
+	
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		results, err := e.detectBatch(ctx, batch)
 		if err != nil {
-			return nil, fmt.Errorf("detector %s failed: %w", det.Name(), err)
+			return err
 		}
 		allResults = append(allResults, results...)
+		
+		// 检测器处理完毕，清除本批凭据的引用
+		clear(batch)
+		batch = batch[:0]
+		return nil
 	}
 	
-	summary := types.AuditSummary{
-		TotalCredentials: len(creds),
-		IssuesFound:      len(allResults),
-		ByType:           make(map[types.DetectionType]int),
+	for cred, err := range creds {
+		if err != nil {
+			return nil, err
+		}
+		summary.TotalCredentials++
+		sources.add(cred)
+		
+		batch = append(batch, cred)
+		if len(batch) >= e.batchSize {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	
+	// 跨批次比较的检测器在看到所有凭据后产生结果
+	for _, det := range e.detectors {
+		finisher, ok := det.(detector.Finisher)
+		if !ok {
+			continue
+		}
+		results, err := finisher.Finish(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("detector %s failed: %w", det.Name(), err)
+		}
+		allResults = append(allResults, results...)
 	}
 	
+	summary.IssuesFound = len(allResults)
+	summary.Sources = sources.summaries
 	for _, result := range allResults {
 		summary.ByType[result.Type]++
+		if result.Category != "" {
+			if summary.ByCategory == nil {
+				summary.ByCategory = make(map[string]int)
+			}
+			summary.ByCategory[result.Category]++
+		}
 	}
 	
 	return &types.AuditReport{
@@ -48,4 +122,39 @@ func (e *Engine) Audit(ctx context.Context, creds []types.Credential) (*types.Au
 		Summary:   summary,
 		Timestamp: time.Now(),
 	}, nil
-} 
+}
+
+// detectBatch 对一批凭据运行所有检测器，并为结果补充凭据来源和类别
+func (e *Engine) detectBatch(ctx context.Context, batch []types.Credential) ([]types.DetectionResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	var batchResults []types.DetectionResult
+	for _, det := range e.detectors {
+		results, err := det.Detect(ctx, batch)
+		if err != nil {
+			return nil, fmt.Errorf("detector %s failed: %w", det.Name(), err)
+		}
+		batchResults = append(batchResults, results...)
+	}
+	
+	byID := make(map[string]*types.Credential, len(batch))
+	for i := range batch {
+		byID[batch[i].ID] = &batch[i]
+	}
+	for i := range batchResults {
+		cred, exists := byID[batchResults[i].CredentialID]
+		if !exists {
+			continue
+		}
+		if batchResults[i].Sources == nil {
+			batchResults[i].Sources = cred.Sources
+		}
+		if batchResults[i].Category == "" {
+			batchResults[i].Category = cred.Category
+		}
+	}
+	
+	return batchResults, nil
+}
@@ -0,0 +1,115 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/yourorg/unpass/internal/types"
+)
+
+// batchRecorder 测试辅助检测器：记录每批的大小，为每条凭据产生一个结果
+type batchRecorder struct {
+	batches []int
+}
+
+func (d *batchRecorder) Name() string { return "recorder" }
+
+func (d *batchRecorder) Detect(ctx context.Context, creds []types.Credential) ([]types.DetectionResult, error) {
+	d.batches = append(d.batches, len(creds))
+	var results []types.DetectionResult
+	for _, cred := range creds {
+		results = append(results, types.DetectionResult{CredentialID: cred.ID, Type: types.DetectionMissing2FA})
+	}
+	return results, nil
+}
+
+func (d *batchRecorder) Configure(config map[string]interface{}) error { return nil }
+
+func TestEngine_AuditStreamBatches(t *testing.T) {
+	recorder := &batchRecorder{}
+	engine := NewEngine()
+	engine.RegisterDetector(recorder)
+	engine.SetBatchSize(2)
+
+	source := types.CredentialSource{File: "vault.json", Provider: "json"}
+	stream := func(yield func(types.Credential, error) bool) {
+		for _, id := range []string{"1", "2", "3", "4", "5"} {
+			if !yield(types.Credential{ID: id, Password: "pw", Sources: []types.CredentialSource{source}}, nil) {
+				return
+			}
+		}
+	}
+
+	report, err := engine.AuditStream(context.Background(), stream)
+	if err != nil {
+		t.Fatalf("AuditStream failed: %v", err)
+	}
+
+	if len(recorder.batches) != 3 || recorder.batches[0] != 2 || recorder.batches[2] != 1 {
+		t.Errorf("Expected batches [2 2 1], got %v", recorder.batches)
+	}
+	if report.Summary.TotalCredentials != 5 || report.Summary.IssuesFound != 5 {
+		t.Errorf("Unexpected summary: %+v", report.Summary)
+	}
+	if len(report.Summary.Sources) != 1 || report.Summary.Sources[0].Credentials != 5 {
+		t.Errorf("Unexpected source summary: %+v", report.Summary.Sources)
+	}
+	for _, result := range report.Results {
+		if len(result.Sources) != 1 || result.Sources[0] != source {
+			t.Errorf("Expected result %s to carry its source, got %v", result.CredentialID, result.Sources)
+		}
+	}
+}
+
+func TestEngine_AuditStreamError(t *testing.T) {
+	engine := NewEngine()
+	engine.RegisterDetector(&batchRecorder{})
+
+	parseErr := errors.New("broken export")
+	stream := func(yield func(types.Credential, error) bool) {
+		if yield(types.Credential{ID: "1"}, nil) {
+			yield(types.Credential{}, parseErr)
+		}
+	}
+
+	if _, err := engine.AuditStream(context.Background(), stream); !errors.Is(err, parseErr) {
+		t.Errorf("Expected stream error to be returned, got %v", err)
+	}
+}
+
+// countingFinisher 测试辅助检测器：累积凭据数，所有批次结束后产生一个结果
+type countingFinisher struct {
+	seen int
+}
+
+func (d *countingFinisher) Name() string { return "finisher" }
+
+func (d *countingFinisher) Detect(ctx context.Context, creds []types.Credential) ([]types.DetectionResult, error) {
+	d.seen += len(creds)
+	return nil, nil
+}
+
+func (d *countingFinisher) Finish(ctx context.Context) ([]types.DetectionResult, error) {
+	return []types.DetectionResult{{CredentialID: "all", Type: types.DetectionPasswordReuse, Metadata: map[string]interface{}{"seen": d.seen}}}, nil
+}
+
+func (d *countingFinisher) Configure(config map[string]interface{}) error { return nil }
+
+func TestEngine_AuditStreamFinisher(t *testing.T) {
+	finisher := &countingFinisher{}
+	engine := NewEngine()
+	engine.RegisterDetector(finisher)
+	engine.SetBatchSize(2)
+
+	report, err := engine.Audit(context.Background(), []types.Credential{{ID: "1"}, {ID: "2"}, {ID: "3"}})
+	if err != nil {
+		t.Fatalf("Audit failed: %v", err)
+	}
+	if len(report.Results) != 1 || report.Results[0].Metadata["seen"] != 3 {
+		t.Errorf("Expected one result after all batches, got %+v", report.Results)
+	}
+	if report.Summary.ByType[types.DetectionPasswordReuse] != 1 {
+		t.Errorf("Expected finisher results in summary, got %+v", report.Summary.ByType)
+	}
+}
@@ -0,0 +1,29 @@
+package audit
+
+import "github.com/yourorg/unpass/internal/types"
+
+// sourceCounter 按首次出现的顺序统计每个来源的凭据数
+// 凭据的第一个来源视为原始来源，其余来源计为重复
+type sourceCounter struct {
+	summaries []types.SourceSummary
+	index     map[types.CredentialSource]int
+}
+
+func newSourceCounter() *sourceCounter {
+	return &sourceCounter{index: make(map[types.CredentialSource]int)}
+}
+
+func (c *sourceCounter) add(cred types.Credential) {
+	for i, source := range cred.Sources {
+		pos, exists := c.index[source]
+		if !exists {
+			pos = len(c.summaries)
+			c.index[source] = pos
+			c.summaries = append(c.summaries, types.SourceSummary{CredentialSource: source})
+		}
+		c.summaries[pos].Credentials++
+		if i > 0 {
+			c.summaries[pos].Duplicates++
+		}
+	}
+}
@@ -1,19 +1,77 @@
 package config
 
+import "time"
+
 type Config struct {
 	Detectors DetectorConfig `yaml:"detectors"`
+	Audit     AuditConfig    `yaml:"audit"`
+	Pwned     PwnedConfig    `yaml:"pwned"`
+	Strength  StrengthConfig `yaml:"strength"`
 }
 
 type DetectorConfig struct {
-	TwoFA   bool `yaml:"twofa"`
-	Passkey bool `yaml:"passkey"`
+	TwoFA       bool `yaml:"twofa"`
+	Passkey     bool `yaml:"passkey"`
+	Pwned       bool `yaml:"pwned"`        // 离线比对已泄露密码库
+	Reuse       bool `yaml:"reuse"`        // 跨站点密码复用
+	Similar     bool `yaml:"similar"`      // 结构相近的密码家族
+	Weak        bool `yaml:"weak"`         // 强度估算过低的弱密码
+	Identity    bool `yaml:"identity"`     // 密码包含用户名、标题或站点名
+	InsecureURL bool `yaml:"insecure_url"` // 明文http、内嵌账号密码、IP地址等不安全的登录地址
+	Lookalike   bool `yaml:"lookalike"`    // 仿冒已知站点的域名及标题与地址不符
+	TOTP        bool `yaml:"totp"`         // 无法使用或不合常规的TOTP配置
+	Secrets     bool `yaml:"secrets"`      // 备注中的API令牌、私钥、助记词、备用码和密保答案
+}
+
+// AuditConfig 审计引擎配置
+type AuditConfig struct {
+	BatchSize int `yaml:"batch_size"` // 每批交给检测器的凭据数，限制内存中的明文密码数量
+}
+
+// PwnedConfig 泄露密码检测的在线范围查询配置，RangeURL为空时只使用本地库
+type PwnedConfig struct {
+	RangeURL string        `yaml:"range_url"` // 范围查询服务地址，请求 {RangeURL}/range/{前缀}
+	Padding  bool          `yaml:"padding"`   // 请求填充响应
+	Workers  int           `yaml:"workers"`   // 并发请求数
+	Retries  int           `yaml:"retries"`   // 网络错误、429和5xx的重试次数
+	CacheDir string        `yaml:"cache_dir"` // 前缀响应的磁盘缓存目录，为空时不缓存
+	CacheTTL time.Duration `yaml:"cache_ttl"`
+}
+
+// StrengthConfig 弱密码检测的阈值，评分或破解时间任一低于阈值即报告
+type StrengthConfig struct {
+	MinScore         int           `yaml:"min_score"`          // 评分0~4，猜测次数分别低于10^3、10^6、10^8、10^10时为0~3
+	MinCrackTime     time.Duration `yaml:"min_crack_time"`     // 为0时不按破解时间判断
+	GuessesPerSecond float64       `yaml:"guesses_per_second"` // 估算破解时间时攻击者每秒的猜测次数
 }
 
 func DefaultConfig() *Config {
 	return &Config{
 		Detectors: DetectorConfig{
-			TwoFA:   true,
-			Passkey: true,
+			TwoFA:       true,
+			Passkey:     true,
+			Pwned:       true,
+			Reuse:       true,
+			Similar:     true,
+			Weak:        true,
+			Identity:    true,
+			InsecureURL: true,
+			Lookalike:   true,
+			TOTP:        true,
+			Secrets:     true,
+		},
+		Audit: AuditConfig{
+			BatchSize: 1000,
+		},
+		Pwned: PwnedConfig{
+			Padding:  true,
+			Workers:  8,
+			Retries:  3,
+			CacheTTL: 24 * time.Hour,
+		},
+		Strength: StrengthConfig{
+			MinScore:         3,
+			GuessesPerSecond: 1e4,
 		},
 	}
 } 
\ No newline at end of file
@@ -61,4 +61,18 @@ func (dl *DatabaseLoader) LoadPwnedPasswordDatabase() (*types.PwnedPasswordDatab
 	}
 
 	return &db, nil
-} 
\ No newline at end of file
+} 
+
+// LoadPwnedPasswords 优先使用编译后的二进制库（unpass db import-pwned），不存在时读取JSON库
+func (dl *DatabaseLoader) LoadPwnedPasswords() (PwnedPasswordLookup, error) {
+	indexPath := filepath.Join(dl.basePath, PwnedPasswordIndexFile)
+	if _, err := os.Stat(indexPath); err == nil {
+		return OpenPwnedPasswordIndex(indexPath)
+	}
+
+	db, err := dl.LoadPwnedPasswordDatabase()
+	if err != nil {
+		return nil, err
+	}
+	return newPwnedPasswordMap(db)
+}
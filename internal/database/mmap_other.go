@@ -0,0 +1,10 @@
+//go:build !unix
+
+package database
+
+import "os"
+
+// mapFile 不支持内存映射的平台上通过ReadAt查询
+func mapFile(file *os.File, size int64) ([]byte, func() error) {
+	return nil, nil
+}
@@ -0,0 +1,21 @@
+//go:build unix
+
+package database
+
+import (
+	"os"
+	"syscall"
+)
+
+// mapFile 将文件只读映射到内存，查询时由操作系统按需换页
+func mapFile(file *os.File, size int64) ([]byte, func() error) {
+	if size == 0 || int64(int(size)) != size {
+		return nil, nil
+	}
+	data, err := syscall.Mmap(int(file.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		// 无法映射时退回ReadAt
+		return nil, nil
+	}
+	return data, func() error { return syscall.Munmap(data) }
+}
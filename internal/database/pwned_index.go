@@ -0,0 +1,629 @@
+package database
+
+import (
+	"bufio"
+	"bytes"
+	"container/heap"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/yourorg/unpass/internal/types"
+)
+
+// PwnedPasswordIndexFile 数据库目录中编译后的泄露密码库文件名
+const PwnedPasswordIndexFile = "pwned_passwords.bin"
+
+// 二进制格式：32字节文件头 | 按哈希排序的记录 | 可选的前缀索引
+// 文件头：magic(8) version(2) flags(2) reserved(4) count(8) created(8)，均为大端序
+// 记录：SHA-1(20) + 泄露次数(4)
+// 前缀索引：65537个uint64，第p项为哈希前两字节不小于p的第一条记录的序号
+const (
+	pwnedIndexMagic      = "UNPWNED\x00"
+	pwnedIndexVersion    = 1
+	pwnedIndexHeaderSize = 32
+	pwnedRecordSize      = sha1.Size + 4
+	pwnedPrefixBuckets   = 1 << 16
+
+	pwnedFlagPrefixIndex = 1 << 0
+
+	// defaultPwnedChunkSize 外部排序时每个有序段的记录数（约96MB）
+	defaultPwnedChunkSize = 1 << 22
+)
+
+// errPwnedUnsorted 输入不是按哈希排序的，需要外部排序
+var errPwnedUnsorted = errors.New("input is not sorted by hash")
+
+// PwnedPasswordLookup 按SHA-1查询泄露次数
+type PwnedPasswordLookup interface {
+	Lookup(hash [sha1.Size]byte) (int, bool)
+	// LastUpdated 数据的更新日期，未知时为空
+	LastUpdated() string
+	Close() error
+}
+
+// PwnedCompileStats 编译结果统计
+type PwnedCompileStats struct {
+	Hashes     uint64 // 写入的不同哈希数
+	Duplicates uint64 // 合并的重复行数
+	Sorted     bool   // 输入已按哈希排序，未使用外部排序
+}
+
+// PwnedPasswordCompiler 将"SHA1:次数"文本（Pwned Passwords下载格式）编译为排序的二进制文件
+type PwnedPasswordCompiler struct {
+	prefixIndex bool
+	chunkSize   int
+	tempDir     string
+}
+
+func NewPwnedPasswordCompiler() *PwnedPasswordCompiler {
+	return &PwnedPasswordCompiler{
+		prefixIndex: true,
+		chunkSize:   defaultPwnedChunkSize,
+	}
+}
+
+// SetPrefixIndex 设置是否写入前缀索引（约512KB，每次查询少读约16层二分）
+func (c *PwnedPasswordCompiler) SetPrefixIndex(enabled bool) {
+	c.prefixIndex = enabled
+}
+
+// SetChunkSize 设置输入未排序时每个内存排序段的记录数
+func (c *PwnedPasswordCompiler) SetChunkSize(records int) {
+	c.chunkSize = records
+}
+
+// SetTempDir 设置外部排序临时文件的目录，默认为输出文件所在目录
+func (c *PwnedPasswordCompiler) SetTempDir(dir string) {
+	c.tempDir = dir
+}
+
+// Compile 编译inputPath到outputPath；先按已排序输入流式写出，发现乱序时改用外部排序
+// 输出先写入临时文件，成功后再替换，失败时不会留下不完整的数据库
+func (c *PwnedPasswordCompiler) Compile(inputPath, outputPath string) (PwnedCompileStats, error) {
+	tempDir := c.tempDir
+	if tempDir == "" {
+		tempDir = filepath.Dir(outputPath)
+	}
+
+	output, err := os.CreateTemp(filepath.Dir(outputPath), filepath.Base(outputPath)+".*.tmp")
+	if err != nil {
+		return PwnedCompileStats{}, err
+	}
+	defer func() {
+		output.Close()
+		os.Remove(output.Name())
+	}()
+
+	stats, err := c.compileSorted(inputPath, output)
+	if errors.Is(err, errPwnedUnsorted) {
+		if err := resetFile(output); err != nil {
+			return PwnedCompileStats{}, err
+		}
+		stats, err = c.compileUnsorted(inputPath, output, tempDir)
+	}
+	if err != nil {
+		return PwnedCompileStats{}, err
+	}
+
+	// CreateTemp创建的文件只有属主可读，数据库与其他数据文件保持一致
+	if err := output.Chmod(0o644); err != nil {
+		return PwnedCompileStats{}, err
+	}
+	if err := output.Close(); err != nil {
+		return PwnedCompileStats{}, err
+	}
+	if err := os.Rename(output.Name(), outputPath); err != nil {
+		return PwnedCompileStats{}, err
+	}
+	return stats, nil
+}
+
+// compileSorted 输入已排序时直接流式写出
+func (c *PwnedPasswordCompiler) compileSorted(inputPath string, output *os.File) (PwnedCompileStats, error) {
+	input, err := os.Open(inputPath)
+	if err != nil {
+		return PwnedCompileStats{}, err
+	}
+	defer input.Close()
+
+	writer, err := newPwnedWriter(output, c.prefixIndex)
+	if err != nil {
+		return PwnedCompileStats{}, err
+	}
+	err = readPwnedHashes(input, func(record pwnedRecord) error {
+		if writer.hasLast && bytes.Compare(record.hash[:], writer.last.hash[:]) < 0 {
+			return errPwnedUnsorted
+		}
+		return writer.add(record)
+	})
+	if err != nil {
+		return PwnedCompileStats{}, err
+	}
+
+	stats, err := writer.finish()
+	stats.Sorted = true
+	return stats, err
+}
+
+// compileUnsorted 分段排序写入临时文件，再多路归并
+func (c *PwnedPasswordCompiler) compileUnsorted(inputPath string, output *os.File, tempDir string) (PwnedCompileStats, error) {
+	input, err := os.Open(inputPath)
+	if err != nil {
+		return PwnedCompileStats{}, err
+	}
+	defer input.Close()
+
+	chunkSize := c.chunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultPwnedChunkSize
+	}
+
+	var runs []*os.File
+	defer func() {
+		for _, run := range runs {
+			run.Close()
+			os.Remove(run.Name())
+		}
+	}()
+
+	chunk := make([]pwnedRecord, 0, min(chunkSize, 1<<16))
+	flush := func() error {
+		if len(chunk) == 0 {
+			return nil
+		}
+		run, err := writePwnedRun(chunk, tempDir)
+		if run != nil {
+			runs = append(runs, run)
+		}
+		chunk = chunk[:0]
+		return err
+	}
+
+	err = readPwnedHashes(input, func(record pwnedRecord) error {
+		chunk = append(chunk, record)
+		if len(chunk) >= chunkSize {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		return PwnedCompileStats{}, err
+	}
+
+	writer, err := newPwnedWriter(output, c.prefixIndex)
+	if err != nil {
+		return PwnedCompileStats{}, err
+	}
+	if err := mergePwnedRuns(runs, writer.add); err != nil {
+		return PwnedCompileStats{}, err
+	}
+	return writer.finish()
+}
+
+type pwnedRecord struct {
+	hash  [sha1.Size]byte
+	count uint32
+}
+
+// readPwnedHashes 逐行解析"SHA1:次数"，没有次数的行按1次计，空行跳过
+func readPwnedHashes(reader io.Reader, visit func(pwnedRecord) error) error {
+	scanner := bufio.NewScanner(reader)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		hashText, countText, hasCount := bytes.Cut(line, []byte(":"))
+		var record pwnedRecord
+		if len(hashText) != hex.EncodedLen(sha1.Size) {
+			return fmt.Errorf("line %d: expected a 40-character SHA-1 hash", lineNumber)
+		}
+		if _, err := hex.Decode(record.hash[:], hashText); err != nil {
+			return fmt.Errorf("line %d: invalid SHA-1 hash: %w", lineNumber, err)
+		}
+		record.count = 1
+		if hasCount {
+			count, err := strconv.ParseUint(string(bytes.TrimSpace(countText)), 10, 64)
+			if err != nil {
+				return fmt.Errorf("line %d: invalid count: %w", lineNumber, err)
+			}
+			record.count = uint32(min(count, math.MaxUint32))
+		}
+
+		if err := visit(record); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// writePwnedRun 将排序后的一段记录写入临时文件
+func writePwnedRun(chunk []pwnedRecord, tempDir string) (*os.File, error) {
+	slices.SortFunc(chunk, func(a, b pwnedRecord) int {
+		return bytes.Compare(a.hash[:], b.hash[:])
+	})
+
+	run, err := os.CreateTemp(tempDir, "pwned-run-*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	buffered := bufio.NewWriter(run)
+	var buf [pwnedRecordSize]byte
+	for _, record := range chunk {
+		copy(buf[:], record.hash[:])
+		binary.BigEndian.PutUint32(buf[sha1.Size:], record.count)
+		if _, err := buffered.Write(buf[:]); err != nil {
+			return run, err
+		}
+	}
+	if err := buffered.Flush(); err != nil {
+		return run, err
+	}
+	_, err = run.Seek(0, io.SeekStart)
+	return run, err
+}
+
+// pwnedRunReader 归并时的有序段读取器
+type pwnedRunReader struct {
+	reader  *bufio.Reader
+	current pwnedRecord
+}
+
+func (r *pwnedRunReader) next() (bool, error) {
+	var buf [pwnedRecordSize]byte
+	if _, err := io.ReadFull(r.reader, buf[:]); err != nil {
+		if err == io.EOF {
+			return false, nil
+		}
+		return false, err
+	}
+	copy(r.current.hash[:], buf[:sha1.Size])
+	r.current.count = binary.BigEndian.Uint32(buf[sha1.Size:])
+	return true, nil
+}
+
+type pwnedRunHeap []*pwnedRunReader
+
+func (h pwnedRunHeap) Len() int { return len(h) }
+func (h pwnedRunHeap) Less(i, j int) bool {
+	return bytes.Compare(h[i].current.hash[:], h[j].current.hash[:]) < 0
+}
+func (h pwnedRunHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *pwnedRunHeap) Push(x any)   { *h = append(*h, x.(*pwnedRunReader)) }
+func (h *pwnedRunHeap) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// mergePwnedRuns 多路归并有序段，按哈希顺序产生记录
+func mergePwnedRuns(runs []*os.File, visit func(pwnedRecord) error) error {
+	var runHeap pwnedRunHeap
+	for _, run := range runs {
+		reader := &pwnedRunReader{reader: bufio.NewReader(run)}
+		ok, err := reader.next()
+		if err != nil {
+			return err
+		}
+		if ok {
+			runHeap = append(runHeap, reader)
+		}
+	}
+	heap.Init(&runHeap)
+
+	for runHeap.Len() > 0 {
+		reader := runHeap[0]
+		if err := visit(reader.current); err != nil {
+			return err
+		}
+		ok, err := reader.next()
+		if err != nil {
+			return err
+		}
+		if ok {
+			heap.Fix(&runHeap, 0)
+		} else {
+			heap.Pop(&runHeap)
+		}
+	}
+	return nil
+}
+
+// pwnedWriter 按哈希顺序写出记录，合并相邻的重复哈希
+type pwnedWriter struct {
+	file        *os.File
+	buffered    *bufio.Writer
+	prefixIndex bool
+	buckets     []uint64 // 每个前缀的记录数
+	last        pwnedRecord
+	hasLast     bool
+	stats       PwnedCompileStats
+}
+
+func newPwnedWriter(file *os.File, prefixIndex bool) (*pwnedWriter, error) {
+	// 文件头在写完记录后回填
+	if _, err := file.Seek(pwnedIndexHeaderSize, io.SeekStart); err != nil {
+		return nil, err
+	}
+	writer := &pwnedWriter{
+		file:        file,
+		buffered:    bufio.NewWriterSize(file, 1<<20),
+		prefixIndex: prefixIndex,
+	}
+	if prefixIndex {
+		writer.buckets = make([]uint64, pwnedPrefixBuckets)
+	}
+	return writer, nil
+}
+
+func (w *pwnedWriter) add(record pwnedRecord) error {
+	if w.hasLast && record.hash == w.last.hash {
+		w.last.count = uint32(min(uint64(w.last.count)+uint64(record.count), math.MaxUint32))
+		w.stats.Duplicates++
+		return nil
+	}
+	if w.hasLast {
+		if err := w.write(w.last); err != nil {
+			return err
+		}
+	}
+	w.last, w.hasLast = record, true
+	return nil
+}
+
+func (w *pwnedWriter) write(record pwnedRecord) error {
+	var buf [pwnedRecordSize]byte
+	copy(buf[:], record.hash[:])
+	binary.BigEndian.PutUint32(buf[sha1.Size:], record.count)
+	if _, err := w.buffered.Write(buf[:]); err != nil {
+		return err
+	}
+	if w.prefixIndex {
+		w.buckets[binary.BigEndian.Uint16(record.hash[:2])]++
+	}
+	w.stats.Hashes++
+	return nil
+}
+
+// finish 写出最后一条记录、前缀索引和文件头
+func (w *pwnedWriter) finish() (PwnedCompileStats, error) {
+	if w.hasLast {
+		if err := w.write(w.last); err != nil {
+			return PwnedCompileStats{}, err
+		}
+		w.hasLast = false
+	}
+
+	var flags uint16
+	if w.prefixIndex {
+		flags |= pwnedFlagPrefixIndex
+		var buf [8]byte
+		var offset uint64
+		for _, count := range w.buckets {
+			binary.BigEndian.PutUint64(buf[:], offset)
+			if _, err := w.buffered.Write(buf[:]); err != nil {
+				return PwnedCompileStats{}, err
+			}
+			offset += count
+		}
+		binary.BigEndian.PutUint64(buf[:], offset)
+		if _, err := w.buffered.Write(buf[:]); err != nil {
+			return PwnedCompileStats{}, err
+		}
+	}
+	if err := w.buffered.Flush(); err != nil {
+		return PwnedCompileStats{}, err
+	}
+
+	header := make([]byte, pwnedIndexHeaderSize)
+	copy(header, pwnedIndexMagic)
+	binary.BigEndian.PutUint16(header[8:], pwnedIndexVersion)
+	binary.BigEndian.PutUint16(header[10:], flags)
+	binary.BigEndian.PutUint64(header[16:], w.stats.Hashes)
+	binary.BigEndian.PutUint64(header[24:], uint64(time.Now().Unix()))
+	if _, err := w.file.WriteAt(header, 0); err != nil {
+		return PwnedCompileStats{}, err
+	}
+	return w.stats, nil
+}
+
+func resetFile(file *os.File) error {
+	if err := file.Truncate(0); err != nil {
+		return err
+	}
+	_, err := file.Seek(0, io.SeekStart)
+	return err
+}
+
+// PwnedPasswordIndex 编译后的泄露密码库，通过内存映射（不支持时用ReadAt）二分查找，不整体读入内存
+type PwnedPasswordIndex struct {
+	file    *os.File
+	data    []byte // 内存映射的整个文件，为nil时使用ReadAt
+	unmap   func() error
+	count   uint64
+	created time.Time
+	index   []uint64 // 前缀索引，未编译索引时为nil
+}
+
+// OpenPwnedPasswordIndex 打开编译后的泄露密码库
+func OpenPwnedPasswordIndex(path string) (*PwnedPasswordIndex, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	index, err := newPwnedPasswordIndex(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to open pwned password index %s: %w", path, err)
+	}
+	return index, nil
+}
+
+func newPwnedPasswordIndex(file *os.File) (*PwnedPasswordIndex, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, pwnedIndexHeaderSize)
+	if _, err := file.ReadAt(header, 0); err != nil {
+		return nil, fmt.Errorf("invalid header: %w", err)
+	}
+	if string(header[:8]) != pwnedIndexMagic {
+		return nil, errors.New("not a compiled pwned password file")
+	}
+	if version := binary.BigEndian.Uint16(header[8:]); version != pwnedIndexVersion {
+		return nil, fmt.Errorf("unsupported version %d", version)
+	}
+	flags := binary.BigEndian.Uint16(header[10:])
+
+	idx := &PwnedPasswordIndex{
+		file:    file,
+		count:   binary.BigEndian.Uint64(header[16:]),
+		created: time.Unix(int64(binary.BigEndian.Uint64(header[24:])), 0).UTC(),
+	}
+
+	recordsEnd := uint64(pwnedIndexHeaderSize) + idx.count*pwnedRecordSize
+	expectedSize := recordsEnd
+	if flags&pwnedFlagPrefixIndex != 0 {
+		expectedSize += (pwnedPrefixBuckets + 1) * 8
+	}
+	if idx.count > uint64(info.Size())/pwnedRecordSize || uint64(info.Size()) != expectedSize {
+		return nil, fmt.Errorf("file size %d does not match %d records", info.Size(), idx.count)
+	}
+
+	// 前缀索引只有512KB，直接读入内存
+	if flags&pwnedFlagPrefixIndex != 0 {
+		raw := make([]byte, (pwnedPrefixBuckets+1)*8)
+		if _, err := file.ReadAt(raw, int64(recordsEnd)); err != nil {
+			return nil, fmt.Errorf("invalid prefix index: %w", err)
+		}
+		idx.index = make([]uint64, pwnedPrefixBuckets+1)
+		for i := range idx.index {
+			idx.index[i] = binary.BigEndian.Uint64(raw[i*8:])
+			// 损坏的索引会让查找越过记录区，访问内存映射时panic
+			if idx.index[i] > idx.count || (i > 0 && idx.index[i] < idx.index[i-1]) {
+				return nil, fmt.Errorf("prefix index entry %d is out of range", i)
+			}
+		}
+		if idx.index[pwnedPrefixBuckets] != idx.count {
+			return nil, errors.New("prefix index does not match record count")
+		}
+	}
+
+	idx.data, idx.unmap = mapFile(file, info.Size())
+	return idx, nil
+}
+
+// Len 库中不同哈希的数量
+func (i *PwnedPasswordIndex) Len() int {
+	return int(i.count)
+}
+
+// LastUpdated 编译日期
+func (i *PwnedPasswordIndex) LastUpdated() string {
+	return i.created.Format("2006-01-02")
+}
+
+// Lookup 二分查找哈希的泄露次数；有前缀索引时只在同前缀的范围内查找
+func (i *PwnedPasswordIndex) Lookup(hash [sha1.Size]byte) (int, bool) {
+	lo, hi := uint64(0), i.count
+	if i.index != nil {
+		// 用int计算上界，前缀为0xFFFF时uint16加1会回绕为0
+		prefix := int(binary.BigEndian.Uint16(hash[:2]))
+		lo, hi = i.index[prefix], i.index[prefix+1]
+	}
+
+	var buf [pwnedRecordSize]byte
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		record, ok := i.record(mid, buf[:])
+		if !ok {
+			return 0, false
+		}
+		switch cmp := bytes.Compare(record[:sha1.Size], hash[:]); {
+		case cmp == 0:
+			return int(binary.BigEndian.Uint32(record[sha1.Size:])), true
+		case cmp < 0:
+			lo = mid + 1
+		default:
+			hi = mid
+		}
+	}
+	return 0, false
+}
+
+// record 读取第n条记录，内存映射不可用时读入buf
+func (i *PwnedPasswordIndex) record(n uint64, buf []byte) ([]byte, bool) {
+	offset := pwnedIndexHeaderSize + n*pwnedRecordSize
+	if i.data != nil {
+		return i.data[offset : offset+pwnedRecordSize], true
+	}
+	if _, err := i.file.ReadAt(buf, int64(offset)); err != nil {
+		return nil, false
+	}
+	return buf, true
+}
+
+func (i *PwnedPasswordIndex) Close() error {
+	var err error
+	if i.unmap != nil {
+		err = i.unmap()
+		i.unmap, i.data = nil, nil
+	}
+	if closeErr := i.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// pwnedPasswordMap JSON格式的小型泄露密码库
+type pwnedPasswordMap struct {
+	counts      map[[sha1.Size]byte]int
+	lastUpdated string
+}
+
+func newPwnedPasswordMap(db *types.PwnedPasswordDatabase) (*pwnedPasswordMap, error) {
+	counts := make(map[[sha1.Size]byte]int, len(db.Passwords))
+	for hashText, count := range db.Passwords {
+		var hash [sha1.Size]byte
+		if len(hashText) != hex.EncodedLen(sha1.Size) {
+			return nil, fmt.Errorf("invalid SHA-1 hash in pwned password database: %q", hashText)
+		}
+		if _, err := hex.Decode(hash[:], []byte(hashText)); err != nil {
+			return nil, fmt.Errorf("invalid SHA-1 hash in pwned password database: %w", err)
+		}
+		counts[hash] = count
+	}
+	return &pwnedPasswordMap{counts: counts, lastUpdated: db.LastUpdated}, nil
+}
+
+func (m *pwnedPasswordMap) Lookup(hash [sha1.Size]byte) (int, bool) {
+	count, exists := m.counts[hash]
+	return count, exists
+}
+
+func (m *pwnedPasswordMap) LastUpdated() string {
+	return m.lastUpdated
+}
+
+func (m *pwnedPasswordMap) Close() error {
+	return nil
+}
@@ -0,0 +1,238 @@
+package database
+
+import (
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writePwnedInput 按"SHA1:次数"写出测试输入，哈希为"password-N"的SHA-1
+func writePwnedInput(t *testing.T, dir string, n int, shuffle bool) string {
+	t.Helper()
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("%X:%d", sha1.Sum([]byte(fmt.Sprintf("password-%d", i))), i+1)
+	}
+	if shuffle {
+		rand.New(rand.NewSource(1)).Shuffle(len(lines), func(i, j int) { lines[i], lines[j] = lines[j], lines[i] })
+	} else {
+		// 下载的语料按哈希排序
+		slices.Sort(lines)
+	}
+
+	path := filepath.Join(dir, "hashes.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\r\n")+"\r\n"), 0600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	return path
+}
+
+func TestPwnedPasswordCompiler(t *testing.T) {
+	testCases := []struct {
+		name        string
+		shuffle     bool
+		prefixIndex bool
+	}{
+		{name: "sorted with index", prefixIndex: true},
+		{name: "sorted without index"},
+		{name: "unsorted external sort", shuffle: true, prefixIndex: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			input := writePwnedInput(t, dir, 500, tc.shuffle)
+			output := filepath.Join(dir, PwnedPasswordIndexFile)
+
+			compiler := NewPwnedPasswordCompiler()
+			compiler.SetPrefixIndex(tc.prefixIndex)
+			compiler.SetChunkSize(64) // 强制产生多个有序段
+			stats, err := compiler.Compile(input, output)
+			if err != nil {
+				t.Fatalf("Compile failed: %v", err)
+			}
+			if stats.Hashes != 500 || stats.Sorted == tc.shuffle {
+				t.Errorf("Unexpected stats: %+v", stats)
+			}
+
+			index, err := OpenPwnedPasswordIndex(output)
+			if err != nil {
+				t.Fatalf("OpenPwnedPasswordIndex failed: %v", err)
+			}
+			defer index.Close()
+
+			for i := 0; i < 500; i++ {
+				count, found := index.Lookup(sha1.Sum([]byte(fmt.Sprintf("password-%d", i))))
+				if !found || count != i+1 {
+					t.Fatalf("Expected password-%d with count %d, got %d (found=%v)", i, i+1, count, found)
+				}
+			}
+			if _, found := index.Lookup(sha1.Sum([]byte("not-pwned"))); found {
+				t.Error("Expected unknown hash not to be found")
+			}
+
+			// 临时文件应已清理
+			entries, _ := os.ReadDir(dir)
+			if len(entries) != 2 {
+				t.Errorf("Expected only input and output files, got %d entries", len(entries))
+			}
+		})
+	}
+}
+
+func TestPwnedPasswordCompiler_Duplicates(t *testing.T) {
+	dir := t.TempDir()
+	hash := fmt.Sprintf("%x", sha1.Sum([]byte("password")))
+	input := filepath.Join(dir, "hashes.txt")
+	if err := os.WriteFile(input, []byte(hash+":3\n\n"+strings.ToUpper(hash)+":4\n"), 0600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	output := filepath.Join(dir, "out.bin")
+	stats, err := NewPwnedPasswordCompiler().Compile(input, output)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	if stats.Hashes != 1 || stats.Duplicates != 1 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	index, err := OpenPwnedPasswordIndex(output)
+	if err != nil {
+		t.Fatalf("OpenPwnedPasswordIndex failed: %v", err)
+	}
+	defer index.Close()
+	if count, _ := index.Lookup(sha1.Sum([]byte("password"))); count != 7 {
+		t.Errorf("Expected duplicate counts to be summed, got %d", count)
+	}
+}
+
+// 首尾两个前缀桶：0xFFFF的上界是索引的最后一项
+func TestPwnedPasswordIndex_EdgePrefixes(t *testing.T) {
+	dir := t.TempDir()
+	first := "0000" + strings.Repeat("1", 36)
+	last := "FFFF" + strings.Repeat("E", 36)
+	input := filepath.Join(dir, "hashes.txt")
+	if err := os.WriteFile(input, []byte(first+":5\n"+last+":9\n"), 0600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	output := filepath.Join(dir, "out.bin")
+	compiler := NewPwnedPasswordCompiler()
+	compiler.SetPrefixIndex(true)
+	if _, err := compiler.Compile(input, output); err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	index, err := OpenPwnedPasswordIndex(output)
+	if err != nil {
+		t.Fatalf("OpenPwnedPasswordIndex failed: %v", err)
+	}
+	defer index.Close()
+
+	for hexHash, expected := range map[string]int{first: 5, last: 9} {
+		var hash [sha1.Size]byte
+		if _, err := hex.Decode(hash[:], []byte(hexHash)); err != nil {
+			t.Fatalf("Decode failed: %v", err)
+		}
+		if count, found := index.Lookup(hash); !found || count != expected {
+			t.Errorf("%s: expected count %d, got %d (found=%v)", hexHash[:4], expected, count, found)
+		}
+	}
+}
+
+// 损坏的前缀索引在打开时报错，而不是在查找时越界
+func TestPwnedPasswordIndex_CorruptPrefixIndex(t *testing.T) {
+	dir := t.TempDir()
+	input := writePwnedInput(t, dir, 50, false)
+	output := filepath.Join(dir, "out.bin")
+	compiler := NewPwnedPasswordCompiler()
+	compiler.SetPrefixIndex(true)
+	if _, err := compiler.Compile(input, output); err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	indexStart := pwnedIndexHeaderSize + 50*pwnedRecordSize
+
+	testCases := []struct {
+		name   string
+		bucket int
+		value  uint64
+	}{
+		{"beyond record count", 1, 1000},
+		{"decreasing", pwnedPrefixBuckets - 1, 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			corrupt := slices.Clone(data)
+			binary.BigEndian.PutUint64(corrupt[indexStart+tc.bucket*8:], tc.value)
+			path := filepath.Join(t.TempDir(), "corrupt.bin")
+			if err := os.WriteFile(path, corrupt, 0600); err != nil {
+				t.Fatalf("WriteFile failed: %v", err)
+			}
+			if index, err := OpenPwnedPasswordIndex(path); err == nil {
+				index.Close()
+				t.Error("Expected a corrupt prefix index to be rejected")
+			}
+		})
+	}
+}
+
+func TestPwnedPasswordCompiler_InvalidInput(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "hashes.txt")
+	if err := os.WriteFile(input, []byte("not-a-hash:1\n"), 0600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	output := filepath.Join(dir, "out.bin")
+	if _, err := NewPwnedPasswordCompiler().Compile(input, output); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("Expected error mentioning the line, got %v", err)
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Error("Expected no output file after a failed import")
+	}
+}
+
+func TestDatabaseLoader_LoadPwnedPasswords(t *testing.T) {
+	dir := t.TempDir()
+	db := fmt.Sprintf(`{"last_updated": "2025-07-08", "passwords": {"%x": 5}}`, sha1.Sum([]byte("password")))
+	if err := os.WriteFile(filepath.Join(dir, "pwned_passwords_database.json"), []byte(db), 0600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	// 没有编译后的库时使用JSON库
+	loader := NewDatabaseLoader(dir)
+	passwords, err := loader.LoadPwnedPasswords()
+	if err != nil {
+		t.Fatalf("LoadPwnedPasswords failed: %v", err)
+	}
+	if count, _ := passwords.Lookup(sha1.Sum([]byte("password"))); count != 5 || passwords.LastUpdated() != "2025-07-08" {
+		t.Errorf("Expected JSON database, got count %d updated %s", count, passwords.LastUpdated())
+	}
+	passwords.Close()
+
+	// 编译后的库优先
+	input := writePwnedInput(t, dir, 10, false)
+	if _, err := NewPwnedPasswordCompiler().Compile(input, filepath.Join(dir, PwnedPasswordIndexFile)); err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	passwords, err = loader.LoadPwnedPasswords()
+	if err != nil {
+		t.Fatalf("LoadPwnedPasswords failed: %v", err)
+	}
+	defer passwords.Close()
+	if _, ok := passwords.(*PwnedPasswordIndex); !ok {
+		t.Errorf("Expected compiled index to be preferred, got %T", passwords)
+	}
+}
@@ -0,0 +1,381 @@
+package database
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultPwnedRangeURL 公共Pwned Passwords范围查询服务
+const DefaultPwnedRangeURL = "https://api.pwnedpasswords.com"
+
+// pwnedRangePrefixLength 发送给服务端的哈希前缀长度（十六进制字符）
+const pwnedRangePrefixLength = 5
+
+const (
+	defaultPwnedRangeWorkers = 8
+	defaultPwnedRangeRetries = 3
+	defaultPwnedRangeBackoff = 500 * time.Millisecond
+	maxPwnedRangeBackoff     = 30 * time.Second
+	// maxPwnedRangeResponse 单个前缀响应的大小上限，带填充时通常不到40KB
+	maxPwnedRangeResponse = 4 << 20
+)
+
+// PwnedPasswordBatchLookup 需要网络请求的查询源，按批并发查询并返回错误
+type PwnedPasswordBatchLookup interface {
+	PwnedPasswordLookup
+	LookupAll(ctx context.Context, hashes [][sha1.Size]byte) (map[[sha1.Size]byte]int, error)
+}
+
+// PwnedRangeClient k-匿名范围查询客户端：只发送SHA-1的前5个十六进制字符，在本地比对返回的后缀
+type PwnedRangeClient struct {
+	baseURL    string
+	httpClient *http.Client
+	padding    bool
+	workers    int
+	retries    int
+	backoff    time.Duration
+	cacheDir   string
+	cacheTTL   time.Duration
+
+	mu     sync.Mutex
+	counts map[[sha1.Size]byte]int // 本次运行已查询的哈希 -> 次数，只保留请求过的哈希，不保留整个前缀的响应
+}
+
+func NewPwnedRangeClient(baseURL string) *PwnedRangeClient {
+	return &PwnedRangeClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		padding:    true,
+		workers:    defaultPwnedRangeWorkers,
+		retries:    defaultPwnedRangeRetries,
+		backoff:    defaultPwnedRangeBackoff,
+		counts:     make(map[[sha1.Size]byte]int),
+	}
+}
+
+// SetHTTPClient 设置发送请求的HTTP客户端
+func (c *PwnedRangeClient) SetHTTPClient(client *http.Client) {
+	c.httpClient = client
+}
+
+// SetPadding 设置是否请求填充响应（Add-Padding），使响应大小不泄露前缀对应的条目数
+func (c *PwnedRangeClient) SetPadding(enabled bool) {
+	c.padding = enabled
+}
+
+// SetWorkers 设置并发请求数
+func (c *PwnedRangeClient) SetWorkers(workers int) {
+	if workers > 0 {
+		c.workers = workers
+	}
+}
+
+// SetRetries 设置网络错误、429和5xx的重试次数
+func (c *PwnedRangeClient) SetRetries(retries int) {
+	c.retries = max(retries, 0)
+}
+
+// SetBackoff 设置首次重试前的等待时间，之后每次翻倍
+func (c *PwnedRangeClient) SetBackoff(backoff time.Duration) {
+	c.backoff = backoff
+}
+
+// SetCache 设置前缀响应的磁盘缓存目录和有效期，dir为空时不缓存
+func (c *PwnedRangeClient) SetCache(dir string, ttl time.Duration) {
+	c.cacheDir = dir
+	c.cacheTTL = ttl
+}
+
+// Lookup 查询单个哈希；网络错误时视为未找到，需要错误信息时使用LookupAll
+func (c *PwnedRangeClient) Lookup(hash [sha1.Size]byte) (int, bool) {
+	counts, err := c.LookupAll(context.Background(), [][sha1.Size]byte{hash})
+	if err != nil {
+		return 0, false
+	}
+	count, exists := counts[hash]
+	return count, exists
+}
+
+// LastUpdated 在线查询的结果总是最新的，没有固定的更新日期
+func (c *PwnedRangeClient) LastUpdated() string {
+	return ""
+}
+
+func (c *PwnedRangeClient) Close() error {
+	c.httpClient.CloseIdleConnections()
+	return nil
+}
+
+// LookupAll 批量查询，相同前缀只请求一次；返回结果只包含出现在泄露数据中的哈希
+func (c *PwnedRangeClient) LookupAll(ctx context.Context, hashes [][sha1.Size]byte) (map[[sha1.Size]byte]int, error) {
+	// 按前缀分组，跳过本次运行已查询过的哈希
+	var prefixes []string
+	pending := make(map[string][][sha1.Size]byte)
+	c.mu.Lock()
+	for _, hash := range hashes {
+		if _, known := c.counts[hash]; known {
+			continue
+		}
+		prefix, _ := splitPwnedHash(hash)
+		if _, exists := pending[prefix]; !exists {
+			prefixes = append(prefixes, prefix)
+		}
+		pending[prefix] = append(pending[prefix], hash)
+	}
+	c.mu.Unlock()
+
+	if err := c.fetchAll(ctx, prefixes, pending); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[[sha1.Size]byte]int)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, hash := range hashes {
+		if count := c.counts[hash]; count > 0 {
+			counts[hash] = count
+		}
+	}
+	return counts, nil
+}
+
+// fetchAll 用固定数量的worker并发获取前缀，任一前缀失败时取消其余请求
+// 每个前缀的响应解析后只记录pending中请求的哈希的次数，随即释放
+func (c *PwnedRangeClient) fetchAll(ctx context.Context, prefixes []string, pending map[string][][sha1.Size]byte) error {
+	if len(prefixes) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan string)
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+
+	for range min(c.workers, len(prefixes)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for prefix := range jobs {
+				suffixes, err := c.fetchPrefix(ctx, prefix)
+				if err != nil {
+					errOnce.Do(func() {
+						firstErr = fmt.Errorf("range query for %s failed: %w", prefix, err)
+						cancel()
+					})
+					continue
+				}
+				c.mu.Lock()
+				for _, hash := range pending[prefix] {
+					_, suffix := splitPwnedHash(hash)
+					c.counts[hash] = suffixes[suffix]
+				}
+				c.mu.Unlock()
+			}
+		}()
+	}
+
+	for _, prefix := range prefixes {
+		select {
+		case jobs <- prefix:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// fetchPrefix 优先读取未过期的磁盘缓存，否则请求服务端并写入缓存
+func (c *PwnedRangeClient) fetchPrefix(ctx context.Context, prefix string) (map[string]int, error) {
+	if body, ok := c.readCache(prefix); ok {
+		if suffixes, err := parsePwnedRange(body); err == nil {
+			return suffixes, nil
+		}
+	}
+
+	body, err := c.request(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	suffixes, err := parsePwnedRange(body)
+	if err != nil {
+		return nil, err
+	}
+	c.writeCache(prefix, body)
+	return suffixes, nil
+}
+
+// request 发送范围查询，网络错误、429和5xx按指数退避重试，优先遵循Retry-After
+func (c *PwnedRangeClient) request(ctx context.Context, prefix string) ([]byte, error) {
+	url := c.baseURL + "/range/" + prefix
+
+	var lastErr error
+	for attempt := 0; attempt <= c.retries; attempt++ {
+		if attempt > 0 {
+			wait := c.backoff << (attempt - 1)
+			var retryAfter *pwnedRetryAfterError
+			if errors.As(lastErr, &retryAfter) {
+				wait = retryAfter.wait
+			}
+			select {
+			case <-time.After(min(wait, maxPwnedRangeBackoff)):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
+		body, retry, err := c.requestOnce(ctx, url)
+		if err == nil {
+			return body, nil
+		}
+		if !retry || ctx.Err() != nil {
+			return nil, err
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("giving up after %d attempts: %w", c.retries+1, lastErr)
+}
+
+// pwnedRetryAfterError 服务端通过Retry-After指定了重试等待时间
+type pwnedRetryAfterError struct {
+	status int
+	wait   time.Duration
+}
+
+func (e *pwnedRetryAfterError) Error() string {
+	return fmt.Sprintf("unexpected status %d (retry after %s)", e.status, e.wait)
+}
+
+// requestOnce 发送一次请求，返回的bool表示失败是否值得重试
+func (c *PwnedRangeClient) requestOnce(ctx context.Context, url string) ([]byte, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	req.Header.Set("User-Agent", "unpass")
+	if c.padding {
+		req.Header.Set("Add-Padding", "true")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, maxPwnedRangeResponse))
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return nil, true, &pwnedRetryAfterError{status: resp.StatusCode, wait: time.Duration(seconds) * time.Second}
+		}
+		return nil, true, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPwnedRangeResponse+1))
+	if err != nil {
+		return nil, true, err
+	}
+	if len(body) > maxPwnedRangeResponse {
+		return nil, false, errors.New("response too large")
+	}
+	return body, false, nil
+}
+
+// readCache 读取未过期的缓存响应
+func (c *PwnedRangeClient) readCache(prefix string) ([]byte, bool) {
+	if c.cacheDir == "" {
+		return nil, false
+	}
+	path := filepath.Join(c.cacheDir, prefix)
+	info, err := os.Stat(path)
+	if err != nil || time.Since(info.ModTime()) > c.cacheTTL {
+		return nil, false
+	}
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	return body, true
+}
+
+// writeCache 原子写入缓存，失败不影响查询结果
+func (c *PwnedRangeClient) writeCache(prefix string, body []byte) {
+	if c.cacheDir == "" {
+		return
+	}
+	if err := os.MkdirAll(c.cacheDir, 0o700); err != nil {
+		return
+	}
+	file, err := os.CreateTemp(c.cacheDir, prefix+".*.tmp")
+	if err != nil {
+		return
+	}
+	_, err = file.Write(body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), filepath.Join(c.cacheDir, prefix))
+	}
+	if err != nil {
+		os.Remove(file.Name())
+	}
+}
+
+// splitPwnedHash 拆分为大写十六进制的前缀和后缀
+func splitPwnedHash(hash [sha1.Size]byte) (string, string) {
+	text := strings.ToUpper(hex.EncodeToString(hash[:]))
+	return text[:pwnedRangePrefixLength], text[pwnedRangePrefixLength:]
+}
+
+// parsePwnedRange 解析"后缀:次数"行；填充条目的次数为0，查询时自然忽略
+func parsePwnedRange(body []byte) (map[string]int, error) {
+	suffixes := make(map[string]int)
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		suffix, countText, found := strings.Cut(line, ":")
+		if !found || len(suffix) != hex.EncodedLen(sha1.Size)-pwnedRangePrefixLength {
+			return nil, fmt.Errorf("malformed range response line: %q", line)
+		}
+		count, err := strconv.Atoi(countText)
+		if err != nil || count < 0 {
+			return nil, fmt.Errorf("malformed range response count: %q", line)
+		}
+		if count > 0 {
+			suffixes[strings.ToUpper(suffix)] = count
+		}
+	}
+	return suffixes, scanner.Err()
+}
@@ -0,0 +1,169 @@
+package database
+
+import (
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// pwnedRangeServer 模拟范围查询服务，只认识known中的密码；failures个请求先返回503
+type pwnedRangeServer struct {
+	known    map[string]int
+	failures atomic.Int32
+	requests atomic.Int32
+
+	mu       sync.Mutex
+	prefixes []string
+	padded   bool
+}
+
+func (s *pwnedRangeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.requests.Add(1)
+	prefix, found := strings.CutPrefix(r.URL.Path, "/range/")
+	if !found || len(prefix) != 5 {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	s.prefixes = append(s.prefixes, prefix)
+	s.padded = r.Header.Get("Add-Padding") == "true"
+	s.mu.Unlock()
+
+	if s.failures.Add(-1) >= 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	for password, count := range s.known {
+		hash := fmt.Sprintf("%X", sha1.Sum([]byte(password)))
+		if hash[:5] == prefix {
+			fmt.Fprintf(w, "%s:%d\r\n", hash[5:], count)
+		}
+	}
+	// 填充条目的次数为0
+	if r.Header.Get("Add-Padding") == "true" {
+		fmt.Fprintf(w, "%s:0\r\n", strings.Repeat("0", 35))
+	}
+}
+
+func newTestRangeClient(t *testing.T, server *pwnedRangeServer) *PwnedRangeClient {
+	t.Helper()
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+
+	client := NewPwnedRangeClient(httpServer.URL + "/")
+	client.SetBackoff(time.Millisecond)
+	return client
+}
+
+func TestPwnedRangeClient_LookupAll(t *testing.T) {
+	server := &pwnedRangeServer{known: map[string]int{"password": 42, "123456": 7}}
+	server.failures.Store(1)
+	client := newTestRangeClient(t, server)
+	client.SetWorkers(2)
+
+	hashes := [][sha1.Size]byte{
+		sha1.Sum([]byte("password")),
+		sha1.Sum([]byte("123456")),
+		sha1.Sum([]byte("k8#Vq!2mZr")),
+		sha1.Sum([]byte("password")),
+	}
+	counts, err := client.LookupAll(context.Background(), hashes)
+	if err != nil {
+		t.Fatalf("LookupAll failed: %v", err)
+	}
+	if len(counts) != 2 || counts[hashes[0]] != 42 || counts[hashes[1]] != 7 {
+		t.Errorf("Unexpected counts: %v", counts)
+	}
+
+	// 只发送前缀，重复的前缀只请求一次，503后重试
+	if got := server.requests.Load(); got != 4 {
+		t.Errorf("Expected 3 prefixes plus one retry, got %d requests", got)
+	}
+	for _, prefix := range server.prefixes {
+		if len(prefix) != 5 {
+			t.Errorf("Expected 5-character prefix, got %q", prefix)
+		}
+	}
+	if !server.padded {
+		t.Error("Expected Add-Padding header by default")
+	}
+
+	// 只保留请求过的哈希，不保留前缀响应中的其他后缀
+	if len(client.counts) != 3 {
+		t.Errorf("Expected counts for the 3 requested hashes only, got %d", len(client.counts))
+	}
+
+	// 本次运行已查询的哈希不再请求
+	if count, found := client.Lookup(sha1.Sum([]byte("password"))); !found || count != 42 {
+		t.Errorf("Expected cached lookup, got %d (found=%v)", count, found)
+	}
+	if got := server.requests.Load(); got != 4 {
+		t.Errorf("Expected no new requests, got %d", got)
+	}
+}
+
+func TestPwnedRangeClient_GivesUp(t *testing.T) {
+	server := &pwnedRangeServer{}
+	server.failures.Store(100)
+	client := newTestRangeClient(t, server)
+	client.SetRetries(2)
+
+	_, err := client.LookupAll(context.Background(), [][sha1.Size]byte{sha1.Sum([]byte("password"))})
+	if err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("Expected error after retries, got %v", err)
+	}
+	if got := server.requests.Load(); got != 3 {
+		t.Errorf("Expected 3 attempts, got %d", got)
+	}
+
+	// 查询失败时单条查询视为未找到
+	if _, found := client.Lookup(sha1.Sum([]byte("password"))); found {
+		t.Error("Expected failed lookup not to report a match")
+	}
+}
+
+func TestPwnedRangeClient_Cache(t *testing.T) {
+	cacheDir := t.TempDir()
+	server := &pwnedRangeServer{known: map[string]int{"password": 42}}
+	hash := sha1.Sum([]byte("password"))
+
+	client := newTestRangeClient(t, server)
+	client.SetCache(cacheDir, time.Hour)
+	if count, _ := client.Lookup(hash); count != 42 {
+		t.Fatalf("Expected count 42, got %d", count)
+	}
+
+	// 新客户端读取磁盘缓存，不再请求
+	client = newTestRangeClient(t, server)
+	client.SetCache(cacheDir, time.Hour)
+	if count, _ := client.Lookup(hash); count != 42 {
+		t.Errorf("Expected cached count 42, got %d", count)
+	}
+	if got := server.requests.Load(); got != 1 {
+		t.Errorf("Expected 1 request with a warm cache, got %d", got)
+	}
+
+	// 过期的缓存重新请求
+	cached := filepath.Join(cacheDir, fmt.Sprintf("%X", hash)[:5])
+	expired := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(cached, expired, expired); err != nil {
+		t.Fatalf("Chtimes failed: %v", err)
+	}
+	client = newTestRangeClient(t, server)
+	client.SetCache(cacheDir, time.Hour)
+	client.Lookup(hash)
+	if got := server.requests.Load(); got != 2 {
+		t.Errorf("Expected expired cache entry to be refreshed, got %d requests", got)
+	}
+}
@@ -11,6 +11,12 @@ type Detector interface {
 	Configure(config map[string]interface{}) error
 }
 
+// Finisher 需要看到所有凭据才能判断的检测器（如跨凭据比较）
+// Detect只累积状态，所有批次处理完后由引擎调用Finish取得结果
+type Finisher interface {
+	Finish(ctx context.Context) ([]types.DetectionResult, error)
+}
+
 type Registry struct {
 	detectors map[string]Detector
 }
@@ -0,0 +1,22 @@
+package detector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTestDatabases 在临时目录中写入2FA和Passkey数据库，内容为空时不写入对应文件
+func writeTestDatabases(t *testing.T, twofaDB, passkeyDB string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range map[string]string{"2fa_database.json": twofaDB, "passkey_database.json": passkeyDB} {
+		if content == "" {
+			continue
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+	return dir
+}
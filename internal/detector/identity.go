@@ -0,0 +1,305 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/yourorg/unpass/internal/database"
+	"github.com/yourorg/unpass/internal/domain"
+	"github.com/yourorg/unpass/internal/types"
+)
+
+// 泄露到密码中的身份信息来源
+const (
+	identityUsername       = "username"
+	identityEmailLocalPart = "email_local_part"
+	identityTitle          = "title"
+	identitySiteDomain     = "site_domain" // 主域名中的标签，如github.com的github
+	identitySiteName       = "site_name"   // Passkey数据库中的站点名称
+)
+
+// identityLabels 报告消息中各来源的说法
+var identityLabels = map[string]string{
+	identityUsername:       "username",
+	identityEmailLocalPart: "email name",
+	identityTitle:          "item title",
+	identitySiteDomain:     "site domain",
+	identitySiteName:       "site name",
+}
+
+// identityFolding 常见的字母替代统一折叠到同一个字母，密码和身份信息按同样的规则折叠后比较
+// l和1、!、|都折叠为i，使a1ice、al1ce都能匹配alice
+var identityFolding = map[rune]rune{
+	'0': 'o',
+	'1': 'i', '!': 'i', '|': 'i', 'l': 'i',
+	'3': 'e',
+	'4': 'a', '@': 'a',
+	'5': 's', '$': 's',
+	'6': 'g', '9': 'g',
+	'7': 't', '+': 't',
+	'8': 'b',
+}
+
+// genericTitleWords 标题中常见但不能代表账户身份的词
+var genericTitleWords = map[string]bool{
+	"the": true, "and": true, "for": true, "www": true, "com": true,
+	"login": true, "account": true, "password": true, "sign": true,
+	"app": true, "web": true, "online": true, "personal": true, "work": true,
+}
+
+// identityToken 待检查的身份片段，value为折叠后的字符，raw为对应的小写字符
+type identityToken struct {
+	component string
+	value     []rune
+	raw       []rune
+}
+
+// IdentityDetector 检测用用户名、邮箱名、标题或站点名拼成的密码，如github-alice-2024
+// 比较时不区分大小写，并折叠常见的字母替代、检查倒写；报告只列出泄露的身份信息来源，不包含密码
+type IdentityDetector struct {
+	siteNames     map[string]string // 主域名 -> Passkey数据库中的站点名称
+	domainMatcher *domain.DomainMatcher
+}
+
+func NewIdentityDetector(dbLoader *database.DatabaseLoader) (*IdentityDetector, error) {
+	// 站点名称来自Passkey数据库，不存在时只检查域名
+	twofaDB, _ := dbLoader.LoadTwoFADatabase()
+	passkeyDB, _ := dbLoader.LoadPasskeyDatabase()
+
+	siteNames := make(map[string]string)
+	if passkeyDB != nil {
+		for _, site := range *passkeyDB {
+			if site.Name != "" && site.Domain != "" {
+				siteNames[strings.ToLower(site.Domain)] = site.Name
+			}
+		}
+	}
+
+	return &IdentityDetector{
+		siteNames:     siteNames,
+		domainMatcher: domain.NewDomainMatcher(twofaDB, passkeyDB),
+	}, nil
+}
+
+func (d *IdentityDetector) Name() string {
+	return "identity"
+}
+
+func (d *IdentityDetector) Detect(ctx context.Context, creds []types.Credential) ([]types.DetectionResult, error) {
+	var results []types.DetectionResult
+
+	for _, cred := range creds {
+		if cred.Password == "" {
+			continue
+		}
+
+		var zones []string
+		for _, url := range credentialURLs(cred) {
+			if zone := d.siteZone(url); zone != "" && !slices.Contains(zones, zone) {
+				zones = append(zones, zone)
+			}
+		}
+
+		password := []rune(cred.Password)
+		folded := foldIdentity(cred.Password)
+		covered := make([]bool, len(folded))
+		var components []string
+		leetspeak, reversed := false, false
+
+		for _, token := range d.identityTokens(cred, zones) {
+			matched := false
+			for _, direction := range []bool{false, true} {
+				candidate, raw := token.value, token.raw
+				if direction {
+					candidate, raw = reverseRunes(candidate), reverseRunes(raw)
+					// 回文正反相同，不算倒写
+					if slices.Equal(candidate, token.value) {
+						continue
+					}
+				}
+				for start := range indexAll(folded, candidate) {
+					matched = true
+					reversed = reversed || direction
+					segment := password[start : start+len(candidate)]
+					for i := range segment {
+						covered[start+i] = true
+						// 折叠前不相同说明使用了字母替代
+						if unicode.ToLower(segment[i]) != raw[i] {
+							leetspeak = true
+						}
+					}
+				}
+			}
+			if matched && !slices.Contains(components, token.component) {
+				components = append(components, token.component)
+			}
+		}
+		if len(components) == 0 {
+			continue
+		}
+		slices.Sort(components)
+
+		// 去掉身份信息后只剩数字和符号的密码几乎不需要猜测
+		severity := types.SeverityMedium
+		if len(components) >= 2 || !hasUncoveredLetter(password, covered) {
+			severity = types.SeverityHigh
+		}
+
+		metadata := map[string]interface{}{
+			"components": components,
+			"leetspeak":  leetspeak,
+			"reversed":   reversed,
+		}
+		if len(zones) > 0 {
+			metadata["domain"] = zones[0]
+		}
+
+		labels := make([]string, len(components))
+		for i, component := range components {
+			labels[i] = identityLabels[component]
+		}
+
+		results = append(results, types.DetectionResult{
+			CredentialID: cred.ID,
+			Title:        cred.Title,
+			Type:         types.DetectionIdentityInPassword,
+			Severity:     severity,
+			Message:      fmt.Sprintf("Password contains the item's %s", joinWords(labels, "and")),
+			Metadata:     metadata,
+		})
+	}
+
+	return results, nil
+}
+
+func (d *IdentityDetector) Configure(config map[string]interface{}) error {
+	return nil
+}
+
+// identityTokens 凭据的用户名、邮箱名、标题单词、主域名标签和站点名称，折叠后短于3个字符的片段不检查
+func (d *IdentityDetector) identityTokens(cred types.Credential, zones []string) []identityToken {
+	var tokens []identityToken
+	add := func(component, value string) {
+		folded := foldIdentity(value)
+		if len(folded) < minSiteTokenLength {
+			return
+		}
+		for _, token := range tokens {
+			if token.component == component && slices.Equal(token.value, folded) {
+				return
+			}
+		}
+		raw := []rune(value)
+		for i, r := range raw {
+			raw[i] = unicode.ToLower(r)
+		}
+		tokens = append(tokens, identityToken{component: component, value: folded, raw: raw})
+	}
+
+	username := strings.TrimSpace(cred.Username)
+	if local, _, found := strings.Cut(username, "@"); found {
+		add(identityEmailLocalPart, local)
+		for _, part := range strings.FieldsFunc(local, isSymbol) {
+			add(identityEmailLocalPart, part)
+		}
+	} else if username != "" {
+		add(identityUsername, username)
+		for _, part := range strings.FieldsFunc(username, isSymbol) {
+			add(identityUsername, part)
+		}
+	}
+
+	for _, word := range strings.FieldsFunc(cred.Title, isSymbol) {
+		if !genericTitleWords[strings.ToLower(word)] {
+			add(identityTitle, word)
+		}
+	}
+
+	for _, zone := range zones {
+		// 去掉后缀，com、co等后缀标签不能代表站点
+		_, suffix := domain.SplitHost(zone)
+		for _, label := range strings.Split(strings.TrimSuffix(zone, "."+suffix), ".") {
+			if domain.IsSuffixLabel(label) {
+				continue
+			}
+			add(identitySiteDomain, label)
+			for _, part := range strings.Split(label, "-") {
+				add(identitySiteDomain, part)
+			}
+		}
+		if name, exists := d.siteNames[zone]; exists {
+			add(identitySiteName, strings.Map(func(r rune) rune {
+				if isSymbol(r) {
+					return -1
+				}
+				return r
+			}, name))
+		}
+	}
+
+	return tokens
+}
+
+// siteZone 返回地址的主域名；未收录的域名按二级后缀拆分，如netbank.mybank.com.au为mybank.com.au而不是com.au
+func (d *IdentityDetector) siteZone(rawURL string) string {
+	zone := d.domainMatcher.ExtractHostedZone(rawURL)
+	if label, _ := domain.SplitHost(zone); !domain.IsSuffixLabel(label) {
+		return zone
+	}
+	if host := loginHost(rawURL); host != "" {
+		label, suffix := domain.SplitHost(host)
+		return label + "." + suffix
+	}
+	return zone
+}
+
+// foldIdentity 逐字符折叠，结果与原密码的字符一一对应
+func foldIdentity(value string) []rune {
+	folded := []rune(value)
+	for i, r := range folded {
+		r = unicode.ToLower(r)
+		if replacement, exists := identityFolding[r]; exists {
+			r = replacement
+		}
+		folded[i] = r
+	}
+	return folded
+}
+
+// indexAll 返回needle在haystack中所有出现位置的起点
+func indexAll(haystack, needle []rune) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i := 0; i+len(needle) <= len(haystack); i++ {
+			if slices.Equal(haystack[i:i+len(needle)], needle) && !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+func reverseRunes(value []rune) []rune {
+	reversed := slices.Clone(value)
+	slices.Reverse(reversed)
+	return reversed
+}
+
+func hasUncoveredLetter(password []rune, covered []bool) bool {
+	for i, r := range password {
+		if !covered[i] && unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// joinWords 用英文习惯连接，conjunction为and或or：a、a and b、a, b and c
+func joinWords(words []string, conjunction string) string {
+	if len(words) <= 1 {
+		return strings.Join(words, "")
+	}
+	return strings.Join(words[:len(words)-1], ", ") + " " + conjunction + " " + words[len(words)-1]
+}
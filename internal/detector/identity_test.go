@@ -0,0 +1,103 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/yourorg/unpass/internal/database"
+	"github.com/yourorg/unpass/internal/types"
+)
+
+func newTestIdentityDetector(t *testing.T) *IdentityDetector {
+	t.Helper()
+	passkeyDB := `[{"name": "Acme Bank", "domain": "acmebank-online.com", "approved": true, "passkey_signin": true}]`
+
+	detector, err := NewIdentityDetector(database.NewDatabaseLoader(writeTestDatabases(t, "", passkeyDB)))
+	if err != nil {
+		t.Fatalf("NewIdentityDetector failed: %v", err)
+	}
+	return detector
+}
+
+func TestIdentityDetector(t *testing.T) {
+	detector := newTestIdentityDetector(t)
+	creds := []types.Credential{
+		{ID: "1", Title: "GitHub", Username: "alice", URL: "https://github.com", Password: "github-alice-2024"},
+		{ID: "2", Title: "Mail", Username: "bob.jones@example.org", URL: "https://mail.example.org", Password: "J0n3s!rocks"},
+		{ID: "3", Title: "Bank", URL: "https://www.acmebank-online.com", Password: "acmebank#1"},
+		{ID: "4", Title: "Forum", Username: "carol", URL: "https://forum.example.net", Password: "lorac-pw-99"},
+		{ID: "5", Title: "Shop", Username: "dave", URL: "https://shop.example.com", Password: "correct horse battery"},
+		{ID: "6", Title: "Empty", Username: "erin"},
+	}
+
+	results, err := detector.Detect(context.Background(), creds)
+	if err != nil {
+		t.Fatalf("Detect failed: %v", err)
+	}
+	if len(results) != 4 {
+		t.Fatalf("Expected 4 results, got %d: %+v", len(results), results)
+	}
+
+	tests := []struct {
+		id         string
+		components []string
+		severity   types.Severity
+		leetspeak  bool
+		reversed   bool
+	}{
+		{"1", []string{"site_domain", "title", "username"}, types.SeverityHigh, false, false},
+		{"2", []string{"email_local_part"}, types.SeverityMedium, true, false},
+		{"3", []string{"site_domain", "site_name", "title"}, types.SeverityHigh, false, false},
+		{"4", []string{"username"}, types.SeverityMedium, false, true},
+	}
+	for i, test := range tests {
+		result := results[i]
+		if result.CredentialID != test.id || result.Type != types.DetectionIdentityInPassword || result.Severity != test.severity {
+			t.Errorf("Unexpected result for %s: %+v", test.id, result)
+			continue
+		}
+		if components := result.Metadata["components"].([]string); !slices.Equal(components, test.components) {
+			t.Errorf("%s: expected components %v, got %v", test.id, test.components, components)
+		}
+		if result.Metadata["leetspeak"] != test.leetspeak || result.Metadata["reversed"] != test.reversed {
+			t.Errorf("%s: unexpected leetspeak/reversed flags: %v", test.id, result.Metadata)
+		}
+
+		// 报告中不能出现密码
+		password := strings.ToLower(creds[i].Password)
+		if strings.Contains(strings.ToLower(result.Message), password) || strings.Contains(strings.ToLower(fmt.Sprint(result.Metadata)), password) {
+			t.Errorf("%s: result leaks the password: %+v", test.id, result)
+		}
+	}
+
+	if !strings.Contains(results[0].Message, "site domain, item title and username") {
+		t.Errorf("Unexpected message: %s", results[0].Message)
+	}
+}
+
+// 未收录的域名不能把com、net等后缀标签当作站点名
+func TestIdentityDetectorSecondLevelSuffix(t *testing.T) {
+	detector := newTestIdentityDetector(t)
+	creds := []types.Credential{
+		{ID: "1", Title: "Banking", URL: "https://netbank.mybank.com.au", Password: "Welcome#Kx9v!"},
+		{ID: "2", Title: "Banking", URL: "https://netbank.mybank.com.au", Password: "mybank-2024"},
+		{ID: "3", Title: "Orders", URL: "https://orgchart.example.org", Password: "Org#Kx9v!ab"},
+	}
+
+	results, err := detector.Detect(context.Background(), creds)
+	if err != nil {
+		t.Fatalf("Detect failed: %v", err)
+	}
+	if len(results) != 1 || results[0].CredentialID != "2" {
+		t.Fatalf("Expected only the password containing mybank, got %+v", results)
+	}
+	if results[0].Metadata["domain"] != "mybank.com.au" {
+		t.Errorf("Expected domain mybank.com.au, got %v", results[0].Metadata["domain"])
+	}
+	if components := results[0].Metadata["components"].([]string); !slices.Equal(components, []string{"site_domain"}) {
+		t.Errorf("Expected site_domain, got %v", components)
+	}
+}
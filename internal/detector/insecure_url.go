@@ -0,0 +1,246 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/yourorg/unpass/internal/domain"
+	"github.com/yourorg/unpass/internal/types"
+)
+
+// 登录地址的安全问题
+const (
+	urlIssuePlainHTTP           = "plain_http"           // 未加密的http登录页
+	urlIssueEmbeddedCredentials = "embedded_credentials" // user:pass@形式的userinfo
+	urlIssueIPAddress           = "ip_address"           // 直接使用公网IP，无法校验证书中的域名
+	urlIssueNonStandardPort     = "non_standard_port"
+	urlIssueTokenInQuery        = "token_in_query" // 查询串或片段中的令牌、会话ID等
+)
+
+// urlIssueMessages 报告消息中各问题的说法
+var urlIssueMessages = map[string]string{
+	urlIssuePlainHTTP:           "uses plain HTTP",
+	urlIssueEmbeddedCredentials: "embeds credentials",
+	urlIssueIPAddress:           "points at a bare IP address",
+	urlIssueNonStandardPort:     "uses a non-standard port",
+	urlIssueTokenInQuery:        "carries a token in the query string",
+}
+
+// sensitiveQueryParams 值通常是令牌或会话凭据的查询参数（小写）
+var sensitiveQueryParams = map[string]bool{
+	"token": true, "access_token": true, "id_token": true, "refresh_token": true, "auth_token": true,
+	"auth": true, "authorization": true, "jwt": true, "bearer": true,
+	"session": true, "sessionid": true, "session_id": true, "sid": true, "phpsessid": true, "jsessionid": true,
+	"key": true, "api_key": true, "apikey": true, "secret": true, "client_secret": true,
+	"password": true, "passwd": true, "pwd": true, "pass": true,
+	"otp": true, "ticket": true, "sig": true, "signature": true,
+}
+
+// sensitiveQueryKeywords 参数名包含这些词时也视为敏感参数
+var sensitiveQueryKeywords = []string{"token", "session", "secret", "password", "apikey", "api_key"}
+
+// severityRank 用于取多个问题中最高的严重程度
+var severityRank = map[types.Severity]int{
+	types.SeverityLow:      1,
+	types.SeverityMedium:   2,
+	types.SeverityHigh:     3,
+	types.SeverityCritical: 4,
+}
+
+// InsecureURLDetector 检查凭据保存的原始登录地址
+// 域名匹配器会把地址统一补全为https并丢弃协议、端口、userinfo和路径，这里直接解析原始地址
+// 每个有问题的地址产生一个结果，报告中的地址去掉了userinfo、查询串和片段
+type InsecureURLDetector struct {
+	domainMatcher *domain.DomainMatcher
+}
+
+func NewInsecureURLDetector() *InsecureURLDetector {
+	return &InsecureURLDetector{
+		domainMatcher: domain.NewDomainMatcher(nil, nil),
+	}
+}
+
+func (d *InsecureURLDetector) Name() string {
+	return "insecure_url"
+}
+
+func (d *InsecureURLDetector) Detect(ctx context.Context, creds []types.Credential) ([]types.DetectionResult, error) {
+	var results []types.DetectionResult
+
+	for _, cred := range creds {
+		// 同一地址在URL和URLs中重复出现时只检查一次
+		var checked []string
+		for _, rawURL := range credentialURLs(cred) {
+			rawURL = strings.TrimSpace(rawURL)
+			if rawURL == "" || slices.Contains(checked, rawURL) {
+				continue
+			}
+			checked = append(checked, rawURL)
+
+			finding, ok := inspectLoginURL(rawURL)
+			if !ok || len(finding.issues) == 0 {
+				continue
+			}
+
+			messages := make([]string, len(finding.issues))
+			for i, issue := range finding.issues {
+				messages[i] = urlIssueMessages[issue]
+			}
+
+			metadata := map[string]interface{}{
+				"url":    finding.redacted,
+				"issues": finding.issues,
+			}
+			if len(finding.queryParams) > 0 {
+				metadata["query_parameters"] = finding.queryParams
+			}
+			if zone := hostedZone(d.domainMatcher, rawURL); zone != "" {
+				metadata["domain"] = zone
+			}
+
+			results = append(results, types.DetectionResult{
+				CredentialID: cred.ID,
+				Title:        cred.Title,
+				Type:         types.DetectionInsecureURL,
+				Severity:     finding.severity,
+				Message:      fmt.Sprintf("Login URL %s", joinWords(messages, "and")),
+				Metadata:     metadata,
+			})
+		}
+	}
+
+	return results, nil
+}
+
+func (d *InsecureURLDetector) Configure(config map[string]interface{}) error {
+	return nil
+}
+
+// urlFinding 单个地址的检查结果
+type urlFinding struct {
+	issues      []string
+	severity    types.Severity
+	redacted    string
+	queryParams []string // 敏感参数的名称，不包含值
+}
+
+func (f *urlFinding) add(issue string, severity types.Severity) {
+	f.issues = append(f.issues, issue)
+	if severityRank[severity] > severityRank[f.severity] {
+		f.severity = severity
+	}
+}
+
+// inspectLoginURL 解析原始地址；没有协议的地址按https处理，不报告明文传输；非http(s)地址（如应用ID）不检查
+func inspectLoginURL(rawURL string) (urlFinding, bool) {
+	hasScheme := strings.Contains(rawURL, "://")
+	if !hasScheme {
+		rawURL = "https://" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return urlFinding{}, false
+	}
+	scheme := strings.ToLower(u.Scheme)
+	if scheme != "http" && scheme != "https" {
+		return urlFinding{}, false
+	}
+
+	var finding urlFinding
+	host := strings.ToLower(u.Hostname())
+	local := isLocalHost(host)
+
+	if scheme == "http" {
+		// 本机和内网设备（如路由器管理页）常见http，风险较低
+		if local {
+			finding.add(urlIssuePlainHTTP, types.SeverityLow)
+		} else {
+			finding.add(urlIssuePlainHTTP, types.SeverityHigh)
+		}
+	}
+
+	if u.User != nil {
+		if _, hasPassword := u.User.Password(); hasPassword {
+			finding.add(urlIssueEmbeddedCredentials, types.SeverityCritical)
+		} else {
+			finding.add(urlIssueEmbeddedCredentials, types.SeverityHigh)
+		}
+	}
+
+	if ip := net.ParseIP(host); ip != nil && !local {
+		finding.add(urlIssueIPAddress, types.SeverityMedium)
+	}
+
+	if port := u.Port(); port != "" && !(scheme == "http" && port == "80") && !(scheme == "https" && port == "443") {
+		finding.add(urlIssueNonStandardPort, types.SeverityLow)
+	}
+
+	// OAuth隐式授权等流程把令牌放在片段中，同样按查询串检查
+	for _, raw := range []string{u.RawQuery, u.Fragment} {
+		values, err := url.ParseQuery(raw)
+		if err != nil && len(values) == 0 {
+			continue
+		}
+		for name, value := range values {
+			if isSensitiveQueryParam(name) && slices.ContainsFunc(value, func(v string) bool { return v != "" }) &&
+				!slices.Contains(finding.queryParams, name) {
+				finding.queryParams = append(finding.queryParams, name)
+			}
+		}
+	}
+	if len(finding.queryParams) > 0 {
+		slices.Sort(finding.queryParams)
+		finding.add(urlIssueTokenInQuery, types.SeverityHigh)
+	}
+
+	redacted := url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}
+	finding.redacted = redacted.String()
+	return finding, true
+}
+
+func isSensitiveQueryParam(name string) bool {
+	name = strings.ToLower(name)
+	if sensitiveQueryParams[name] {
+		return true
+	}
+	for _, keyword := range sensitiveQueryKeywords {
+		if strings.Contains(name, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostedZone 返回地址的主域名；IP地址没有主域名，返回IP本身，避免把192.168.1.1截成1.1
+func hostedZone(matcher *domain.DomainMatcher, rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return ""
+	}
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+	if u, err := url.Parse(rawURL); err == nil {
+		if host := strings.ToLower(u.Hostname()); net.ParseIP(host) != nil {
+			return host
+		}
+	}
+	return matcher.ExtractHostedZone(rawURL)
+}
+
+// isLocalHost 本机、内网地址和.local等局域网主机名
+func isLocalHost(host string) bool {
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") ||
+		strings.HasSuffix(host, ".local") || strings.HasSuffix(host, ".lan") || strings.HasSuffix(host, ".internal") {
+		return true
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsUnspecified()
+	}
+	// 没有点的主机名只能在内网解析
+	return !strings.Contains(host, ".")
+}
@@ -0,0 +1,448 @@
+package detector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/yourorg/unpass/internal/database"
+	"github.com/yourorg/unpass/internal/domain"
+	"github.com/yourorg/unpass/internal/types"
+)
+
+// 仿冒已知站点的手法
+const (
+	lookalikePunycode      = "punycode"       // 国际化域名，显示为Unicode
+	lookalikeHomoglyph     = "homoglyph"      // 混淆骨架与已知站点相同，如paypa1、rnicrosoft
+	lookalikeExtraHyphen   = "extra_hyphen"   // 插入连字符或以连字符拼接，如pay-pal、paypal-secure
+	lookalikeTypo          = "typo"           // 编辑距离为1或相邻字母互换
+	lookalikeTLDSwap       = "tld_swap"       // 同名换了顶级域名，如paypal.net
+	lookalikeBrandMismatch = "brand_mismatch" // 标题是已知站点，地址却指向别处
+)
+
+const (
+	// minLookalikeLabelLength 短于该长度的标签容易与无关站点撞上，不做骨架和连字符比较
+	minLookalikeLabelLength = 4
+	// minBrandLabelLength 标签至少这么长才作为品牌名称匹配标题和连字符拼接的域名
+	minBrandLabelLength = 5
+	// minTypoLabelLength 编辑距离和换顶级域名比较只针对较长的标签，cloud、lever这类短标签多为常见词
+	minTypoLabelLength = 6
+)
+
+// LookalikeDetector 比较凭据地址的主域名与2FA、Passkey数据库中的所有已知域名，识别paypa1.com这类仿冒域名
+// 依次检查punycode解码后的混淆骨架（Unicode TR39）、连字符、编辑距离和换顶级域名，并标记标题是已知站点而地址指向别处的凭据
+// 地址属于已知站点（含其子域名）时不报告；数据库中的子域名条目只标记为已知，其主域名标签（如cloud.microsoft的cloud）不作为仿冒目标
+type LookalikeDetector struct {
+	knownDomains map[string]bool
+	labels       map[string][]string // 主域名标签 -> 已知域名
+	skeletons    map[string][]string // 标签的混淆骨架 -> 已知标签
+	brands       map[string]string   // 归一化的站点名称或标签 -> 已知域名
+	deletions    map[string][]string // 删除一个字符后的标签（及标签本身）-> 编辑距离比较的候选标签
+}
+
+func NewLookalikeDetector(dbLoader *database.DatabaseLoader) (*LookalikeDetector, error) {
+	// 两个数据库至少需要一个
+	twofaDB, twofaErr := dbLoader.LoadTwoFADatabase()
+	passkeyDB, passkeyErr := dbLoader.LoadPasskeyDatabase()
+	if twofaDB == nil && passkeyDB == nil {
+		return nil, fmt.Errorf("failed to load site databases: %w", errors.Join(twofaErr, passkeyErr))
+	}
+
+	d := &LookalikeDetector{
+		knownDomains: make(map[string]bool),
+		labels:       make(map[string][]string),
+		skeletons:    make(map[string][]string),
+		brands:       make(map[string]string),
+		deletions:    make(map[string][]string),
+	}
+	if twofaDB != nil {
+		for _, site := range twofaDB.Sites {
+			d.addSite(site.Domain, "")
+		}
+	}
+	if passkeyDB != nil {
+		for _, site := range *passkeyDB {
+			if site.Approved && !site.Hidden {
+				d.addSite(site.Domain, site.Name)
+			}
+		}
+	}
+
+	for label, domains := range d.labels {
+		slices.Sort(domains)
+		length := utf8.RuneCountInString(label)
+		if length >= minBrandLabelLength {
+			if _, exists := d.brands[label]; !exists {
+				d.brands[label] = preferredDomain(domains)
+			}
+		}
+		if length >= minTypoLabelLength {
+			for _, variant := range deletionVariants(label) {
+				d.deletions[variant] = append(d.deletions[variant], label)
+			}
+		}
+	}
+	for _, labels := range d.skeletons {
+		slices.Sort(labels)
+	}
+
+	return d, nil
+}
+
+func (d *LookalikeDetector) addSite(siteDomain, name string) {
+	siteDomain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(siteDomain)), "www.")
+	// 个别条目带有路径，如xenforo.com/community
+	siteDomain, _, _ = strings.Cut(siteDomain, "/")
+	if !strings.Contains(siteDomain, ".") {
+		return
+	}
+	d.knownDomains[siteDomain] = true
+
+	// 数据库中的子域名（如bugzilla.mozilla.org）的主域名本身也是已知站点，
+	// 但主域名标签可能是cloud这类常见词，不作为仿冒目标
+	label, suffix := domain.SplitHost(siteDomain)
+	zone := label + "." + suffix
+	d.knownDomains[zone] = true
+	if brand := normalizeBrand(name); utf8.RuneCountInString(brand) >= minLookalikeLabelLength {
+		if _, exists := d.brands[brand]; !exists {
+			d.brands[brand] = siteDomain
+		}
+	}
+	if siteDomain != zone {
+		return
+	}
+
+	if !slices.Contains(d.labels[label], siteDomain) {
+		d.labels[label] = append(d.labels[label], siteDomain)
+	}
+	if utf8.RuneCountInString(label) >= minLookalikeLabelLength {
+		skeleton := domain.Skeleton(label)
+		if !slices.Contains(d.skeletons[skeleton], label) {
+			d.skeletons[skeleton] = append(d.skeletons[skeleton], label)
+		}
+	}
+}
+
+func (d *LookalikeDetector) Name() string {
+	return "lookalike"
+}
+
+func (d *LookalikeDetector) Detect(ctx context.Context, creds []types.Credential) ([]types.DetectionResult, error) {
+	var results []types.DetectionResult
+
+	for _, cred := range creds {
+		titleSite := d.titleSite(cred.Title)
+
+		var checked []string
+		for _, rawURL := range credentialURLs(cred) {
+			host := loginHost(rawURL)
+			if host == "" || slices.Contains(checked, host) {
+				continue
+			}
+			checked = append(checked, host)
+
+			unicodeHost := domain.ToUnicode(host)
+			if d.isKnownHost(unicodeHost) {
+				continue
+			}
+			label, suffix := domain.SplitHost(unicodeHost)
+			zone := label + "." + suffix
+
+			match := d.matchLookalike(label, suffix)
+			if match.target != "" && unicodeHost != host {
+				match.techniques = append([]string{lookalikePunycode}, match.techniques...)
+			}
+
+			// 同名换顶级域名（如地区站点）与标题一致，不算标题不符
+			titleLabel, _ := domain.SplitHost(titleSite)
+			mismatch := titleSite != "" && label != titleLabel
+			if match.target == "" && !mismatch {
+				continue
+			}
+
+			severity := match.severity
+			target := match.target
+			if mismatch {
+				match.techniques = append(match.techniques, lookalikeBrandMismatch)
+				if target == "" {
+					target = titleSite
+				}
+				if severityRank[severity] < severityRank[types.SeverityMedium] {
+					severity = types.SeverityMedium
+				}
+				if match.target == titleSite {
+					// 标题和仿冒目标一致，凭据很可能已在钓鱼页面上填写
+					severity = types.SeverityCritical
+				}
+			}
+
+			metadata := map[string]interface{}{
+				"domain":     zone,
+				"target":     target,
+				"techniques": match.techniques,
+			}
+			if unicodeHost != host {
+				asciiLabel, asciiSuffix := domain.SplitHost(host)
+				metadata["punycode"] = asciiLabel + "." + asciiSuffix
+			}
+			if mismatch {
+				metadata["title_site"] = titleSite
+			}
+
+			var message string
+			switch {
+			case match.target == "":
+				message = fmt.Sprintf("Item titled %q points to %s instead of %s", cred.Title, zone, titleSite)
+			case mismatch:
+				message = fmt.Sprintf("Login domain %s looks like %s (%s) and the item is titled %q",
+					zone, match.target, joinWords(match.techniques[:len(match.techniques)-1], "and"), cred.Title)
+			default:
+				message = fmt.Sprintf("Login domain %s looks like %s (%s)", zone, match.target, joinWords(match.techniques, "and"))
+			}
+
+			results = append(results, types.DetectionResult{
+				CredentialID: cred.ID,
+				Title:        cred.Title,
+				Type:         types.DetectionLookalikeDomain,
+				Severity:     severity,
+				Message:      message,
+				Metadata:     metadata,
+			})
+		}
+	}
+
+	return results, nil
+}
+
+func (d *LookalikeDetector) Configure(config map[string]interface{}) error {
+	return nil
+}
+
+// lookalikeMatch 主域名与最接近的已知域名
+type lookalikeMatch struct {
+	target     string
+	techniques []string
+	severity   types.Severity
+}
+
+func (m *lookalikeMatch) add(target, technique string, severity types.Severity) {
+	if m.target == "" {
+		m.target = target
+	}
+	if !slices.Contains(m.techniques, technique) {
+		m.techniques = append(m.techniques, technique)
+	}
+	if severityRank[severity] > severityRank[m.severity] {
+		m.severity = severity
+	}
+}
+
+// matchLookalike 依次按换顶级域名、混淆骨架、连字符和编辑距离查找被仿冒的已知域名
+func (d *LookalikeDetector) matchLookalike(label, suffix string) lookalikeMatch {
+	var match lookalikeMatch
+	length := utf8.RuneCountInString(label)
+	if domains, exists := d.labels[label]; exists {
+		if length < minTypoLabelLength {
+			return match
+		}
+		// 后缀相同的已知域名是同一主域名下的站点，不算换顶级域名
+		var swapped []string
+		for _, known := range domains {
+			if _, knownSuffix := domain.SplitHost(known); knownSuffix != suffix {
+				swapped = append(swapped, known)
+			}
+		}
+		if len(swapped) == 0 {
+			return match
+		}
+		// 品牌常注册各国的国家顶级域名，换成两个字母的后缀风险较低
+		severity := types.SeverityMedium
+		if len(suffix) == 2 {
+			severity = types.SeverityLow
+		}
+		match.add(preferredDomain(swapped), lookalikeTLDSwap, severity)
+		return match
+	}
+	if length < minLookalikeLabelLength {
+		return match
+	}
+
+	// 含非ASCII字符的混淆在浏览器地址栏中几乎无法分辨
+	homoglyphSeverity := types.SeverityHigh
+	if !isASCII(label) {
+		homoglyphSeverity = types.SeverityCritical
+	}
+	if target := d.skeletonMatch(label); target != "" {
+		match.add(target, lookalikeHomoglyph, homoglyphSeverity)
+	}
+
+	if strings.Contains(label, "-") {
+		joined := strings.ReplaceAll(label, "-", "")
+		if domains, exists := d.labels[joined]; exists {
+			match.add(preferredDomain(domains), lookalikeExtraHyphen, types.SeverityHigh)
+		} else if target := d.skeletonMatch(joined); target != "" {
+			match.add(target, lookalikeExtraHyphen, types.SeverityHigh)
+			match.add(target, lookalikeHomoglyph, homoglyphSeverity)
+		}
+		// paypal-secure这类以连字符拼接已知品牌的域名
+		for _, part := range strings.Split(label, "-") {
+			if domains, exists := d.labels[part]; exists && utf8.RuneCountInString(part) >= minBrandLabelLength {
+				match.add(preferredDomain(domains), lookalikeExtraHyphen, types.SeverityHigh)
+			}
+		}
+	}
+
+	if match.target == "" && length >= minTypoLabelLength {
+		if target := d.typoMatch(label); target != "" {
+			match.add(target, lookalikeTypo, types.SeverityMedium)
+		}
+	}
+
+	return match
+}
+
+// typoMatch 返回编辑距离为1或相邻字母互换的已知域名
+// 两个标签满足条件时，各删除至多一个字符后必然相同，因此只需比较删除索引中的候选
+func (d *LookalikeDetector) typoMatch(label string) string {
+	var candidates []string
+	for _, variant := range deletionVariants(label) {
+		for _, candidate := range d.deletions[variant] {
+			if candidate != label && !slices.Contains(candidates, candidate) {
+				candidates = append(candidates, candidate)
+			}
+		}
+	}
+	slices.Sort(candidates)
+
+	runes := []rune(label)
+	for _, candidate := range candidates {
+		other := []rune(candidate)
+		if isTransposition(runes, other) || levenshtein(runes, other) == 1 {
+			return preferredDomain(d.labels[candidate])
+		}
+	}
+	return ""
+}
+
+// deletionVariants 返回标签本身和删除任意一个字符后的所有变体
+func deletionVariants(label string) []string {
+	runes := []rune(label)
+	variants := []string{label}
+	for i := range runes {
+		variant := string(runes[:i]) + string(runes[i+1:])
+		if !slices.Contains(variants, variant) {
+			variants = append(variants, variant)
+		}
+	}
+	return variants
+}
+
+// skeletonMatch 返回混淆骨架相同但字符不同的已知域名
+func (d *LookalikeDetector) skeletonMatch(label string) string {
+	for _, known := range d.skeletons[domain.Skeleton(label)] {
+		if known != label {
+			return preferredDomain(d.labels[known])
+		}
+	}
+	return ""
+}
+
+// titleSite 标题（去掉login、account等通用词后）是已知站点名称或标签时返回该站点的域名
+func (d *LookalikeDetector) titleSite(title string) string {
+	var words []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(title), isSymbol) {
+		if !genericTitleWords[word] {
+			words = append(words, word)
+		}
+	}
+	brand := strings.Join(words, "")
+	if utf8.RuneCountInString(brand) < minLookalikeLabelLength {
+		return ""
+	}
+	return d.brands[brand]
+}
+
+// isKnownHost 主机名是已知域名或其子域名
+func (d *LookalikeDetector) isKnownHost(host string) bool {
+	for {
+		if d.knownDomains[host] {
+			return true
+		}
+		_, parent, found := strings.Cut(host, ".")
+		if !found {
+			return false
+		}
+		host = parent
+	}
+}
+
+// loginHost 返回http(s)或无协议地址的小写主机名，IP地址和单标签主机名返回空
+func loginHost(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return ""
+	}
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	if scheme := strings.ToLower(u.Scheme); scheme != "http" && scheme != "https" {
+		return ""
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if net.ParseIP(host) != nil || !strings.Contains(host, ".") {
+		return ""
+	}
+	return host
+}
+
+// preferredDomain 同名标签有多个已知域名时优先.com
+func preferredDomain(domains []string) string {
+	for _, candidate := range domains {
+		if strings.HasSuffix(candidate, ".com") {
+			return candidate
+		}
+	}
+	return domains[0]
+}
+
+// normalizeBrand 站点名称小写并去掉空格和符号
+func normalizeBrand(name string) string {
+	return strings.Map(func(r rune) rune {
+		if isSymbol(r) {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, name)
+}
+
+// isTransposition 两个等长字符串只有一对相邻字符互换
+func isTransposition(a, b []rune) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := 0; i+1 < len(a); i++ {
+		if a[i] != b[i] {
+			return a[i] == b[i+1] && a[i+1] == b[i] && slices.Equal(a[i+2:], b[i+2:])
+		}
+	}
+	return false
+}
+
+func isASCII(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if value[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,146 @@
+package detector
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/yourorg/unpass/internal/database"
+	"github.com/yourorg/unpass/internal/types"
+)
+
+func newTestLookalikeDetector(t *testing.T) *LookalikeDetector {
+	t.Helper()
+	twofaDB := `{"sites": [
+		{"domain": "paypal.com", "supports_2fa": true},
+		{"domain": "github.com", "supports_2fa": true},
+		{"domain": "netflix.com", "supports_2fa": false},
+		{"domain": "zalando.com", "supports_2fa": true},
+		{"domain": "bugzilla.mozilla.org", "supports_2fa": true},
+		{"domain": "m365.cloud.microsoft", "supports_2fa": true},
+		{"domain": "clever.com", "supports_2fa": true}
+	]}`
+	passkeyDB := `[{"name": "Microsoft", "domain": "microsoft.com", "approved": true, "passkey_signin": true}]`
+
+	detector, err := NewLookalikeDetector(database.NewDatabaseLoader(writeTestDatabases(t, twofaDB, passkeyDB)))
+	if err != nil {
+		t.Fatalf("NewLookalikeDetector failed: %v", err)
+	}
+	return detector
+}
+
+func TestLookalikeDetector(t *testing.T) {
+	detector := newTestLookalikeDetector(t)
+	creds := []types.Credential{
+		{ID: "1", Title: "Payments", URL: "https://www.paypa1.com/signin"},
+		{ID: "2", Title: "Payments", URL: "https://xn--pypal-4ve.com"},
+		{ID: "3", Title: "Code", URL: "https://git-hub.com/login"},
+		{ID: "4", Title: "Movies", URL: "https://netflx.com"},
+		{ID: "5", Title: "Shop", URL: "https://zalando.de"},
+		{ID: "6", Title: "Microsoft Account", URL: "https://login.example.net"},
+		{ID: "7", Title: "PayPal", URLs: []string{"https://rnicrosoft.com", "https://paypal.com.secure-login.example"}},
+		{ID: "8", Title: "PayPal", URL: "https://www.paypal.com/signin"},
+		{ID: "9", Title: "GitHub", URL: "https://gist.github.com", URLs: []string{"androidapp://com.github.android", "https://192.0.2.1"}},
+	}
+
+	results, err := detector.Detect(context.Background(), creds)
+	if err != nil {
+		t.Fatalf("Detect failed: %v", err)
+	}
+
+	tests := []struct {
+		id         string
+		domain     string
+		target     string
+		techniques []string
+		severity   types.Severity
+	}{
+		{"1", "paypa1.com", "paypal.com", []string{"homoglyph"}, types.SeverityHigh},
+		{"2", "pаypal.com", "paypal.com", []string{"punycode", "homoglyph"}, types.SeverityCritical},
+		{"3", "git-hub.com", "github.com", []string{"extra_hyphen"}, types.SeverityHigh},
+		{"4", "netflx.com", "netflix.com", []string{"typo"}, types.SeverityMedium},
+		{"5", "zalando.de", "zalando.com", []string{"tld_swap"}, types.SeverityLow},
+		{"6", "example.net", "microsoft.com", []string{"brand_mismatch"}, types.SeverityMedium},
+		{"7", "rnicrosoft.com", "microsoft.com", []string{"homoglyph", "brand_mismatch"}, types.SeverityHigh},
+		{"7", "secure-login.example", "paypal.com", []string{"brand_mismatch"}, types.SeverityMedium},
+	}
+	if len(results) != len(tests) {
+		t.Fatalf("Expected %d results, got %d: %+v", len(tests), len(results), results)
+	}
+	for i, test := range tests {
+		result := results[i]
+		if result.CredentialID != test.id || result.Type != types.DetectionLookalikeDomain || result.Severity != test.severity {
+			t.Errorf("Unexpected result for %s: %+v", test.id, result)
+			continue
+		}
+		if result.Metadata["domain"] != test.domain || result.Metadata["target"] != test.target {
+			t.Errorf("%s: expected %s imitating %s, got %v", test.id, test.domain, test.target, result.Metadata)
+		}
+		if techniques := result.Metadata["techniques"].([]string); !slices.Equal(techniques, test.techniques) {
+			t.Errorf("%s: expected techniques %v, got %v", test.id, test.techniques, techniques)
+		}
+	}
+
+	if results[1].Metadata["punycode"] != "xn--pypal-4ve.com" {
+		t.Errorf("Expected the punycode form to be reported, got %v", results[1].Metadata)
+	}
+	if results[0].Message != "Login domain paypa1.com looks like paypal.com (homoglyph)" {
+		t.Errorf("Unexpected message: %s", results[0].Message)
+	}
+}
+
+func TestLookalikeDetectorTitleMatchesTarget(t *testing.T) {
+	detector := newTestLookalikeDetector(t)
+	creds := []types.Credential{{ID: "1", Title: "PayPal", URL: "https://paypa1.com/login"}}
+
+	results, err := detector.Detect(context.Background(), creds)
+	if err != nil {
+		t.Fatalf("Detect failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Severity != types.SeverityCritical {
+		t.Fatalf("Expected a critical result when the title names the imitated site, got %+v", results)
+	}
+	if results[0].Message != `Login domain paypa1.com looks like paypal.com (homoglyph) and the item is titled "PayPal"` {
+		t.Errorf("Unexpected message: %s", results[0].Message)
+	}
+}
+
+// 数据库只收录了子域名时，主域名本身不是仿冒，其主域名标签也不作为仿冒目标
+func TestLookalikeDetectorSubdomainEntry(t *testing.T) {
+	detector := newTestLookalikeDetector(t)
+	creds := []types.Credential{
+		{ID: "1", Title: "Mozilla", URL: "https://mozilla.org"},
+		{ID: "2", Title: "Mozilla", URL: "https://accounts.mozilla.org/login"},
+		{ID: "3", Title: "Mozilla", URL: "https://mozilla.net"},
+		{ID: "4", Title: "Cloud", URL: "https://cloud.com"},
+	}
+
+	results, err := detector.Detect(context.Background(), creds)
+	if err != nil {
+		t.Fatalf("Detect failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Fatalf("Expected no results, got %+v", results)
+	}
+}
+
+// 短标签多为常见词，不做编辑距离和换顶级域名比较
+func TestLookalikeDetectorShortLabels(t *testing.T) {
+	detector := newTestLookalikeDetector(t)
+	creds := []types.Credential{
+		{ID: "1", Title: "Jobs", URL: "https://jobs.lever.co"},
+		{ID: "2", Title: "School", URL: "https://clever.net"},
+		{ID: "3", Title: "School", URL: "https://cleverr.com"},
+	}
+
+	results, err := detector.Detect(context.Background(), creds)
+	if err != nil {
+		t.Fatalf("Detect failed: %v", err)
+	}
+	if len(results) != 2 || results[0].CredentialID != "2" || results[1].CredentialID != "3" {
+		t.Fatalf("Expected only the clever lookalikes to be reported, got %+v", results)
+	}
+	if techniques := results[1].Metadata["techniques"].([]string); !slices.Equal(techniques, []string{"typo"}) {
+		t.Errorf("Expected typo, got %v", techniques)
+	}
+}
@@ -0,0 +1,99 @@
+package detector
+
+import (
+	"fmt"
+	"slices"
+)
+
+// 第二因素方法，与2FA数据库的methods取值一致；passkey来自Passkey数据库
+const (
+	methodSecurityKey    = "u2f"
+	methodCustomHardware = "custom-hardware" // 厂商硬件令牌
+	methodPasskey        = "passkey"
+	methodTOTP           = "totp"
+	methodPush           = "custom-software" // 厂商App推送或专有验证器
+	methodSMS            = "sms"
+	methodEmail          = "email"
+	methodCall           = "call"
+)
+
+// 方法的强度等级，越大越难被钓鱼或劫持
+const (
+	methodRankUnknown           = iota
+	methodRankInterceptable     // 短信、电话可被SIM卡劫持，邮件随邮箱一起被接管
+	methodRankPush              // 推送可被疲劳轰炸骗取批准
+	methodRankTOTP              // 验证码仍可被实时钓鱼转发，厂商硬件令牌和读卡器生成的码同样如此
+	methodRankPhishingResistant // 安全密钥和Passkey绑定站点来源，无法被钓鱼页面使用
+)
+
+var methodRanks = map[string]int{
+	methodSecurityKey:    methodRankPhishingResistant,
+	methodCustomHardware: methodRankTOTP,
+	methodPasskey:        methodRankPhishingResistant,
+	methodTOTP:           methodRankTOTP,
+	methodPush:           methodRankPush,
+	methodSMS:            methodRankInterceptable,
+	methodEmail:          methodRankInterceptable,
+	methodCall:           methodRankInterceptable,
+}
+
+// methodNames 推荐文本中各方法的说法
+var methodNames = map[string]string{
+	methodSecurityKey:    "a hardware security key",
+	methodCustomHardware: "the site's hardware token",
+	methodPasskey:        "a passkey",
+	methodTOTP:           "an authenticator app (TOTP)",
+	methodPush:           "the site's authenticator app",
+	methodSMS:            "SMS codes",
+	methodEmail:          "email codes",
+	methodCall:           "phone call verification",
+}
+
+// rankMethods 去重并按强度从高到低排列站点提供的方法，同等级保持数据库中的顺序；未知方法排在最后
+func rankMethods(methods []string, passkey bool) []string {
+	var ranked []string
+	if passkey {
+		ranked = append(ranked, methodPasskey)
+	}
+	for _, method := range methods {
+		if method != "" && !slices.Contains(ranked, method) {
+			ranked = append(ranked, method)
+		}
+	}
+	slices.SortStableFunc(ranked, func(a, b string) int {
+		return methodRanks[b] - methodRanks[a]
+	})
+	return ranked
+}
+
+// onlyInterceptable 站点提供的方法全部可被SIM卡劫持或随邮箱被接管
+func onlyInterceptable(ranked []string) bool {
+	return len(ranked) > 0 && methodRanks[ranked[0]] == methodRankInterceptable
+}
+
+// methodRecommendation 按最强的可用方法给出建议；站点同时支持Passkey和硬件密钥时一并说明
+func methodRecommendation(ranked []string) string {
+	if len(ranked) == 0 {
+		return ""
+	}
+	best := ranked[0]
+	switch methodRanks[best] {
+	case methodRankPhishingResistant:
+		var options []string
+		for _, method := range ranked {
+			if methodRanks[method] == methodRankPhishingResistant {
+				options = append(options, methodNames[method])
+			}
+		}
+		if len(options) > 1 {
+			return fmt.Sprintf("Use %s; they resist phishing", joinWords(options, "or"))
+		}
+		return fmt.Sprintf("Use %s; it resists phishing", options[0])
+	case methodRankInterceptable:
+		return fmt.Sprintf("Enable %s, the strongest option this site offers, and protect the phone number or mailbox it relies on", methodNames[best])
+	case methodRankUnknown:
+		return fmt.Sprintf("Enable %s", best)
+	default:
+		return fmt.Sprintf("Enable %s", methodNames[best])
+	}
+}
@@ -0,0 +1,100 @@
+package detector
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/yourorg/unpass/internal/database"
+	"github.com/yourorg/unpass/internal/types"
+)
+
+func TestRankMethods(t *testing.T) {
+	ranked := rankMethods([]string{"sms", "totp", "", "custom-software", "u2f", "email", "sms"}, true)
+	expected := []string{"passkey", "u2f", "totp", "custom-software", "sms", "email"}
+	if !slices.Equal(ranked, expected) {
+		t.Errorf("Expected %v, got %v", expected, ranked)
+	}
+
+	if onlyInterceptable(rankMethods([]string{"sms", "totp"}, false)) {
+		t.Error("A site offering TOTP should not be reported as SMS-only")
+	}
+	if !onlyInterceptable(rankMethods([]string{"email", "call"}, false)) {
+		t.Error("Expected email and call to be reported as interceptable only")
+	}
+	if onlyInterceptable(nil) {
+		t.Error("A site without known methods should not be reported")
+	}
+
+	// 厂商硬件令牌生成的码可被转发，与TOTP同级，不能说成抗钓鱼
+	hardware := rankMethods([]string{"sms", "custom-hardware"}, false)
+	if !slices.Equal(hardware, []string{"custom-hardware", "sms"}) || onlyInterceptable(hardware) {
+		t.Errorf("Unexpected ranking for a hardware token site: %v", hardware)
+	}
+	if recommendation := methodRecommendation(hardware); recommendation != "Enable the site's hardware token" {
+		t.Errorf("Unexpected recommendation for a hardware token site: %s", recommendation)
+	}
+
+	recommendation := methodRecommendation(ranked)
+	if recommendation != "Use a passkey or a hardware security key; they resist phishing" {
+		t.Errorf("Unexpected recommendation: %s", recommendation)
+	}
+}
+
+func TestTwoFADetectorMethods(t *testing.T) {
+	twofaDB := `{"sites": [
+		{"domain": "github.com", "supports_2fa": true, "methods": ["sms", "totp", "u2f"], "documentation_url": "https://docs.github.com/2fa"},
+		{"domain": "bank.example", "supports_2fa": true, "methods": ["email", "sms"], "documentation_url": "https://bank.example/2fa"},
+		{"domain": "shop.example", "supports_2fa": true, "methods": ["email"]}
+	]}`
+	passkeyDB := `[{"name": "GitHub", "domain": "github.com", "approved": true, "passkey_signin": true, "setup_link": "https://github.com/settings/security"}]`
+	detector, err := NewTwoFADetector(database.NewDatabaseLoader(writeTestDatabases(t, twofaDB, passkeyDB)))
+	if err != nil {
+		t.Fatalf("NewTwoFADetector failed: %v", err)
+	}
+
+	creds := []types.Credential{
+		{ID: "1", Title: "GitHub", URL: "https://github.com"},
+		{ID: "2", Title: "Bank", URL: "https://bank.example"},
+		{ID: "3", Title: "Shop", URL: "https://shop.example"},
+	}
+	results, err := detector.Detect(context.Background(), creds)
+	if err != nil {
+		t.Fatalf("Detect failed: %v", err)
+	}
+	if len(results) != 5 {
+		t.Fatalf("Expected 5 results, got %d: %+v", len(results), results)
+	}
+
+	github := results[0]
+	if github.Metadata["recommended_method"] != "passkey" || github.Metadata["setup_link"] != "https://github.com/settings/security" ||
+		github.Metadata["documentation_url"] != "https://docs.github.com/2fa" {
+		t.Errorf("Expected a passkey recommendation next to the 2FA documentation, got %v", github.Metadata)
+	}
+	if github.Metadata["recommendation"] != "Use a passkey or a hardware security key; they resist phishing" {
+		t.Errorf("Unexpected recommendation: %v", github.Metadata["recommendation"])
+	}
+
+	tests := []struct {
+		index    int
+		id       string
+		severity types.Severity
+		methods  []string
+	}{
+		{2, "2", types.SeverityMedium, []string{"email", "sms"}},
+		{4, "3", types.SeverityLow, []string{"email"}},
+	}
+	for _, test := range tests {
+		result := results[test.index]
+		if result.CredentialID != test.id || result.Type != types.DetectionWeakSecondFactor || result.Severity != test.severity {
+			t.Errorf("Unexpected result for %s: %+v", test.id, result)
+			continue
+		}
+		if methods := result.Metadata["supported_methods"].([]string); !slices.Equal(methods, test.methods) {
+			t.Errorf("%s: expected methods %v, got %v", test.id, test.methods, methods)
+		}
+	}
+	if results[1].Type != types.DetectionMissing2FA || results[1].Metadata["recommended_method"] != "email" {
+		t.Errorf("Expected the missing 2FA finding to recommend email codes, got %+v", results[1])
+	}
+}
@@ -0,0 +1,134 @@
+package detector
+
+import (
+	"context"
+	"crypto/sha1"
+	"fmt"
+
+	"github.com/yourorg/unpass/internal/database"
+	"github.com/yourorg/unpass/internal/domain"
+	"github.com/yourorg/unpass/internal/types"
+)
+
+// PwnedPasswordDetector 离线检测出现在已知数据泄露中的密码
+// 编译后的完整库通过内存映射查询，用完需要Close
+type PwnedPasswordDetector struct {
+	passwords     database.PwnedPasswordLookup
+	domainMatcher *domain.DomainMatcher
+}
+
+func NewPwnedPasswordDetector(dbLoader *database.DatabaseLoader) (*PwnedPasswordDetector, error) {
+	passwords, err := dbLoader.LoadPwnedPasswords()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPwnedPasswordDetectorWithLookup(passwords), nil
+}
+
+// NewPwnedPasswordDetectorWithLookup 使用指定的查询源，如在线范围查询客户端
+func NewPwnedPasswordDetectorWithLookup(passwords database.PwnedPasswordLookup) *PwnedPasswordDetector {
+	return &PwnedPasswordDetector{
+		passwords:     passwords,
+		domainMatcher: domain.NewDomainMatcher(nil, nil),
+	}
+}
+
+func (d *PwnedPasswordDetector) Name() string {
+	return "pwned"
+}
+
+func (d *PwnedPasswordDetector) Detect(ctx context.Context, creds []types.Credential) ([]types.DetectionResult, error) {
+	var results []types.DetectionResult
+
+	hashes := make([][sha1.Size]byte, len(creds))
+	for i, cred := range creds {
+		if cred.Password != "" {
+			hashes[i] = sha1.Sum([]byte(cred.Password))
+		}
+	}
+	lookup, err := d.lookup(ctx, creds, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, cred := range creds {
+		if cred.Password == "" {
+			continue
+		}
+
+		// 报告中不包含密码和任何哈希片段：哈希前缀加上精确的泄露次数，就能在公开的范围查询结果中找出完整哈希
+		hash := hashes[i]
+		count, exists := lookup(hash)
+		if !exists || count <= 0 {
+			continue
+		}
+
+		metadata := map[string]interface{}{
+			"breach_count": count,
+		}
+		if lastUpdated := d.passwords.LastUpdated(); lastUpdated != "" {
+			metadata["database_updated"] = lastUpdated
+		}
+		if hostedZone := d.domainMatcher.ExtractHostedZone(cred.URL); hostedZone != "" {
+			metadata["domain"] = hostedZone
+		}
+
+		results = append(results, types.DetectionResult{
+			CredentialID: cred.ID,
+			Title:        cred.Title,
+			Type:         types.DetectionPwnedPassword,
+			Severity:     pwnedSeverity(count),
+			Message:      fmt.Sprintf("Password has appeared %d times in known data breaches", count),
+			Metadata:     metadata,
+		})
+	}
+
+	return results, nil
+}
+
+// lookup 在线查询源整批并发查询，以便返回网络错误；本地库逐条查询
+func (d *PwnedPasswordDetector) lookup(ctx context.Context, creds []types.Credential, hashes [][sha1.Size]byte) (func([sha1.Size]byte) (int, bool), error) {
+	batch, ok := d.passwords.(database.PwnedPasswordBatchLookup)
+	if !ok {
+		return d.passwords.Lookup, nil
+	}
+
+	var pending [][sha1.Size]byte
+	for i, cred := range creds {
+		if cred.Password != "" {
+			pending = append(pending, hashes[i])
+		}
+	}
+	counts, err := batch.LookupAll(ctx, pending)
+	if err != nil {
+		return nil, fmt.Errorf("pwned password lookup failed: %w", err)
+	}
+	return func(hash [sha1.Size]byte) (int, bool) {
+		count, exists := counts[hash]
+		return count, exists
+	}, nil
+}
+
+func (d *PwnedPasswordDetector) Configure(config map[string]interface{}) error {
+	return nil
+}
+
+// Close 释放泄露密码库（解除内存映射）
+func (d *PwnedPasswordDetector) Close() error {
+	return d.passwords.Close()
+}
+
+// pwnedSeverity 泄露次数越多，密码越可能出现在撞库字典的前列
+func pwnedSeverity(count int) types.Severity {
+	switch {
+	case count >= 100000:
+		return types.SeverityCritical
+	case count >= 1000:
+		return types.SeverityHigh
+	case count >= 10:
+		return types.SeverityMedium
+	default:
+		return types.SeverityLow
+	}
+}
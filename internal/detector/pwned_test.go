@@ -0,0 +1,70 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourorg/unpass/internal/database"
+	"github.com/yourorg/unpass/internal/types"
+)
+
+func TestPwnedPasswordDetector_Detect(t *testing.T) {
+	// "password"和"123456"的SHA-1，大写哈希应与小写等价
+	dir := t.TempDir()
+	db := `{
+		"last_updated": "2025-07-08",
+		"passwords": {
+			"5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8": 21690062,
+			"7c4a8d09ca3762af61e59520943dc26494f8941b": 42
+		}
+	}`
+	if err := os.WriteFile(filepath.Join(dir, "pwned_passwords_database.json"), []byte(db), 0600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	detector, err := NewPwnedPasswordDetector(database.NewDatabaseLoader(dir))
+	if err != nil {
+		t.Fatalf("NewPwnedPasswordDetector failed: %v", err)
+	}
+
+	creds := []types.Credential{
+		{ID: "1", Title: "GitHub", URL: "https://github.com/login", Password: "password"},
+		{ID: "2", Title: "Router", Password: "123456"},
+		{ID: "3", Title: "Bank", Password: "k8#Vq!2mZr"},
+		{ID: "4", Title: "Empty"},
+	}
+	results, err := detector.Detect(context.Background(), creds)
+	if err != nil {
+		t.Fatalf("Detect failed: %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	if results[0].CredentialID != "1" || results[0].Type != types.DetectionPwnedPassword || results[0].Severity != types.SeverityCritical {
+		t.Errorf("Unexpected result: %+v", results[0])
+	}
+	if results[0].Metadata["breach_count"] != 21690062 || results[0].Metadata["domain"] != "github.com" {
+		t.Errorf("Unexpected metadata: %v", results[0].Metadata)
+	}
+	if results[1].Severity != types.SeverityMedium {
+		t.Errorf("Expected medium severity for 42 breaches, got %s", results[1].Severity)
+	}
+
+	// 报告中不能出现密码或完整哈希
+	for _, result := range results {
+		text := fmt.Sprintf("%s %v", result.Message, result.Metadata)
+		for _, secret := range []string{"123456", "5baa61e4c9b93f3f0682250b6cf8331b7ee68fd8", "7c4a8d09ca3762af61e59520943dc26494f8941b"} {
+			if strings.Contains(strings.ToLower(text), secret) {
+				t.Errorf("Result leaks %s: %s", secret, text)
+			}
+		}
+	}
+	if _, exists := results[0].Metadata["hash_prefix"]; exists {
+		t.Error("Hash prefix together with the breach count identifies the full hash")
+	}
+}
@@ -0,0 +1,249 @@
+package detector
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/yourorg/unpass/internal/database"
+	"github.com/yourorg/unpass/internal/domain"
+	"github.com/yourorg/unpass/internal/types"
+)
+
+// emailProviders 常见邮箱服务的主域名；邮箱通常是其他账户的找回渠道，复用其密码风险更高
+var emailProviders = map[string]bool{
+	"gmail.com": true, "google.com": true, "googlemail.com": true,
+	"outlook.com": true, "hotmail.com": true, "live.com": true, "msn.com": true,
+	"yahoo.com": true, "ymail.com": true, "aol.com": true,
+	"icloud.com": true, "me.com": true, "mac.com": true,
+	"proton.me": true, "protonmail.com": true, "tutanota.com": true, "tuta.io": true,
+	"fastmail.com": true, "zoho.com": true, "hey.com": true, "mail.com": true,
+	"gmx.com": true, "gmx.net": true, "gmx.de": true, "web.de": true,
+	"yandex.com": true, "yandex.ru": true, "mail.ru": true,
+	"qq.com": true, "163.com": true, "126.com": true,
+}
+
+// reuseEntry 复用检测记录的凭据信息，不保存密码
+type reuseEntry struct {
+	id          string
+	title       string
+	zones       []string
+	sources     []types.CredentialSource
+	category    string
+	missing2FA  bool // 站点支持2FA但凭据没有配置可用的TOTP
+	emailDomain bool
+}
+
+// PasswordReuseDetector 检测在不同站点间复用的密码
+// 密码以本次运行随机密钥的HMAC分组，跨批次只保留摘要和凭据ID，所有批次结束后由Finish产生结果
+type PasswordReuseDetector struct {
+	key           []byte
+	groups        map[[sha256.Size]byte][]reuseEntry
+	order         [][sha256.Size]byte // 按首次出现的顺序输出
+	twofaDomains  map[string]bool
+	domainMatcher *domain.DomainMatcher
+}
+
+func NewPasswordReuseDetector(dbLoader *database.DatabaseLoader) (*PasswordReuseDetector, error) {
+	key := make([]byte, sha256.Size)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate reuse hash key: %w", err)
+	}
+
+	// 2FA数据库用于提高严重程度，不存在时忽略
+	twofaDB, _ := dbLoader.LoadTwoFADatabase()
+	passkeyDB, _ := dbLoader.LoadPasskeyDatabase()
+
+	twofaDomains := make(map[string]bool)
+	if twofaDB != nil {
+		for _, site := range twofaDB.Sites {
+			if site.Supports2FA {
+				twofaDomains[strings.ToLower(site.Domain)] = true
+			}
+		}
+	}
+
+	return &PasswordReuseDetector{
+		key:           key,
+		groups:        make(map[[sha256.Size]byte][]reuseEntry),
+		twofaDomains:  twofaDomains,
+		domainMatcher: domain.NewDomainMatcher(twofaDB, passkeyDB),
+	}, nil
+}
+
+func (d *PasswordReuseDetector) Name() string {
+	return "reuse"
+}
+
+// Detect 记录本批凭据的密码摘要，复用只能在看到所有凭据后判断，结果由Finish返回
+func (d *PasswordReuseDetector) Detect(ctx context.Context, creds []types.Credential) ([]types.DetectionResult, error) {
+	for _, cred := range creds {
+		if cred.Password == "" {
+			continue
+		}
+
+		mac := hmac.New(sha256.New, d.key)
+		mac.Write([]byte(cred.Password))
+		var digest [sha256.Size]byte
+		mac.Sum(digest[:0])
+
+		entry := reuseEntry{
+			id:       cred.ID,
+			title:    cred.Title,
+			sources:  cred.Sources,
+			category: cred.Category,
+		}
+		for _, url := range credentialURLs(cred) {
+			zone := d.domainMatcher.ExtractHostedZone(url)
+			if zone == "" || slices.Contains(entry.zones, zone) {
+				continue
+			}
+			entry.zones = append(entry.zones, zone)
+			if isEmailProvider(zone) {
+				entry.emailDomain = true
+			}
+			if d.twofaDomains[zone] && !totpConfigured(cred.TOTP) {
+				entry.missing2FA = true
+			}
+		}
+
+		if _, exists := d.groups[digest]; !exists {
+			d.order = append(d.order, digest)
+		}
+		d.groups[digest] = append(d.groups[digest], entry)
+	}
+
+	return nil, nil
+}
+
+// Finish 对每个复用组中与其他站点共用密码的凭据各产生一个结果
+func (d *PasswordReuseDetector) Finish(ctx context.Context) ([]types.DetectionResult, error) {
+	var results []types.DetectionResult
+
+	for _, digest := range d.order {
+		group := d.groups[digest]
+		if len(group) < 2 {
+			continue
+		}
+
+		var domains []string
+		emailProvider, missing2FA := false, false
+		for _, entry := range group {
+			for _, zone := range entry.zones {
+				if !slices.Contains(domains, zone) {
+					domains = append(domains, zone)
+				}
+			}
+			emailProvider = emailProvider || entry.emailDomain
+			missing2FA = missing2FA || entry.missing2FA
+		}
+		slices.Sort(domains)
+
+		for i, entry := range group {
+			// 同一站点的多个账户共用密码不算跨站复用
+			elsewhere := reusedElsewhere(group, i)
+			if len(elsewhere) == 0 {
+				continue
+			}
+
+			metadata := map[string]interface{}{
+				"reused_with":    elsewhere,
+				"reuse_count":    len(elsewhere),
+				"domains":        domains,
+				"email_provider": emailProvider,
+			}
+			if len(entry.zones) > 0 {
+				metadata["domain"] = entry.zones[0]
+			}
+			if missing2FA {
+				metadata["missing_2fa"] = true
+			}
+
+			results = append(results, types.DetectionResult{
+				CredentialID: entry.id,
+				Title:        entry.title,
+				Type:         types.DetectionPasswordReuse,
+				Severity:     reuseSeverity(emailProvider, missing2FA),
+				Message:      fmt.Sprintf("Password is shared with %d other credentials on different sites", len(elsewhere)),
+				Metadata:     metadata,
+				Sources:      entry.sources,
+				Category:     entry.category,
+			})
+		}
+	}
+
+	// 结果已产生，释放摘要
+	clear(d.groups)
+	d.order = nil
+	return results, nil
+}
+
+func (d *PasswordReuseDetector) Configure(config map[string]interface{}) error {
+	return nil
+}
+
+// reusedElsewhere 组内与第i个凭据没有共同站点的凭据ID；没有URL的凭据视为独立站点
+func reusedElsewhere(group []reuseEntry, i int) []string {
+	var others []string
+	for j, other := range group {
+		if j == i {
+			continue
+		}
+		if len(group[i].zones) == 0 || len(other.zones) == 0 {
+			others = append(others, other.id)
+			continue
+		}
+		shared := false
+		for _, zone := range other.zones {
+			if slices.Contains(group[i].zones, zone) {
+				shared = true
+				break
+			}
+		}
+		if !shared {
+			others = append(others, other.id)
+		}
+	}
+	return others
+}
+
+// reuseSeverity 复用组中有邮箱服务或未启用2FA的站点时，每项各提高一级
+func reuseSeverity(emailProvider, missing2FA bool) types.Severity {
+	switch {
+	case emailProvider && missing2FA:
+		return types.SeverityCritical
+	case emailProvider || missing2FA:
+		return types.SeverityHigh
+	default:
+		return types.SeverityMedium
+	}
+}
+
+// isEmailProvider 主域名或其上级域名是否为邮箱服务
+func isEmailProvider(zone string) bool {
+	for {
+		if emailProviders[zone] {
+			return true
+		}
+		_, parent, found := strings.Cut(zone, ".")
+		if !found || !strings.Contains(parent, ".") {
+			return false
+		}
+		zone = parent
+	}
+}
+
+// credentialURLs 优先使用URLs字段，回退到主URL
+func credentialURLs(cred types.Credential) []string {
+	if len(cred.URLs) > 0 {
+		return cred.URLs
+	}
+	if cred.URL != "" {
+		return []string{cred.URL}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package detector
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/yourorg/unpass/internal/database"
+	"github.com/yourorg/unpass/internal/types"
+)
+
+func newTestReuseDetector(t *testing.T) *PasswordReuseDetector {
+	t.Helper()
+	twofaDB := `{"sites": [{"domain": "github.com", "supports_2fa": true, "methods": ["totp"]}]}`
+
+	detector, err := NewPasswordReuseDetector(database.NewDatabaseLoader(writeTestDatabases(t, twofaDB, "")))
+	if err != nil {
+		t.Fatalf("NewPasswordReuseDetector failed: %v", err)
+	}
+	return detector
+}
+
+// detectAll 分批交给检测器后取得Finish的结果
+func detectAll(t *testing.T, detector *PasswordReuseDetector, batches ...[]types.Credential) []types.DetectionResult {
+	t.Helper()
+	for _, batch := range batches {
+		results, err := detector.Detect(context.Background(), batch)
+		if err != nil || len(results) != 0 {
+			t.Fatalf("Expected Detect to only accumulate, got %v, %v", results, err)
+		}
+	}
+	results, err := detector.Finish(context.Background())
+	if err != nil {
+		t.Fatalf("Finish failed: %v", err)
+	}
+	return results
+}
+
+func TestPasswordReuseDetector(t *testing.T) {
+	detector := newTestReuseDetector(t)
+	results := detectAll(t, detector,
+		[]types.Credential{
+			{ID: "1", Title: "Forum", URL: "https://forum.example.org", Password: "shared"},
+			{ID: "2", Title: "Shop", URLs: []string{"https://shop.example.net", "https://www.shop.example.net/login"}, Password: "shared"},
+		},
+		[]types.Credential{
+			// 同一站点的多个账户不算跨站复用
+			{ID: "3", Title: "GitHub", URL: "https://github.com", Password: "same-site", TOTP: "otpauth://totp/x"},
+			{ID: "4", Title: "GitHub bot", URL: "https://www.github.com/login", Password: "same-site"},
+			{ID: "5", Title: "Unique", URL: "https://example.com", Password: "unique"},
+		},
+	)
+
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d: %+v", len(results), results)
+	}
+	for _, result := range results {
+		if result.Type != types.DetectionPasswordReuse || result.Severity != types.SeverityMedium {
+			t.Errorf("Unexpected result: %+v", result)
+		}
+	}
+	if others := results[0].Metadata["reused_with"].([]string); !slices.Equal(others, []string{"2"}) {
+		t.Errorf("Expected credential 1 to be reused with 2, got %v", others)
+	}
+	if domains := results[1].Metadata["domains"].([]string); !slices.Equal(domains, []string{"example.net", "example.org"}) {
+		t.Errorf("Unexpected reuse domains: %v", domains)
+	}
+
+	// Finish后状态清空
+	if results, _ := detector.Finish(context.Background()); len(results) != 0 {
+		t.Errorf("Expected no results after Finish, got %d", len(results))
+	}
+}
+
+// 消息中只统计其他站点的凭据，同一站点的账户不计入
+func TestPasswordReuseDetector_MixedSites(t *testing.T) {
+	results := detectAll(t, newTestReuseDetector(t), []types.Credential{
+		{ID: "1", Title: "GitHub", URL: "https://github.com", Password: "pw"},
+		{ID: "2", Title: "GitHub bot", URL: "https://github.com/login", Password: "pw"},
+		{ID: "3", Title: "Forum", URL: "https://forum.example.org", Password: "pw"},
+	})
+
+	expected := []string{
+		"Password is shared with 1 other credentials on different sites",
+		"Password is shared with 1 other credentials on different sites",
+		"Password is shared with 2 other credentials on different sites",
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d results, got %d: %+v", len(expected), len(results), results)
+	}
+	// 元数据与消息统计的是同一组凭据
+	reusedWith := [][]string{{"3"}, {"3"}, {"1", "2"}}
+	for i, result := range results {
+		if result.Message != expected[i] {
+			t.Errorf("%s: unexpected message: %s", result.CredentialID, result.Message)
+		}
+		if others := result.Metadata["reused_with"].([]string); !slices.Equal(others, reusedWith[i]) {
+			t.Errorf("%s: expected reused with %v, got %v", result.CredentialID, reusedWith[i], others)
+		}
+		if count := result.Metadata["reuse_count"]; count != len(reusedWith[i]) {
+			t.Errorf("%s: expected reuse count %d, got %v", result.CredentialID, len(reusedWith[i]), count)
+		}
+	}
+}
+
+func TestPasswordReuseDetector_Severity(t *testing.T) {
+	testCases := []struct {
+		name     string
+		creds    []types.Credential
+		expected types.Severity
+	}{
+		{
+			name: "email provider",
+			creds: []types.Credential{
+				{ID: "1", URL: "https://mail.google.com", Password: "pw"},
+				{ID: "2", URL: "https://example.org", Password: "pw"},
+			},
+			expected: types.SeverityHigh,
+		},
+		{
+			name: "2fa site with totp",
+			creds: []types.Credential{
+				{ID: "1", URL: "https://github.com", Password: "pw", TOTP: "JBSWY3DPEHPK3PXP"},
+				{ID: "2", URL: "https://example.org", Password: "pw"},
+			},
+			expected: types.SeverityMedium,
+		},
+		{
+			name: "email provider and 2fa site without totp",
+			creds: []types.Credential{
+				{ID: "1", URL: "https://github.com", Password: "pw"},
+				{ID: "2", URL: "https://outlook.com", Password: "pw"},
+				{ID: "3", Title: "No URL", Password: "pw"},
+			},
+			expected: types.SeverityCritical,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			results := detectAll(t, newTestReuseDetector(t), tc.creds)
+			if len(results) != len(tc.creds) {
+				t.Fatalf("Expected %d results, got %d", len(tc.creds), len(results))
+			}
+			if results[0].Severity != tc.expected {
+				t.Errorf("Expected severity %s, got %s", tc.expected, results[0].Severity)
+			}
+		})
+	}
+}
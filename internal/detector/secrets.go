@@ -0,0 +1,73 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yourorg/unpass/internal/domain"
+	"github.com/yourorg/unpass/internal/secrets"
+	"github.com/yourorg/unpass/internal/types"
+)
+
+// SecretsDetector 扫描凭据备注中不应与密码放在一起的敏感信息
+// Enpass、Bitwarden等提供商把自定义文本字段合并进Notes，一并检查
+// 每处发现产生一个结果，元数据和消息中只包含脱敏后的片段
+type SecretsDetector struct {
+	scanner       *secrets.Scanner
+	domainMatcher *domain.DomainMatcher
+}
+
+func NewSecretsDetector() *SecretsDetector {
+	return &SecretsDetector{
+		scanner:       secrets.NewScanner(),
+		domainMatcher: domain.NewDomainMatcher(nil, nil),
+	}
+}
+
+func (d *SecretsDetector) Name() string {
+	return "secrets"
+}
+
+func (d *SecretsDetector) Detect(ctx context.Context, creds []types.Credential) ([]types.DetectionResult, error) {
+	var results []types.DetectionResult
+
+	for _, cred := range creds {
+		findings := d.scanner.Scan(cred.Notes)
+		if len(findings) == 0 {
+			continue
+		}
+
+		var zone string
+		for _, url := range credentialURLs(cred) {
+			if zone = hostedZone(d.domainMatcher, url); zone != "" {
+				break
+			}
+		}
+
+		for _, finding := range findings {
+			metadata := map[string]interface{}{
+				"category": string(finding.Category),
+				"rule":     finding.Rule,
+				"excerpt":  finding.Excerpt,
+			}
+			if zone != "" {
+				metadata["domain"] = zone
+			}
+
+			results = append(results, types.DetectionResult{
+				CredentialID: cred.ID,
+				Title:        cred.Title,
+				Type:         types.DetectionSecretInNotes,
+				Severity:     finding.Severity,
+				Message:      fmt.Sprintf("Notes contain %s", finding.Description),
+				Metadata:     metadata,
+			})
+		}
+	}
+
+	return results, nil
+}
+
+func (d *SecretsDetector) Configure(config map[string]interface{}) error {
+	return nil
+}
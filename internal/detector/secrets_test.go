@@ -0,0 +1,68 @@
+package detector
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/yourorg/unpass/internal/types"
+)
+
+func TestSecretsDetector(t *testing.T) {
+	detector := NewSecretsDetector()
+	token := "ghp_" + strings.Repeat("a1B2", 9)
+	creds := []types.Credential{
+		{ID: "1", Title: "GitHub", URL: "https://github.com/login", Notes: "CLI token: " + token},
+		{ID: "2", Title: "Wallet", URL: "http://192.168.1.1:8080", Notes: "Seed: " + strings.Repeat("abandon ", 11) + "about; Security Answer: Fluffy"},
+		{ID: "3", Title: "Mail", URL: "https://mail.example.com", Notes: "Security Question: My first pet name; Phone: +1 555 0100"},
+		{ID: "4", Title: "Empty"},
+	}
+
+	results, err := detector.Detect(context.Background(), creds)
+	if err != nil {
+		t.Fatalf("Detect failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d: %+v", len(results), results)
+	}
+
+	tests := []struct {
+		id       string
+		category string
+		severity types.Severity
+		excerpt  string
+		message  string
+	}{
+		{"1", "api_token", types.SeverityCritical, "ghp_********", "Notes contain a GitHub token"},
+		{"2", "seed_phrase", types.SeverityCritical, "(12 words)", "Notes contain a valid BIP39 seed phrase"},
+		{"2", "security_answer", types.SeverityMedium, "Security Answer: [redacted]", "Notes contain a security question answer"},
+	}
+	for i, test := range tests {
+		result := results[i]
+		if result.CredentialID != test.id || result.Type != types.DetectionSecretInNotes || result.Severity != test.severity {
+			t.Errorf("Unexpected result %d: %+v", i, result)
+			continue
+		}
+		if result.Metadata["category"] != test.category || result.Metadata["excerpt"] != test.excerpt || result.Message != test.message {
+			t.Errorf("Result %d: unexpected metadata %v, message %q", i, result.Metadata, result.Message)
+		}
+	}
+	if results[0].Metadata["domain"] != "github.com" {
+		t.Errorf("Expected domain github.com, got %v", results[0].Metadata["domain"])
+	}
+	if results[1].Metadata["domain"] != "192.168.1.1" {
+		t.Errorf("Expected domain 192.168.1.1, got %v", results[1].Metadata["domain"])
+	}
+
+	// 报告中不包含敏感信息本身
+	for _, result := range results {
+		for _, value := range result.Metadata {
+			if s, ok := value.(string); ok && (strings.Contains(s, token) || strings.Contains(s, "Fluffy") || strings.Contains(s, "abandon")) {
+				t.Errorf("Metadata leaks a secret: %v", result.Metadata)
+			}
+		}
+		if strings.Contains(result.Message, token) || strings.Contains(result.Message, "Fluffy") {
+			t.Errorf("Message leaks a secret: %q", result.Message)
+		}
+	}
+}
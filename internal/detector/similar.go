@@ -0,0 +1,422 @@
+package detector
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/yourorg/unpass/internal/database"
+	"github.com/yourorg/unpass/internal/domain"
+	"github.com/yourorg/unpass/internal/types"
+)
+
+const (
+	// similarThreshold 骨架相似度不低于该值的密码视为同一家族
+	similarThreshold = 0.8
+	// minSkeletonLength 骨架太短（如纯数字密码）无法判断结构相似
+	minSkeletonLength = 4
+	// minSiteTokenLength 短于该长度的站点名不从密码中剔除，避免误删普通字母
+	minSiteTokenLength = 3
+)
+
+// 归一化时识别的变形模式
+const (
+	patternNumber    = "number"    // 年份或首尾数字
+	patternSiteName  = "site_name" // 包含站点名
+	patternLeetspeak = "leetspeak" // 数字或符号替代字母
+)
+
+var (
+	yearPattern       = regexp.MustCompile(`(19|20)\d{2}`)
+	edgeDigitsPattern = regexp.MustCompile(`^\d+|\d+$`)
+)
+
+// leetReplacer 常见的字母替代
+var leetReplacer = strings.NewReplacer(
+	"0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "@", "a", "$", "s",
+)
+
+// similarEntry 家族检测记录的凭据信息；只保存归一化后的骨架和密码摘要，不保存密码
+type similarEntry struct {
+	id       string
+	title    string
+	domain   string
+	sources  []types.CredentialSource
+	category string
+	skeleton string
+	digest   [sha256.Size]byte // 区分完全相同的密码，完全复用由复用检测器报告
+	patterns []string
+}
+
+// SimilarPasswordDetector 检测结构相近的密码家族，如Summer2023!/Summer2024!、Pa55word-github/Pa55word-gitlab
+// 密码先去掉年份和首尾数字、还原常见的字母替代、剔除站点名，再按编辑距离比较骨架
+// 骨架跨批次保留在内存中，Finish后清除
+type SimilarPasswordDetector struct {
+	key           []byte
+	entries       []similarEntry
+	domainMatcher *domain.DomainMatcher
+}
+
+func NewSimilarPasswordDetector(dbLoader *database.DatabaseLoader) (*SimilarPasswordDetector, error) {
+	key := make([]byte, sha256.Size)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate similarity hash key: %w", err)
+	}
+
+	twofaDB, _ := dbLoader.LoadTwoFADatabase()
+	passkeyDB, _ := dbLoader.LoadPasskeyDatabase()
+
+	return &SimilarPasswordDetector{
+		key:           key,
+		domainMatcher: domain.NewDomainMatcher(twofaDB, passkeyDB),
+	}, nil
+}
+
+func (d *SimilarPasswordDetector) Name() string {
+	return "similar"
+}
+
+// Detect 记录本批凭据的密码骨架，家族在Finish中计算
+func (d *SimilarPasswordDetector) Detect(ctx context.Context, creds []types.Credential) ([]types.DetectionResult, error) {
+	for _, cred := range creds {
+		if cred.Password == "" {
+			continue
+		}
+
+		var zones []string
+		for _, url := range credentialURLs(cred) {
+			if zone := d.domainMatcher.ExtractHostedZone(url); zone != "" && !slices.Contains(zones, zone) {
+				zones = append(zones, zone)
+			}
+		}
+
+		skeleton, patterns := normalizePassword(cred.Password, siteTokens(cred.Title, zones))
+		if len([]rune(skeleton)) < minSkeletonLength {
+			continue
+		}
+
+		mac := hmac.New(sha256.New, d.key)
+		mac.Write([]byte(cred.Password))
+		entry := similarEntry{
+			id:       cred.ID,
+			title:    cred.Title,
+			sources:  cred.Sources,
+			category: cred.Category,
+			skeleton: skeleton,
+			patterns: patterns,
+		}
+		mac.Sum(entry.digest[:0])
+		if len(zones) > 0 {
+			entry.domain = zones[0]
+		}
+		d.entries = append(d.entries, entry)
+	}
+
+	return nil, nil
+}
+
+// Finish 用并查集把相似度达到阈值的密码合并为家族，为每个有不同密码成员的凭据产生一个结果
+func (d *SimilarPasswordDetector) Finish(ctx context.Context) ([]types.DetectionResult, error) {
+	entries := d.entries
+	defer func() {
+		// 骨架由密码推导，结果产生后立即释放
+		clear(d.entries)
+		d.entries = nil
+	}()
+
+	parent := make([]int, len(entries))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	// best[i] 第i个凭据与其他不同密码之间的最高相似度
+	best := make([]float64, len(entries))
+	candidates := newSkeletonCandidates(entries)
+	for i := range entries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		for _, j := range candidates.after(i) {
+			if entries[i].digest == entries[j].digest {
+				parent[find(i)] = find(j)
+				continue
+			}
+			score := skeletonSimilarity(entries[i].skeleton, entries[j].skeleton)
+			if score < similarThreshold {
+				continue
+			}
+			parent[find(i)] = find(j)
+			best[i] = math.Max(best[i], score)
+			best[j] = math.Max(best[j], score)
+		}
+	}
+
+	// 按首个成员出现的顺序编号家族
+	families := make(map[int][]int)
+	var roots []int
+	for i := range entries {
+		root := find(i)
+		if _, exists := families[root]; !exists {
+			roots = append(roots, root)
+		}
+		families[root] = append(families[root], i)
+	}
+
+	var results []types.DetectionResult
+	familyID := 0
+	for _, root := range roots {
+		members := families[root]
+		if len(members) < 2 {
+			continue
+		}
+
+		var patterns []string
+		for _, i := range members {
+			for _, pattern := range entries[i].patterns {
+				if !slices.Contains(patterns, pattern) {
+					patterns = append(patterns, pattern)
+				}
+			}
+		}
+		slices.Sort(patterns)
+
+		reported := false
+		for _, i := range members {
+			// 只与完全相同的密码成组时交给复用检测器
+			if best[i] == 0 {
+				continue
+			}
+			if !reported {
+				familyID++
+				reported = true
+			}
+
+			var related []string
+			for _, j := range members {
+				if j != i {
+					related = append(related, entries[j].id)
+				}
+			}
+
+			metadata := map[string]interface{}{
+				"family_id":           familyID,
+				"family_size":         len(members),
+				"related_credentials": related,
+				"similarity":          math.Round(best[i]*100) / 100,
+				"patterns":            patterns,
+			}
+			if entries[i].domain != "" {
+				metadata["domain"] = entries[i].domain
+			}
+
+			severity := types.SeverityMedium
+			if len(members) >= 3 {
+				severity = types.SeverityHigh
+			}
+
+			results = append(results, types.DetectionResult{
+				CredentialID: entries[i].id,
+				Title:        entries[i].title,
+				Type:         types.DetectionSimilarPassword,
+				Severity:     severity,
+				Message:      fmt.Sprintf("Password is a variation of %d other credentials' passwords", len(related)),
+				Metadata:     metadata,
+				Sources:      entries[i].sources,
+				Category:     entries[i].category,
+			})
+		}
+	}
+
+	return results, nil
+}
+
+func (d *SimilarPasswordDetector) Configure(config map[string]interface{}) error {
+	return nil
+}
+
+// siteTokens 站点名（主域名去掉后缀）和标题中的单词，用于从密码中剔除
+func siteTokens(title string, zones []string) []string {
+	var tokens []string
+	add := func(token string) {
+		token = strings.ToLower(token)
+		if len([]rune(token)) >= minSiteTokenLength && !slices.Contains(tokens, token) {
+			tokens = append(tokens, token)
+		}
+	}
+
+	for _, zone := range zones {
+		label, _, _ := strings.Cut(zone, ".")
+		add(label)
+	}
+	for _, word := range strings.FieldsFunc(title, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		add(word)
+	}
+
+	// 长的先剔除，避免短词截断长词
+	slices.SortFunc(tokens, func(a, b string) int { return len(b) - len(a) })
+	return tokens
+}
+
+// normalizePassword 返回密码的结构骨架及其中出现的变形模式
+func normalizePassword(password string, tokens []string) (string, []string) {
+	var patterns []string
+	value := strings.ToLower(password)
+
+	// 年份和首尾的数字通常是递增的计数
+	stripped := yearPattern.ReplaceAllString(value, "")
+	stripped = edgeDigitsPattern.ReplaceAllString(strings.TrimFunc(stripped, isSymbol), "")
+	if stripped != strings.TrimFunc(value, isSymbol) {
+		patterns = append(patterns, patternNumber)
+	}
+	value = stripped
+
+	if unleet := leetReplacer.Replace(value); unleet != value {
+		patterns = append(patterns, patternLeetspeak)
+		value = unleet
+	}
+
+	for _, token := range tokens {
+		for _, variant := range []string{token, leetReplacer.Replace(token)} {
+			if strings.Contains(value, variant) {
+				value = strings.ReplaceAll(value, variant, "")
+				if !slices.Contains(patterns, patternSiteName) {
+					patterns = append(patterns, patternSiteName)
+				}
+			}
+		}
+	}
+
+	// 分隔符和剩余的数字不影响结构
+	value = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) {
+			return r
+		}
+		return -1
+	}, value)
+
+	return value, patterns
+}
+
+func isSymbol(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// skeletonSimilarity 1 - 编辑距离/较长骨架的长度
+func skeletonSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	ra, rb := []rune(a), []rune(b)
+	longer := max(len(ra), len(rb))
+	// 长度差已超出阈值时不必计算编辑距离
+	if float64(longer-min(len(ra), len(rb)))/float64(longer) > 1-similarThreshold {
+		return 0
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longer)
+}
+
+// bigram 骨架中相邻的两个字符
+type bigram [2]rune
+
+// bigramPosting 包含某个二元组的凭据及该二元组在骨架中出现的次数
+type bigramPosting struct {
+	entry int
+	count int
+}
+
+// skeletonCandidates 用二元组倒排索引筛选可能达到相似度阈值的骨架对，避免对所有骨架两两计算编辑距离
+// 依据q-gram引理：编辑距离不超过k的两个串，较长者的L-1个二元组中至少有L-1-2k个也出现在另一个串中
+type skeletonCandidates struct {
+	lengths  []int
+	bigrams  []map[bigram]int
+	postings map[bigram][]bigramPosting
+	shared   []int // 按凭据累计的共有二元组数，复用以避免每次分配
+}
+
+func newSkeletonCandidates(entries []similarEntry) *skeletonCandidates {
+	c := &skeletonCandidates{
+		lengths:  make([]int, len(entries)),
+		bigrams:  make([]map[bigram]int, len(entries)),
+		postings: make(map[bigram][]bigramPosting),
+		shared:   make([]int, len(entries)),
+	}
+	for i, entry := range entries {
+		runes := []rune(entry.skeleton)
+		counts := make(map[bigram]int, len(runes))
+		for k := 1; k < len(runes); k++ {
+			counts[bigram{runes[k-1], runes[k]}]++
+		}
+		c.lengths[i] = len(runes)
+		c.bigrams[i] = counts
+		for gram, count := range counts {
+			c.postings[gram] = append(c.postings[gram], bigramPosting{entry: i, count: count})
+		}
+	}
+	return c
+}
+
+// after 返回i之后可能与第i个骨架达到相似度阈值的凭据，按下标升序
+// 骨架至少有minSkeletonLength个字符，所需的共有二元组数总是大于0，没有共有二元组的凭据不可能入选
+func (c *skeletonCandidates) after(i int) []int {
+	var touched []int
+	for gram, count := range c.bigrams[i] {
+		for _, posting := range c.postings[gram] {
+			if posting.entry <= i {
+				continue
+			}
+			if c.shared[posting.entry] == 0 {
+				touched = append(touched, posting.entry)
+			}
+			c.shared[posting.entry] += min(count, posting.count)
+		}
+	}
+
+	var candidates []int
+	for _, j := range touched {
+		longer := max(c.lengths[i], c.lengths[j])
+		// 多算一次编辑，避免浮点误差漏掉恰好等于阈值的骨架对
+		maxEdits := int(float64(longer)*(1-similarThreshold)) + 1
+		if c.shared[j] >= longer-1-2*maxEdits {
+			candidates = append(candidates, j)
+		}
+		c.shared[j] = 0
+	}
+	slices.Sort(candidates)
+	return candidates
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,127 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/yourorg/unpass/internal/database"
+	"github.com/yourorg/unpass/internal/types"
+)
+
+func TestNormalizePassword(t *testing.T) {
+	testCases := []struct {
+		password string
+		tokens   []string
+		skeleton string
+		patterns []string
+	}{
+		{password: "Summer2023!", skeleton: "summer", patterns: []string{patternNumber}},
+		{password: "Pa55word-github", tokens: []string{"github"}, skeleton: "password", patterns: []string{patternLeetspeak, patternSiteName}},
+		{password: "12monkeys", skeleton: "monkeys", patterns: []string{patternNumber}},
+		{password: "g1thubR0cks", tokens: []string{"github"}, skeleton: "rocks", patterns: []string{patternLeetspeak, patternSiteName}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.password, func(t *testing.T) {
+			skeleton, patterns := normalizePassword(tc.password, tc.tokens)
+			if skeleton != tc.skeleton || !slices.Equal(patterns, tc.patterns) {
+				t.Errorf("Expected %q %v, got %q %v", tc.skeleton, tc.patterns, skeleton, patterns)
+			}
+		})
+	}
+}
+
+func TestSimilarPasswordDetector(t *testing.T) {
+	detector, err := NewSimilarPasswordDetector(database.NewDatabaseLoader(t.TempDir()))
+	if err != nil {
+		t.Fatalf("NewSimilarPasswordDetector failed: %v", err)
+	}
+
+	batches := [][]types.Credential{
+		{
+			{ID: "1", Title: "VPN", URL: "https://vpn.corp.example", Password: "Summer2023!"},
+			{ID: "2", Title: "HR", URL: "https://hr.example.com", Password: "Summer2024!"},
+		},
+		{
+			{ID: "3", Title: "GitHub", URL: "https://github.com", Password: "Pa55word-github"},
+			{ID: "4", Title: "GitLab", URL: "https://gitlab.com", Password: "Pa55word-gitlab"},
+			{ID: "5", Title: "Bank", URL: "https://bank.example", Password: "x7$Kq9!vLm2@Wz"},
+		},
+		{
+			// 完全相同的密码由复用检测器报告
+			{ID: "6", Title: "Forum", URL: "https://forum.example", Password: "identical-secret"},
+			{ID: "7", Title: "Blog", URL: "https://blog.example", Password: "identical-secret"},
+			{ID: "8", Title: "Wiki", URL: "https://wiki.example", Password: "Summ3r2025"},
+		},
+	}
+	for _, batch := range batches {
+		if _, err := detector.Detect(context.Background(), batch); err != nil {
+			t.Fatalf("Detect failed: %v", err)
+		}
+	}
+	results, err := detector.Finish(context.Background())
+	if err != nil {
+		t.Fatalf("Finish failed: %v", err)
+	}
+
+	families := make(map[int][]string)
+	for _, result := range results {
+		if result.Type != types.DetectionSimilarPassword {
+			t.Errorf("Unexpected result type: %s", result.Type)
+		}
+		family := result.Metadata["family_id"].(int)
+		families[family] = append(families[family], result.CredentialID)
+
+		// 元数据中不能出现密码或骨架
+		text := strings.ToLower(fmt.Sprintf("%v", result.Metadata))
+		for _, secret := range []string{"summer", "summ3r", "pa55word", "password", "identical"} {
+			if strings.Contains(text, secret) {
+				t.Errorf("Result leaks %q: %s", secret, text)
+			}
+		}
+	}
+
+	if len(families) != 2 || !slices.Equal(families[1], []string{"1", "2", "8"}) || !slices.Equal(families[2], []string{"3", "4"}) {
+		t.Errorf("Unexpected families: %v", families)
+	}
+	if results[0].Severity != types.SeverityHigh || results[3].Severity != types.SeverityMedium {
+		t.Errorf("Expected larger family to have higher severity, got %s and %s", results[0].Severity, results[3].Severity)
+	}
+	if similarity := results[3].Metadata["similarity"].(float64); similarity < similarThreshold {
+		t.Errorf("Expected similarity above threshold, got %v", similarity)
+	}
+}
+
+func BenchmarkSimilarPasswordFinish(b *testing.B) {
+	detector, err := NewSimilarPasswordDetector(database.NewDatabaseLoader(b.TempDir()))
+	if err != nil {
+		b.Fatalf("NewSimilarPasswordDetector failed: %v", err)
+	}
+
+	const alphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789!@#$%"
+	random := rand.New(rand.NewSource(1))
+	creds := make([]types.Credential, 20000)
+	for i := range creds {
+		password := make([]byte, 10+random.Intn(8))
+		for j := range password {
+			password[j] = alphabet[random.Intn(len(alphabet))]
+		}
+		creds[i] = types.Credential{ID: fmt.Sprint(i), Title: fmt.Sprintf("Site %d", i), Password: string(password)}
+	}
+
+	b.ResetTimer()
+	for range b.N {
+		b.StopTimer()
+		if _, err := detector.Detect(context.Background(), creds); err != nil {
+			b.Fatalf("Detect failed: %v", err)
+		}
+		b.StartTimer()
+		if _, err := detector.Finish(context.Background()); err != nil {
+			b.Fatalf("Finish failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package detector
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yourorg/unpass/internal/domain"
+	"github.com/yourorg/unpass/internal/totp"
+	"github.com/yourorg/unpass/internal/types"
+)
+
+// TOTP配置问题
+const (
+	totpIssueInvalidURI           = "invalid_uri"
+	totpIssueUndecodableSecret    = "undecodable_secret"
+	totpIssueUnsupportedAlgorithm = "unsupported_algorithm"
+	totpIssueGenerationFailed     = "generation_failed" // 能解析但无法生成验证码，如位数超出范围
+	totpIssueShortSecret          = "short_secret"
+	totpIssueUnusualDigits        = "unusual_digits"
+	totpIssueUnusualPeriod        = "unusual_period"
+	totpIssueSharedSecret         = "shared_secret"
+)
+
+const (
+	// minTOTPSecretBits RFC 4226要求密钥至少128位
+	minTOTPSecretBits = 128
+	// weakTOTPSecretBits 低于80位（16个base32字符）的密钥少见，严重程度更高
+	weakTOTPSecretBits = 80
+)
+
+// totpEntry 共用密钥检测记录的凭据信息，不保存密钥
+type totpEntry struct {
+	id       string
+	title    string
+	zone     string
+	sources  []types.CredentialSource
+	category string
+	usable   bool // 能否生成验证码
+}
+
+// TOTPDetector 解析凭据中的TOTP密钥或otpauth/steam URI，报告无法使用或不合常规的配置
+// 无法解码、算法不受支持或无法按RFC 6238生成验证码的TOTP不算启用了2FA，2FA检测仍会报告对应站点
+// 密钥以本次运行随机密钥的HMAC分组，多个凭据共用同一密钥时由Finish报告；结果中不包含密钥
+type TOTPDetector struct {
+	key           []byte
+	groups        map[[sha256.Size]byte][]totpEntry
+	order         [][sha256.Size]byte
+	domainMatcher *domain.DomainMatcher
+}
+
+func NewTOTPDetector() (*TOTPDetector, error) {
+	key := make([]byte, sha256.Size)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate TOTP hash key: %w", err)
+	}
+
+	return &TOTPDetector{
+		key:           key,
+		groups:        make(map[[sha256.Size]byte][]totpEntry),
+		domainMatcher: domain.NewDomainMatcher(nil, nil),
+	}, nil
+}
+
+func (d *TOTPDetector) Name() string {
+	return "totp"
+}
+
+func (d *TOTPDetector) Detect(ctx context.Context, creds []types.Credential) ([]types.DetectionResult, error) {
+	var results []types.DetectionResult
+
+	for _, cred := range creds {
+		if strings.TrimSpace(cred.TOTP) == "" {
+			continue
+		}
+
+		var zone string
+		for _, url := range credentialURLs(cred) {
+			if zone = d.domainMatcher.ExtractHostedZone(url); zone != "" {
+				break
+			}
+		}
+
+		metadata := map[string]interface{}{}
+		if zone != "" {
+			metadata["domain"] = zone
+		}
+
+		key, err := totp.Parse(cred.TOTP)
+		if err != nil {
+			issue := totpIssueInvalidURI
+			switch {
+			case errors.Is(err, totp.ErrUndecodableSecret):
+				issue = totpIssueUndecodableSecret
+			case errors.Is(err, totp.ErrUnsupportedAlgorithm):
+				issue = totpIssueUnsupportedAlgorithm
+			}
+			metadata["issues"] = []string{issue}
+			metadata["usable"] = false
+			results = append(results, types.DetectionResult{
+				CredentialID: cred.ID,
+				Title:        cred.Title,
+				Type:         types.DetectionTOTPMisconfigured,
+				Severity:     types.SeverityHigh,
+				Message:      fmt.Sprintf("TOTP cannot be used: %v", err),
+				Metadata:     metadata,
+			})
+			continue
+		}
+
+		var issues, messages []string
+		var severity types.Severity
+		add := func(issue, message string, issueSeverity types.Severity) {
+			issues = append(issues, issue)
+			messages = append(messages, message)
+			if severityRank[issueSeverity] > severityRank[severity] {
+				severity = issueSeverity
+			}
+		}
+
+		usable := true
+		if _, err := key.Code(time.Now()); err != nil {
+			usable = false
+			add(totpIssueGenerationFailed, fmt.Sprintf("cannot generate a code (%v)", err), types.SeverityHigh)
+		}
+		d.record(cred, zone, key.Secret, usable)
+
+		if bits := key.SecretBits(); bits < weakTOTPSecretBits {
+			add(totpIssueShortSecret, fmt.Sprintf("has a %d-bit secret", bits), types.SeverityMedium)
+		} else if bits < minTOTPSecretBits {
+			add(totpIssueShortSecret, fmt.Sprintf("has a %d-bit secret", bits), types.SeverityLow)
+		}
+		// Steam令牌固定为5个字符，不算异常
+		if !key.Steam && key.Digits != 6 && key.Digits != 8 {
+			add(totpIssueUnusualDigits, fmt.Sprintf("uses %d digits", key.Digits), types.SeverityLow)
+		}
+		if key.Type == totp.TypeTOTP && key.Period != totp.DefaultPeriod {
+			add(totpIssueUnusualPeriod, fmt.Sprintf("uses a %d-second period", key.Period), types.SeverityLow)
+		}
+		if len(issues) == 0 {
+			continue
+		}
+
+		metadata["issues"] = issues
+		metadata["usable"] = usable
+		metadata["format"] = key.Format
+		metadata["secret_bits"] = key.SecretBits()
+		metadata["algorithm"] = key.Algorithm
+		metadata["digits"] = key.Digits
+		metadata["period"] = key.Period
+		if key.Issuer != "" {
+			metadata["issuer"] = key.Issuer
+		}
+
+		results = append(results, types.DetectionResult{
+			CredentialID: cred.ID,
+			Title:        cred.Title,
+			Type:         types.DetectionTOTPMisconfigured,
+			Severity:     severity,
+			Message:      fmt.Sprintf("TOTP %s", joinWords(messages, "and")),
+			Metadata:     metadata,
+		})
+	}
+
+	return results, nil
+}
+
+// record 记录密钥摘要及该凭据的TOTP能否使用，供Finish查找共用同一密钥的凭据
+func (d *TOTPDetector) record(cred types.Credential, zone string, secret []byte, usable bool) {
+	mac := hmac.New(sha256.New, d.key)
+	mac.Write(secret)
+	var digest [sha256.Size]byte
+	mac.Sum(digest[:0])
+
+	if _, exists := d.groups[digest]; !exists {
+		d.order = append(d.order, digest)
+	}
+	d.groups[digest] = append(d.groups[digest], totpEntry{
+		id:       cred.ID,
+		title:    cred.Title,
+		zone:     zone,
+		sources:  cred.Sources,
+		category: cred.Category,
+		usable:   usable,
+	})
+}
+
+// Finish 对共用同一TOTP密钥的每个凭据各产生一个结果：一处泄露即可为所有这些账户生成验证码
+func (d *TOTPDetector) Finish(ctx context.Context) ([]types.DetectionResult, error) {
+	var results []types.DetectionResult
+
+	for _, digest := range d.order {
+		group := d.groups[digest]
+		if len(group) < 2 {
+			continue
+		}
+
+		for i, entry := range group {
+			var others []string
+			for j, other := range group {
+				if j != i {
+					others = append(others, other.id)
+				}
+			}
+
+			metadata := map[string]interface{}{
+				"issues":      []string{totpIssueSharedSecret},
+				"shared_with": others,
+				"usable":      entry.usable,
+			}
+			if entry.zone != "" {
+				metadata["domain"] = entry.zone
+			}
+
+			results = append(results, types.DetectionResult{
+				CredentialID: entry.id,
+				Title:        entry.title,
+				Type:         types.DetectionTOTPMisconfigured,
+				Severity:     types.SeverityMedium,
+				Message:      fmt.Sprintf("TOTP secret is shared with %d other credentials", len(others)),
+				Metadata:     metadata,
+				Sources:      entry.sources,
+				Category:     entry.category,
+			})
+		}
+	}
+
+	// 结果已产生，释放摘要
+	clear(d.groups)
+	d.order = nil
+	return results, nil
+}
+
+func (d *TOTPDetector) Configure(config map[string]interface{}) error {
+	return nil
+}
+
+// totpConfigured TOTP字段能解析并生成验证码时才视为已启用2FA
+func totpConfigured(raw string) bool {
+	if strings.TrimSpace(raw) == "" {
+		return false
+	}
+	key, err := totp.Parse(raw)
+	if err != nil {
+		return false
+	}
+	_, err = key.Code(time.Now())
+	return err == nil
+}
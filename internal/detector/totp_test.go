@@ -0,0 +1,118 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/yourorg/unpass/internal/database"
+	"github.com/yourorg/unpass/internal/types"
+)
+
+func TestTOTPDetector(t *testing.T) {
+	detector, err := NewTOTPDetector()
+	if err != nil {
+		t.Fatalf("NewTOTPDetector failed: %v", err)
+	}
+	creds := []types.Credential{
+		{ID: "1", Title: "GitHub", URL: "https://github.com", TOTP: "otpauth://totp/GitHub:alice?secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP&issuer=GitHub"},
+		{ID: "2", Title: "Broken", URL: "https://broken.example.com", TOTP: "not a secret!"},
+		{ID: "3", Title: "Short", TOTP: "JBSWY3DPEHPK3PXP"},
+		{ID: "4", Title: "Odd", TOTP: "otpauth://totp/Odd?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ&digits=7&period=60"},
+		{ID: "5", Title: "Too many digits", TOTP: "otpauth://totp/Odd?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ&digits=12"},
+		{ID: "6", Title: "MD5", TOTP: "otpauth://totp/Odd?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ&algorithm=MD5"},
+		{ID: "7", Title: "Steam", TOTP: "steam://GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"},
+		{ID: "8", Title: "None"},
+	}
+
+	results, err := detector.Detect(context.Background(), creds)
+	if err != nil {
+		t.Fatalf("Detect failed: %v", err)
+	}
+
+	tests := []struct {
+		id       string
+		issues   []string
+		severity types.Severity
+		usable   bool
+	}{
+		{"2", []string{"undecodable_secret"}, types.SeverityHigh, false},
+		{"3", []string{"short_secret"}, types.SeverityLow, true},
+		{"4", []string{"unusual_digits", "unusual_period"}, types.SeverityLow, true},
+		{"5", []string{"generation_failed", "unusual_digits"}, types.SeverityHigh, false},
+		{"6", []string{"unsupported_algorithm"}, types.SeverityHigh, false},
+	}
+	if len(results) != len(tests) {
+		t.Fatalf("Expected %d results, got %d: %+v", len(tests), len(results), results)
+	}
+	for i, test := range tests {
+		result := results[i]
+		if result.CredentialID != test.id || result.Type != types.DetectionTOTPMisconfigured || result.Severity != test.severity {
+			t.Errorf("Unexpected result for %s: %+v", test.id, result)
+			continue
+		}
+		if issues := result.Metadata["issues"].([]string); !slices.Equal(issues, test.issues) {
+			t.Errorf("%s: expected issues %v, got %v", test.id, test.issues, issues)
+		}
+		if result.Metadata["usable"] != test.usable {
+			t.Errorf("%s: expected usable=%v, got %v", test.id, test.usable, result.Metadata["usable"])
+		}
+	}
+	if results[2].Message != "TOTP uses 7 digits and uses a 60-second period" {
+		t.Errorf("Unexpected message: %s", results[2].Message)
+	}
+
+	// 密钥只在Finish中按摘要比较：4、5、7共用同一密钥，6无法解析不参与比较
+	shared, err := detector.Finish(context.Background())
+	if err != nil {
+		t.Fatalf("Finish failed: %v", err)
+	}
+	if len(shared) != 3 {
+		t.Fatalf("Expected 3 shared secret results, got %d: %+v", len(shared), shared)
+	}
+	if others := shared[0].Metadata["shared_with"].([]string); shared[0].CredentialID != "4" || !slices.Equal(others, []string{"5", "7"}) {
+		t.Errorf("Unexpected shared secret result: %+v", shared[0])
+	}
+	// 共用密钥的结果按每个凭据实际能否生成验证码标注
+	for i, usable := range []bool{true, false, true} {
+		if shared[i].Metadata["usable"] != usable {
+			t.Errorf("%s: expected usable=%v, got %v", shared[i].CredentialID, usable, shared[i].Metadata["usable"])
+		}
+	}
+
+	// 结果中不能出现密钥
+	for _, result := range append(results, shared...) {
+		text := strings.ToUpper(result.Message + fmt.Sprint(result.Metadata))
+		for _, secret := range []string{"JBSWY3DPEHPK3PXP", "GEZDGNBVGY3TQOJQ"} {
+			if strings.Contains(text, secret) {
+				t.Errorf("%s: result leaks the TOTP secret: %s", result.CredentialID, text)
+			}
+		}
+	}
+}
+
+func TestTwoFADetectorIgnoresUnusableTOTP(t *testing.T) {
+	twofaDB := `{"sites": [{"domain": "github.com", "supports_2fa": true, "methods": ["totp"]}]}`
+	detector, err := NewTwoFADetector(database.NewDatabaseLoader(writeTestDatabases(t, twofaDB, "")))
+	if err != nil {
+		t.Fatalf("NewTwoFADetector failed: %v", err)
+	}
+
+	creds := []types.Credential{
+		{ID: "1", Title: "GitHub", URL: "https://github.com", TOTP: "JBSWY3DPEHPK3PXP"},
+		{ID: "2", Title: "GitHub", URL: "https://github.com", TOTP: "otpauth://totp/GitHub:alice"},
+		{ID: "3", Title: "GitHub", URL: "https://github.com"},
+	}
+	results, err := detector.Detect(context.Background(), creds)
+	if err != nil {
+		t.Fatalf("Detect failed: %v", err)
+	}
+	if len(results) != 2 || results[0].CredentialID != "2" || results[1].CredentialID != "3" {
+		t.Fatalf("Expected the broken TOTP not to suppress the 2FA finding, got %+v", results)
+	}
+	if results[0].Metadata["totp_unusable"] != true {
+		t.Errorf("Expected totp_unusable metadata, got %v", results[0].Metadata)
+	}
+}
@@ -2,6 +2,8 @@ package detector
 
 import (
 	"context"
+	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/yourorg/unpass/internal/database"
@@ -9,8 +11,11 @@ import (
 	"github.com/yourorg/unpass/internal/types"
 )
 
+// TwoFADetector 检测支持2FA但没有配置可用TOTP的凭据，并按站点提供的方法推荐最强的一种
+// 站点只提供短信、邮件或电话验证时另外报告weak_second_factor
 type TwoFADetector struct {
 	supportedDomains map[string]types.TwoFASite
+	passkeySites     map[string]types.PasskeySite // 支持Passkey登录或作为第二因素的站点
 	domainMatcher    *domain.DomainMatcher
 }
 
@@ -30,8 +35,18 @@ func NewTwoFADetector(dbLoader *database.DatabaseLoader) (*TwoFADetector, error)
 		}
 	}
 
+	passkeySites := make(map[string]types.PasskeySite)
+	if passkeyDB != nil {
+		for _, site := range *passkeyDB {
+			if site.Approved && !site.Hidden && (site.PasskeySignin || site.PasskeyMFA) {
+				passkeySites[strings.ToLower(site.Domain)] = site
+			}
+		}
+	}
+
 	return &TwoFADetector{
 		supportedDomains: supportedDomains,
+		passkeySites:     passkeySites,
 		domainMatcher:    domain.NewDomainMatcher(twofaDB, passkeyDB),
 	}, nil
 }
@@ -44,8 +59,8 @@ func (d *TwoFADetector) Detect(ctx context.Context, creds []types.Credential) ([
 	var results []types.DetectionResult
 
 	for _, cred := range creds {
-		// 如果已经配置了TOTP，跳过检测
-		if cred.TOTP != "" {
+		// 如果已经配置了可用的TOTP，跳过检测；无法生成验证码的TOTP不算启用
+		if totpConfigured(cred.TOTP) {
 			continue
 		}
 
@@ -76,19 +91,40 @@ func (d *TwoFADetector) Detect(ctx context.Context, creds []types.Credential) ([
 			detectedDomains[hostedZone] = true
 
 			if site, exists := d.supportedDomains[hostedZone]; exists {
+				metadata := map[string]interface{}{
+					"domain":            hostedZone,
+					"original_url":      url,
+					"supported_methods": site.Methods,
+					"documentation_url": site.DocumentationURL,
+				}
+				if cred.TOTP != "" {
+					metadata["totp_unusable"] = true
+				}
+
+				// 推荐最强的可用方法，支持Passkey时设置链接与2FA文档并列给出
+				passkeySite, passkey := d.passkeySites[hostedZone]
+				ranked := rankMethods(site.Methods, passkey)
+				if len(ranked) > 0 {
+					metadata["ranked_methods"] = ranked
+					metadata["recommended_method"] = ranked[0]
+					metadata["recommendation"] = methodRecommendation(ranked)
+				}
+				if passkey {
+					metadata["setup_link"] = passkeySite.SetupLink
+				}
+
 				results = append(results, types.DetectionResult{
 					CredentialID: cred.ID,
 					Title:        cred.Title,
 					Type:         types.DetectionMissing2FA,
 					Severity:     types.SeverityMedium,
 					Message:      "Website supports 2FA but may not be enabled",
-					Metadata: map[string]interface{}{
-						"domain":            hostedZone,
-						"original_url":      url,
-						"supported_methods": site.Methods,
-						"documentation_url": site.DocumentationURL,
-					},
+					Metadata:     metadata,
 				})
+
+				if onlyInterceptable(ranked) {
+					results = append(results, weakSecondFactorResult(cred, hostedZone, site, ranked))
+				}
 			}
 		}
 	}
@@ -96,6 +132,29 @@ func (d *TwoFADetector) Detect(ctx context.Context, creds []types.Credential) ([
 	return results, nil
 }
 
+// weakSecondFactorResult 站点只提供短信、电话或邮件验证；可被SIM卡劫持的方法为medium，只有邮件为low
+func weakSecondFactorResult(cred types.Credential, hostedZone string, site types.TwoFASite, ranked []string) types.DetectionResult {
+	severity := types.SeverityLow
+	if slices.Contains(ranked, methodSMS) || slices.Contains(ranked, methodCall) {
+		severity = types.SeverityMedium
+	}
+
+	return types.DetectionResult{
+		CredentialID: cred.ID,
+		Title:        cred.Title,
+		Type:         types.DetectionWeakSecondFactor,
+		Severity:     severity,
+		Message:      fmt.Sprintf("Website only offers second factors that can be intercepted (%s)", strings.Join(ranked, ", ")),
+		Metadata: map[string]interface{}{
+			"domain":             hostedZone,
+			"supported_methods":  ranked,
+			"recommended_method": ranked[0],
+			"recommendation":     methodRecommendation(ranked),
+			"documentation_url":  site.DocumentationURL,
+		},
+	}
+}
+
 func (d *TwoFADetector) Configure(config map[string]interface{}) error {
 	return nil
 }
@@ -0,0 +1,185 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yourorg/unpass/internal/domain"
+	"github.com/yourorg/unpass/internal/strength"
+	"github.com/yourorg/unpass/internal/types"
+)
+
+// defaultMinStrengthScore 评分低于3（猜测次数不足10^8）的密码视为弱密码
+const defaultMinStrengthScore = 3
+
+// WeakPasswordDetector 按字典单词、字母替代、键盘路径、重复、序列和日期估算密码的猜测次数，报告低于阈值的弱密码
+// 凭据自身的标题、用户名和主域名作为排名最靠前的字典，包含它们的密码猜测次数大幅降低，判断阈值和严重程度时评分再降一级
+// 结果只包含评分、猜测次数和模式名称，不包含匹配到的密码片段
+type WeakPasswordDetector struct {
+	estimator     *strength.Estimator
+	minScore      int
+	minCrackTime  time.Duration
+	domainMatcher *domain.DomainMatcher
+}
+
+func NewWeakPasswordDetector() *WeakPasswordDetector {
+	return &WeakPasswordDetector{
+		estimator:     strength.NewEstimator(),
+		minScore:      defaultMinStrengthScore,
+		domainMatcher: domain.NewDomainMatcher(nil, nil),
+	}
+}
+
+// SetMinScore 评分（0~4）低于该值的密码视为弱密码
+func (d *WeakPasswordDetector) SetMinScore(score int) {
+	d.minScore = score
+}
+
+// SetMinCrackTime 估算破解时间低于该值的密码也视为弱密码，0为不按破解时间判断
+func (d *WeakPasswordDetector) SetMinCrackTime(crackTime time.Duration) {
+	d.minCrackTime = crackTime
+}
+
+// SetGuessesPerSecond 设置估算破解时间时攻击者每秒的猜测次数
+func (d *WeakPasswordDetector) SetGuessesPerSecond(rate float64) {
+	d.estimator.SetGuessesPerSecond(rate)
+}
+
+func (d *WeakPasswordDetector) Name() string {
+	return "weak"
+}
+
+func (d *WeakPasswordDetector) Detect(ctx context.Context, creds []types.Credential) ([]types.DetectionResult, error) {
+	var results []types.DetectionResult
+
+	for _, cred := range creds {
+		if cred.Password == "" {
+			continue
+		}
+
+		var zones []string
+		for _, url := range credentialURLs(cred) {
+			if zone := d.domainMatcher.ExtractHostedZone(url); zone != "" && !slices.Contains(zones, zone) {
+				zones = append(zones, zone)
+			}
+		}
+
+		estimate := d.estimator.Estimate(cred.Password, userInputs(cred, zones))
+		containsUserInput := estimate.ContainsUserInput()
+		score := estimate.Score
+		if containsUserInput && score > 0 {
+			// 针对性攻击会优先尝试账户自身的信息
+			score--
+		}
+		if !d.isWeak(score, estimate.CrackTimeSeconds) {
+			continue
+		}
+
+		crackTime := strength.DisplayTime(estimate.CrackTimeSeconds)
+		metadata := map[string]interface{}{
+			"score":              estimate.Score,
+			"guesses_log10":      math.Round(estimate.GuessesLog10*100) / 100,
+			"crack_time_seconds": estimate.CrackTimeSeconds,
+			"crack_time_display": crackTime,
+			"patterns":           estimate.Patterns(),
+		}
+		if containsUserInput {
+			metadata["contains_user_input"] = true
+		}
+		if len(zones) > 0 {
+			metadata["domain"] = zones[0]
+		}
+
+		message := fmt.Sprintf("Password could be cracked in %s (strength %d/4)", crackTime, estimate.Score)
+		if containsUserInput {
+			message = fmt.Sprintf("Password contains the item's own title, username or site and could be cracked in %s (strength %d/4)", crackTime, estimate.Score)
+		}
+
+		results = append(results, types.DetectionResult{
+			CredentialID: cred.ID,
+			Title:        cred.Title,
+			Type:         types.DetectionWeakPassword,
+			Severity:     weakSeverity(score),
+			Message:      message,
+			Metadata:     metadata,
+		})
+	}
+
+	return results, nil
+}
+
+// isWeak 评分或破解时间任一低于阈值即为弱密码
+func (d *WeakPasswordDetector) isWeak(score int, crackTimeSeconds float64) bool {
+	if score < d.minScore {
+		return true
+	}
+	return d.minCrackTime > 0 && crackTimeSeconds < d.minCrackTime.Seconds()
+}
+
+// Configure 支持 min_score（整数）和 min_crack_time（如"720h"）
+func (d *WeakPasswordDetector) Configure(config map[string]interface{}) error {
+	if value, exists := config["min_score"]; exists {
+		score, ok := value.(int)
+		if !ok || score < 0 || score > 4 {
+			return fmt.Errorf("min_score must be an integer between 0 and 4, got %v", value)
+		}
+		d.minScore = score
+	}
+	if value, exists := config["min_crack_time"]; exists {
+		switch crackTime := value.(type) {
+		case time.Duration:
+			d.minCrackTime = crackTime
+		case string:
+			parsed, err := time.ParseDuration(crackTime)
+			if err != nil {
+				return fmt.Errorf("invalid min_crack_time: %w", err)
+			}
+			d.minCrackTime = parsed
+		default:
+			return fmt.Errorf("min_crack_time must be a duration, got %v", value)
+		}
+	}
+	return nil
+}
+
+// userInputs 与凭据相关、攻击者容易猜到的信息：标题中的单词、用户名及其本地部分、主域名及站点名
+func userInputs(cred types.Credential, zones []string) []string {
+	inputs := siteTokens(cred.Title, zones)
+	add := func(input string) {
+		input = strings.ToLower(strings.TrimSpace(input))
+		if len([]rune(input)) >= minSiteTokenLength && !slices.Contains(inputs, input) {
+			inputs = append(inputs, input)
+		}
+	}
+
+	add(cred.Title)
+	add(cred.Username)
+	if local, _, found := strings.Cut(cred.Username, "@"); found {
+		add(local)
+	}
+	for _, part := range strings.FieldsFunc(cred.Username, isSymbol) {
+		add(part)
+	}
+	for _, zone := range zones {
+		add(zone)
+	}
+	return inputs
+}
+
+// weakSeverity 评分越低越容易在线猜中
+func weakSeverity(score int) types.Severity {
+	switch score {
+	case 0:
+		return types.SeverityCritical
+	case 1:
+		return types.SeverityHigh
+	case 2:
+		return types.SeverityMedium
+	default:
+		return types.SeverityLow
+	}
+}
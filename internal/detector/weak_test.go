@@ -0,0 +1,120 @@
+package detector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourorg/unpass/internal/types"
+)
+
+func TestWeakPasswordDetector(t *testing.T) {
+	detector := NewWeakPasswordDetector()
+	creds := []types.Credential{
+		{ID: "1", Title: "Forum", URL: "https://forum.example.org", Password: "password"},
+		{ID: "2", Title: "Strong", URL: "https://example.com", Password: "kX9#mQ2$vL7@pR4!wT6"},
+		{ID: "3", Title: "Keyboard", Password: "qwerty123"},
+		{ID: "4", Title: "Empty"},
+	}
+
+	results, err := detector.Detect(context.Background(), creds)
+	if err != nil {
+		t.Fatalf("Detect failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d: %+v", len(results), results)
+	}
+
+	result := results[0]
+	if result.CredentialID != "1" || result.Type != types.DetectionWeakPassword || result.Severity != types.SeverityCritical {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if score := result.Metadata["score"].(int); score != 0 {
+		t.Errorf("Expected score 0, got %d", score)
+	}
+	if domain := result.Metadata["domain"]; domain != "example.org" {
+		t.Errorf("Expected domain example.org, got %v", domain)
+	}
+	if result.Metadata["crack_time_display"] != "less than a second" {
+		t.Errorf("Unexpected crack time: %v", result.Metadata["crack_time_display"])
+	}
+	if results[1].CredentialID != "3" {
+		t.Errorf("Expected credential 3 to be weak, got %s", results[1].CredentialID)
+	}
+}
+
+func TestWeakPasswordDetectorUserInputs(t *testing.T) {
+	detector := NewWeakPasswordDetector()
+	creds := []types.Credential{
+		{ID: "1", Title: "Initech Portal", Username: "milton.waddams@example.com", URL: "https://login.initech.com", Password: "Waddams!Initech"},
+	}
+
+	results, err := detector.Detect(context.Background(), creds)
+	if err != nil {
+		t.Fatalf("Detect failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected the password built from the item's own details to be weak, got %+v", results)
+	}
+	if patterns := results[0].Metadata["patterns"].([]string); !slices.Contains(patterns, "user_input") {
+		t.Errorf("Expected user_input pattern, got %v", patterns)
+	}
+	if !strings.Contains(results[0].Message, "own title, username or site") {
+		t.Errorf("Unexpected message: %s", results[0].Message)
+	}
+
+	// 除站点域名外，元数据中不能出现密码片段
+	for key, value := range results[0].Metadata {
+		if key == "domain" {
+			continue
+		}
+		if text := strings.ToLower(fmt.Sprint(value)); strings.Contains(text, "waddams") || strings.Contains(text, "initech") {
+			t.Errorf("Metadata %s leaks a password fragment: %q", key, text)
+		}
+	}
+}
+
+func TestWeakPasswordDetectorThresholds(t *testing.T) {
+	creds := []types.Credential{{ID: "1", Title: "Site", Password: "Tr0ub4dour&3"}}
+
+	detector := NewWeakPasswordDetector()
+	if results, _ := detector.Detect(context.Background(), creds); len(results) != 0 {
+		t.Fatalf("Expected default thresholds to accept the password, got %+v", results)
+	}
+
+	if err := detector.Configure(map[string]interface{}{"min_crack_time": "87600h"}); err != nil {
+		t.Fatalf("Configure failed: %v", err)
+	}
+	results, _ := detector.Detect(context.Background(), creds)
+	if len(results) != 1 || results[0].Severity != types.SeverityLow {
+		t.Fatalf("Expected a low severity result below the crack time threshold, got %+v", results)
+	}
+
+	detector.SetMinCrackTime(0)
+	detector.SetGuessesPerSecond(1e10)
+	detector.SetMinCrackTime(time.Minute)
+	if results, _ := detector.Detect(context.Background(), creds); len(results) != 1 {
+		t.Errorf("Expected a faster attacker to crack the password within a minute, got %+v", results)
+	}
+
+	if err := detector.Configure(map[string]interface{}{"min_score": 7}); err == nil {
+		t.Error("Expected an out of range min_score to be rejected")
+	}
+}
+
+// 只能穷举的短密码没有可识别的模式，JSON报告中patterns为[]
+func TestWeakPasswordDetectorNoPatterns(t *testing.T) {
+	detector := NewWeakPasswordDetector()
+	results, err := detector.Detect(context.Background(), []types.Credential{{ID: "1", Title: "Site", Password: "x7Qz"}})
+	if err != nil || len(results) != 1 {
+		t.Fatalf("Expected the short password to be weak, got %+v, %v", results, err)
+	}
+	data, err := json.Marshal(results[0].Metadata["patterns"])
+	if err != nil || string(data) != "[]" {
+		t.Errorf("Expected patterns to encode as [], got %s, %v", data, err)
+	}
+}
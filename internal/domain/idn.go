@@ -0,0 +1,161 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// punycode参数（RFC 3492）
+const (
+	punycodeBase        = 36
+	punycodeTMin        = 1
+	punycodeTMax        = 26
+	punycodeSkew        = 38
+	punycodeDamp        = 700
+	punycodeInitialBias = 72
+	punycodeInitialN    = 128
+)
+
+// idnPrefix 国际化域名标签的ASCII前缀
+const idnPrefix = "xn--"
+
+var errInvalidPunycode = errors.New("invalid punycode")
+
+// ToUnicode 把主机名中以xn--开头的标签解码为Unicode，无法解码的标签保持原样
+func ToUnicode(hostname string) string {
+	labels := strings.Split(hostname, ".")
+	for i, label := range labels {
+		if len(label) > len(idnPrefix) && strings.EqualFold(label[:len(idnPrefix)], idnPrefix) {
+			if decoded, err := decodePunycode(label[len(idnPrefix):]); err == nil {
+				labels[i] = decoded
+			}
+		}
+	}
+	return strings.Join(labels, ".")
+}
+
+// decodePunycode 解码去掉xn--前缀的punycode标签
+func decodePunycode(encoded string) (string, error) {
+	var output []rune
+	// 最后一个'-'之前是原样保留的ASCII字符
+	if pos := strings.LastIndexByte(encoded, '-'); pos >= 0 {
+		for _, r := range encoded[:pos] {
+			if r >= 0x80 {
+				return "", errInvalidPunycode
+			}
+			output = append(output, r)
+		}
+		encoded = encoded[pos+1:]
+	}
+
+	n, bias, i := punycodeInitialN, punycodeInitialBias, 0
+	for len(encoded) > 0 {
+		oldI, w := i, 1
+		for k := punycodeBase; ; k += punycodeBase {
+			if len(encoded) == 0 {
+				return "", errInvalidPunycode
+			}
+			digit, ok := punycodeDigit(encoded[0])
+			encoded = encoded[1:]
+			if !ok || digit > (math.MaxInt32-i)/w {
+				return "", errInvalidPunycode
+			}
+			i += digit * w
+			t := min(max(k-bias, punycodeTMin), punycodeTMax)
+			if digit < t {
+				break
+			}
+			if w > math.MaxInt32/(punycodeBase-t) {
+				return "", errInvalidPunycode
+			}
+			w *= punycodeBase - t
+		}
+		bias = punycodeAdapt(i-oldI, len(output)+1, oldI == 0)
+		n += i / (len(output) + 1)
+		if n > unicode.MaxRune {
+			return "", errInvalidPunycode
+		}
+		i %= len(output) + 1
+		output = slices.Insert(output, i, rune(n))
+		i++
+	}
+	return string(output), nil
+}
+
+func punycodeDigit(c byte) (int, bool) {
+	switch {
+	case c >= 'a' && c <= 'z':
+		return int(c - 'a'), true
+	case c >= 'A' && c <= 'Z':
+		return int(c - 'A'), true
+	case c >= '0' && c <= '9':
+		return int(c-'0') + 26, true
+	}
+	return 0, false
+}
+
+func punycodeAdapt(delta, numPoints int, first bool) int {
+	if first {
+		delta /= punycodeDamp
+	} else {
+		delta /= 2
+	}
+	delta += delta / numPoints
+	k := 0
+	for delta > ((punycodeBase-punycodeTMin)*punycodeTMax)/2 {
+		delta /= punycodeBase - punycodeTMin
+		k += punycodeBase
+	}
+	return k + (punycodeBase-punycodeTMin+1)*delta/(delta+punycodeSkew)
+}
+
+// confusables 参照Unicode TR39 confusables.txt中域名常见的部分，把字符映射到外观相同的原型
+// 原型统一为小写：数字0、1分别映射为o、l，m、d、w分别展开为rn、cl、vv，使rnicrosoft与microsoft的骨架相同
+var confusables = map[rune]string{
+	'0': "o", '1': "l", '|': "l", 'ı': "i", 'ɩ': "i", 'ɡ': "g",
+	'm': "rn", 'd': "cl", 'w': "vv",
+	// 西里尔字母
+	'а': "a", 'с': "c", 'ԁ': "cl", 'е': "e", 'ё': "e", 'һ': "h", 'і': "i", 'ї': "i", 'ј': "j",
+	'ӏ': "l", 'о': "o", 'р': "p", 'ԛ': "q", 'ѕ': "s", 'ѵ': "v", 'ԝ': "vv", 'х': "x", 'у': "y", 'ү': "y",
+	// 希腊字母
+	'α': "a", 'ι': "i", 'ν': "v", 'ο': "o", 'ρ': "p", 'υ': "u", 'χ': "x", 'γ': "y",
+}
+
+// diacritics 常见带变音符号的拉丁字母对应的基本字母，TR39骨架保留组合符号，这里额外去掉，使päypal也能匹配paypal
+var diacritics = map[string]string{
+	"àáâãäåāăąǎ": "a", "çćĉċč": "c", "ďđ": "cl", "èéêëēĕėęě": "e", "ĝğġģ": "g", "ĥħ": "h",
+	"ìíîïĩīĭįǐ": "i", "ĵ": "j", "ķ": "k", "ĺļľŀł": "l", "ñńņňŉ": "n", "òóôõöøōŏőǒ": "o",
+	"ŕŗř": "r", "śŝşšș": "s", "ţťŧț": "t", "ùúûüũūŭůűųǔ": "u", "ŵ": "vv", "ýÿŷ": "y", "źżž": "z",
+}
+
+var diacriticBase = func() map[rune]string {
+	base := make(map[rune]string)
+	for letters, letter := range diacritics {
+		for _, r := range letters {
+			base[r] = letter
+		}
+	}
+	return base
+}()
+
+// Skeleton 计算TR39风格的混淆骨架：小写后把每个字符替换为原型，去掉变音符号和组合符号
+// 两个字符串骨架相同说明它们在显示上难以区分
+func Skeleton(value string) string {
+	var builder strings.Builder
+	for _, r := range strings.ToLower(value) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if prototype, exists := confusables[r]; exists {
+			builder.WriteString(prototype)
+		} else if base, exists := diacriticBase[r]; exists {
+			builder.WriteString(base)
+		} else {
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,45 @@
+package domain
+
+import "testing"
+
+func TestToUnicode(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"xn--pypal-4ve.com", "pаypal.com"},
+		{"www.xn--mnchen-3ya.de", "www.münchen.de"},
+		{"XN--D1ACPJX3F.xn--fsqu00a", "яндекс.例子"},
+		{"example.com", "example.com"},
+		// 无法解码的标签保持原样
+		{"xn--a-!.com", "xn--a-!.com"},
+	}
+
+	for _, tc := range testCases {
+		if result := ToUnicode(tc.input); result != tc.expected {
+			t.Errorf("ToUnicode(%q) = %q, expected %q", tc.input, result, tc.expected)
+		}
+	}
+}
+
+func TestSkeleton(t *testing.T) {
+	testCases := []struct {
+		a, b  string
+		equal bool
+	}{
+		{"paypal", "pаypal", true}, // 西里尔字母а
+		{"paypal", "paypa1", true},
+		{"microsoft", "rnicrosoft", true},
+		{"google", "g00gle", true},
+		{"apple", "äpple", true},
+		{"Amazon", "amazon", true},
+		{"paypal", "paypai", false},
+		{"github", "gitlab", false},
+	}
+
+	for _, tc := range testCases {
+		if equal := Skeleton(tc.a) == Skeleton(tc.b); equal != tc.equal {
+			t.Errorf("Skeleton(%q) == Skeleton(%q) is %v, expected %v", tc.a, tc.b, equal, tc.equal)
+		}
+	}
+}
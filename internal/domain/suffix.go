@@ -0,0 +1,27 @@
+package domain
+
+import "strings"
+
+// secondLevelSuffixes 国家顶级域名下常见的二级后缀，如example.co.uk的主域名标签是example
+var secondLevelSuffixes = map[string]bool{
+	"co": true, "com": true, "net": true, "org": true, "gov": true,
+	"edu": true, "ac": true, "or": true, "ne": true,
+}
+
+// SplitHost 把主机名拆成主域名标签和后缀，如login.example.co.uk拆为example和co.uk
+func SplitHost(host string) (string, string) {
+	labels := strings.Split(host, ".")
+	n := len(labels)
+	if n < 2 {
+		return host, ""
+	}
+	if n >= 3 && len(labels[n-1]) == 2 && secondLevelSuffixes[labels[n-2]] {
+		return labels[n-3], labels[n-2] + "." + labels[n-1]
+	}
+	return labels[n-2], labels[n-1]
+}
+
+// IsSuffixLabel 标签是顶级域名或常见的二级后缀，不能代表站点
+func IsSuffixLabel(label string) bool {
+	return secondLevelSuffixes[label]
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "testing"
+
+func TestSplitHost(t *testing.T) {
+	testCases := []struct {
+		host   string
+		label  string
+		suffix string
+	}{
+		{"example.com", "example", "com"},
+		{"login.example.co.uk", "example", "co.uk"},
+		{"netbank.mybank.com.au", "mybank", "com.au"},
+		{"bugzilla.mozilla.org", "mozilla", "org"},
+		{"localhost", "localhost", ""},
+	}
+
+	for _, tc := range testCases {
+		label, suffix := SplitHost(tc.host)
+		if label != tc.label || suffix != tc.suffix {
+			t.Errorf("SplitHost(%q) = %q, %q; expected %q, %q", tc.host, label, suffix, tc.label, tc.suffix)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package input
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"iter"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/yourorg/unpass/internal/types"
+)
+
+// Deduplicator 合并在多个来源中重复出现的凭据
+// 站点、用户名和密码都相同才视为重复；同一来源内的多个条目保持独立
+//
+// 每个输入只解析（解密）一次。启用合并时，Filter在输入读完前缓存凭据：
+// 每组重复凭据保留信息最完整的一条（例如带TOTP的），用其他副本补齐缺失的字段，并附上整组的来源
+type Deduplicator struct {
+	merge   bool
+	groups  map[[sha256.Size]byte][]*dedupGroup
+	usedIDs map[string]bool
+	merged  int
+}
+
+// dedupGroup 一组互相重复的凭据
+type dedupGroup struct {
+	sources []types.CredentialSource
+	kept    *dedupEntry // 保留的凭据
+}
+
+// dedupEntry 缓存中的凭据，被更完整的副本替换后标记为dropped
+type dedupEntry struct {
+	credential types.Credential
+	dropped    bool
+}
+
+func NewDeduplicator() *Deduplicator {
+	return &Deduplicator{
+		groups:  make(map[[sha256.Size]byte][]*dedupGroup),
+		usedIDs: make(map[string]bool),
+	}
+}
+
+// SetMerge 设置是否合并跨来源的重复凭据；只有一个输入时不需要缓存，凭据直接流式输出
+func (d *Deduplicator) SetMerge(merge bool) {
+	d.merge = merge
+}
+
+// Filter 输出去重后的凭据流
+// 不同来源的凭据ID冲突时追加序号，保证检测结果能对应到唯一凭据
+func (d *Deduplicator) Filter(credentials iter.Seq2[types.Credential, error]) iter.Seq2[types.Credential, error] {
+	return func(yield func(types.Credential, error) bool) {
+		var entries []*dedupEntry
+		for credential, err := range credentials {
+			if err != nil {
+				yield(types.Credential{}, err)
+				return
+			}
+			if !d.merge {
+				credential.ID = uniqueID(d.usedIDs, credential.ID)
+				if !yield(credential, nil) {
+					return
+				}
+				continue
+			}
+			if entry := d.add(credential); entry != nil {
+				entries = append(entries, entry)
+			}
+		}
+
+		for _, entry := range entries {
+			if entry.dropped {
+				continue
+			}
+			credential := entry.credential
+			credential.ID = uniqueID(d.usedIDs, credential.ID)
+			if !yield(credential, nil) {
+				return
+			}
+		}
+	}
+}
+
+// add 把凭据并入所属的重复分组；凭据需要输出时返回其缓存条目
+func (d *Deduplicator) add(credential types.Credential) *dedupEntry {
+	key := dedupKey(credential)
+	for _, group := range d.groups[key] {
+		if sharesSource(group.sources, credential.Sources) {
+			continue
+		}
+
+		group.sources = append(group.sources, credential.Sources...)
+		d.merged++
+
+		// 保留信息更完整的凭据，另一条只用于补齐缺失的字段
+		var entry *dedupEntry
+		if dedupScore(credential) > dedupScore(group.kept.credential) {
+			group.kept.dropped = true
+			fillMissing(&credential, group.kept.credential)
+			entry = &dedupEntry{credential: credential}
+			group.kept = entry
+		} else {
+			fillMissing(&group.kept.credential, credential)
+		}
+		group.kept.credential.Sources = orderSources(group.kept.credential.Sources, group.sources)
+		return entry
+	}
+
+	entry := &dedupEntry{credential: credential}
+	d.groups[key] = append(d.groups[key], &dedupGroup{sources: slices.Clone(credential.Sources), kept: entry})
+	return entry
+}
+
+// Merged 返回被合并的重复凭据数
+func (d *Deduplicator) Merged() int {
+	return d.merged
+}
+
+// fillMissing 用重复副本补齐保留凭据中为空的字段
+func fillMissing(kept *types.Credential, other types.Credential) {
+	if kept.TOTP == "" {
+		kept.TOTP = other.TOTP
+	}
+	if kept.Passkey == "" {
+		kept.Passkey = other.Passkey
+	}
+	if kept.Notes == "" {
+		kept.Notes = other.Notes
+	}
+	if kept.Category == "" {
+		kept.Category = other.Category
+	}
+}
+
+// orderSources 保留凭据自己的来源排在最前，其余来源按发现顺序排列
+func orderSources(own, all []types.CredentialSource) []types.CredentialSource {
+	ordered := slices.Clone(own)
+	for _, source := range all {
+		if !slices.Contains(ordered, source) {
+			ordered = append(ordered, source)
+		}
+	}
+	return ordered
+}
+
+// sharesSource 判断两组来源是否有相同的文件
+func sharesSource(a, b []types.CredentialSource) bool {
+	for _, sa := range a {
+		for _, sb := range b {
+			if sa.File == sb.File {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// dedupKey 由规范化的站点、用户名和密码计算去重键摘要，不保留明文密码
+func dedupKey(credential types.Credential) [sha256.Size]byte {
+	site := normalizeHost(credential.URL)
+	if site == "" {
+		site = strings.ToLower(strings.TrimSpace(credential.Title))
+	}
+	h := sha256.New()
+	h.Write([]byte(site))
+	h.Write([]byte{0})
+	h.Write([]byte(strings.ToLower(strings.TrimSpace(credential.Username))))
+	h.Write([]byte{0})
+	h.Write([]byte(credential.Password))
+
+	var key [sha256.Size]byte
+	h.Sum(key[:0])
+	return key
+}
+
+// dedupScore 凭据信息的完整程度：TOTP和Passkey影响检测结果，权重最高
+func dedupScore(credential types.Credential) int {
+	score := 0
+	if credential.TOTP != "" {
+		score += 4
+	}
+	if credential.Passkey != "" {
+		score += 4
+	}
+	if credential.Notes != "" {
+		score++
+	}
+	return score
+}
+
+// normalizeHost 提取小写主机名并去掉www前缀，忽略协议和路径的差异
+func normalizeHost(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return ""
+	}
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+}
+
+// uniqueID 为冲突的ID追加序号
+func uniqueID(used map[string]bool, id string) string {
+	candidate := id
+	for n := 2; used[candidate]; n++ {
+		candidate = fmt.Sprintf("%s-%d", id, n)
+	}
+	used[candidate] = true
+	return candidate
+}
@@ -0,0 +1,120 @@
+package input
+
+import (
+	"testing"
+
+	"github.com/yourorg/unpass/internal/parser"
+	"github.com/yourorg/unpass/internal/types"
+)
+
+func credentialSeq(credentials []types.Credential) func(yield func(types.Credential, error) bool) {
+	return func(yield func(types.Credential, error) bool) {
+		for _, credential := range credentials {
+			if !yield(credential, nil) {
+				return
+			}
+		}
+	}
+}
+
+func TestDeduplicator(t *testing.T) {
+	enpass := types.CredentialSource{File: "vault.json", Provider: "enpass"}
+	chrome := types.CredentialSource{File: "chrome.csv", Provider: "browser-csv"}
+
+	credentials := []types.Credential{
+		{ID: "1", Title: "github.com", URL: "https://www.github.com/login", Username: "Alice", Password: "pw", Sources: []types.CredentialSource{chrome}},
+		{ID: "1", Title: "example.com", URL: "https://example.com", Username: "alice", Password: "other", Sources: []types.CredentialSource{chrome}},
+		{ID: "e1", Title: "GitHub", URL: "https://github.com", Username: "alice", Password: "pw", TOTP: "otpauth://totp/x", Sources: []types.CredentialSource{enpass}},
+		{ID: "1", Title: "GitHub (work)", URL: "https://github.com", Username: "alice", Password: "pw", Sources: []types.CredentialSource{enpass}},
+	}
+
+	dedup := NewDeduplicator()
+	dedup.SetMerge(true)
+	result, err := parser.Collect(dedup.Filter(credentialSeq(credentials)))
+	if err != nil {
+		t.Fatalf("Filter failed: %v", err)
+	}
+
+	// 同一来源内的重复条目保持独立，跨来源的重复被合并
+	if dedup.Merged() != 1 {
+		t.Errorf("Expected 1 merged credential, got %d", dedup.Merged())
+	}
+	if len(result) != 3 {
+		t.Fatalf("Expected 3 credentials, got %d", len(result))
+	}
+
+	// 保留带TOTP的凭据，并附上两个来源
+	github := result[1]
+	if github.ID != "e1" || github.TOTP == "" {
+		t.Errorf("Expected credential with TOTP to be kept, got %+v", github)
+	}
+	if len(github.Sources) != 2 || github.Sources[0] != enpass || github.Sources[1] != chrome {
+		t.Errorf("Expected both sources on merged credential, got %v", github.Sources)
+	}
+
+	// 与已有ID冲突的凭据应获得新ID
+	if result[0].ID != "1" || result[2].ID != "1-2" {
+		t.Errorf("Expected IDs '1' and '1-2', got '%s' and '%s'", result[0].ID, result[2].ID)
+	}
+}
+
+// 保留的凭据用其他来源的副本补齐缺失的字段
+func TestDeduplicator_FillMissing(t *testing.T) {
+	enpass := types.CredentialSource{File: "vault.json", Provider: "enpass"}
+	chrome := types.CredentialSource{File: "chrome.csv", Provider: "browser-csv"}
+	keepass := types.CredentialSource{File: "vault.kdbx", Provider: "keepass"}
+
+	credentials := []types.Credential{
+		{ID: "1", Title: "GitHub", URL: "https://github.com", Username: "alice", Password: "pw", Notes: "recovery email", Sources: []types.CredentialSource{chrome}},
+		{ID: "e1", Title: "GitHub", URL: "https://github.com", Username: "alice", Password: "pw", TOTP: "otpauth://totp/x", Sources: []types.CredentialSource{enpass}},
+		{ID: "k1", Title: "GitHub", URL: "https://github.com", Username: "alice", Password: "pw", Passkey: "github.com", Sources: []types.CredentialSource{keepass}},
+	}
+
+	dedup := NewDeduplicator()
+	dedup.SetMerge(true)
+	result, err := parser.Collect(dedup.Filter(credentialSeq(credentials)))
+	if err != nil {
+		t.Fatalf("Filter failed: %v", err)
+	}
+	if len(result) != 1 || dedup.Merged() != 2 {
+		t.Fatalf("Expected 1 credential with 2 merged, got %d (%d merged)", len(result), dedup.Merged())
+	}
+
+	github := result[0]
+	if github.ID != "e1" || github.TOTP == "" || github.Passkey != "github.com" || github.Notes != "recovery email" {
+		t.Errorf("Expected the TOTP credential completed from the other copies, got %+v", github)
+	}
+	if len(github.Sources) != 3 || github.Sources[0] != enpass || github.Sources[1] != chrome || github.Sources[2] != keepass {
+		t.Errorf("Expected all sources on merged credential, got %v", github.Sources)
+	}
+}
+
+func TestDeduplicator_WithoutMerge(t *testing.T) {
+	credentials := []types.Credential{
+		{ID: "1", Title: "A", Username: "a", Password: "pw"},
+		{ID: "1", Title: "A", Username: "a", Password: "pw"},
+	}
+
+	result, err := parser.Collect(NewDeduplicator().Filter(credentialSeq(credentials)))
+	if err != nil {
+		t.Fatalf("Filter failed: %v", err)
+	}
+	if len(result) != 2 || result[1].ID != "1-2" {
+		t.Errorf("Expected both credentials with unique IDs, got %+v", result)
+	}
+}
+
+func TestNormalizeHost(t *testing.T) {
+	testCases := map[string]string{
+		"https://www.GitHub.com/login": "github.com",
+		"github.com":                   "github.com",
+		"http://192.168.1.1:8080/":     "192.168.1.1",
+		"":                             "",
+	}
+
+	for rawURL, expected := range testCases {
+		if got := normalizeHost(rawURL); got != expected {
+			t.Errorf("normalizeHost(%q) = %q, expected %q", rawURL, got, expected)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package input
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// StdinPath 表示从标准输入读取
+const StdinPath = "-"
+
+// Input 待解析的输入来源
+type Input struct {
+	Path string
+	// FromDirectory 由目录展开得到的文件，格式无法识别时跳过而不是报错
+	FromDirectory bool
+	// Directory Path是由目录解析器整体读取的目录（如浏览器配置目录）
+	Directory bool
+}
+
+// Expand 将命令行给出的路径展开为输入列表，支持glob、目录和"-"（标准输入）
+// 目录只展开第一层的普通文件，跳过隐藏文件；同一文件只保留一次
+func Expand(patterns []string) ([]Input, error) {
+	return expand(patterns, nil)
+}
+
+// expand isSource非nil且识别出目录本身是数据源时，目录作为一个输入而不再展开
+func expand(patterns []string, isSource func(dir string) bool) ([]Input, error) {
+	var inputs []Input
+	seen := make(map[string]bool)
+
+	add := func(input Input) {
+		key := input.Path
+		if key != StdinPath {
+			key = filepath.Clean(key)
+		}
+		if !seen[key] {
+			seen[key] = true
+			inputs = append(inputs, input)
+		}
+	}
+
+	for _, pattern := range patterns {
+		if pattern == StdinPath {
+			if seen[StdinPath] {
+				return nil, errors.New("stdin can only be used once")
+			}
+			add(Input{Path: StdinPath})
+			continue
+		}
+
+		paths := []string{pattern}
+		if strings.ContainsAny(pattern, "*?[") {
+			matches, err := filepath.Glob(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %s: %w", pattern, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no files match %s", pattern)
+			}
+			paths = matches
+		}
+
+		for _, path := range paths {
+			info, err := os.Stat(path)
+			if err != nil {
+				return nil, err
+			}
+			if !info.IsDir() {
+				add(Input{Path: path})
+				continue
+			}
+			if isSource != nil && isSource(path) {
+				add(Input{Path: path, Directory: true})
+				continue
+			}
+
+			files, err := expandDirectory(path)
+			if err != nil {
+				return nil, err
+			}
+			for _, file := range files {
+				add(Input{Path: file, FromDirectory: true})
+			}
+		}
+	}
+
+	return inputs, nil
+}
+
+// expandDirectory 列出目录中的普通文件（按名称排序）
+func expandDirectory(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		files = append(files, filepath.Join(dir, entry.Name()))
+	}
+	return files, nil
+}
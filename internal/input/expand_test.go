@@ -0,0 +1,63 @@
+package input
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("[]"), 0600); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, "a.json", "b.csv", ".hidden")
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0700); err != nil {
+		t.Fatalf("Mkdir failed: %v", err)
+	}
+	writeFiles(t, filepath.Join(dir, "sub"), "c.json")
+
+	inputs, err := Expand([]string{
+		filepath.Join(dir, "*.json"),
+		dir,
+		"-",
+	})
+	if err != nil {
+		t.Fatalf("Expand failed: %v", err)
+	}
+
+	var paths []string
+	for _, in := range inputs {
+		paths = append(paths, filepath.Base(in.Path))
+	}
+
+	// a.json只出现一次，隐藏文件和子目录被跳过
+	expected := []string{"a.json", "b.csv", "-"}
+	if strings.Join(paths, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, paths)
+	}
+	if inputs[0].FromDirectory || !inputs[1].FromDirectory {
+		t.Errorf("Unexpected FromDirectory flags: %+v", inputs)
+	}
+}
+
+func TestExpand_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := Expand([]string{filepath.Join(dir, "*.kdbx")}); err == nil {
+		t.Error("Expected error for glob without matches")
+	}
+	if _, err := Expand([]string{filepath.Join(dir, "missing.json")}); err == nil {
+		t.Error("Expected error for missing file")
+	}
+	if _, err := Expand([]string{"-", "-"}); err == nil {
+		t.Error("Expected error for repeated stdin")
+	}
+}
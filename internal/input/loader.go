@@ -0,0 +1,206 @@
+package input
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"strings"
+
+	"github.com/yourorg/unpass/internal/parser"
+	"github.com/yourorg/unpass/internal/types"
+)
+
+// stdinSourceName 标准输入在报告中的来源名称
+const stdinSourceName = "stdin"
+
+// Progress 单个输入处理完毕时的进度
+type Progress struct {
+	Source      types.CredentialSource
+	Credentials int
+	// Skipped 非nil表示该输入由目录展开得到且格式无法识别，已跳过
+	Skipped error
+}
+
+// Loader 识别输入格式并以流的形式解析凭据
+type Loader struct {
+	registry  *parser.Registry
+	format    string // 非空时跳过内容识别，直接使用该解析器
+	stdin     io.Reader
+	stdinData []byte // 标准输入只能读取一次，缓存以便多次遍历
+	progress  func(Progress)
+}
+
+func NewLoader(registry *parser.Registry, format string) *Loader {
+	return &Loader{
+		registry: registry,
+		format:   format,
+		stdin:    os.Stdin,
+	}
+}
+
+// SetStdin 设置"-"对应的输入流
+func (l *Loader) SetStdin(stdin io.Reader) {
+	l.stdin = stdin
+}
+
+// SetProgressFunc 设置每个输入处理完毕后的回调
+func (l *Loader) SetProgressFunc(fn func(Progress)) {
+	l.progress = fn
+}
+
+// Close 清除缓存的标准输入内容
+func (l *Loader) Close() {
+	clear(l.stdinData)
+	l.stdinData = nil
+}
+
+// Expand 展开输入路径，能被目录解析器识别的目录整体作为一个输入
+func (l *Loader) Expand(patterns []string) ([]Input, error) {
+	return expand(patterns, func(dir string) bool {
+		_, ok := l.selectDirectoryParser(dir)
+		return ok
+	})
+}
+
+// Stream 依次解析所有输入，逐条产生凭据并记录来源
+// 可以多次遍历，每次都会重新读取输入
+func (l *Loader) Stream(inputs []Input) iter.Seq2[types.Credential, error] {
+	return func(yield func(types.Credential, error) bool) {
+		for _, in := range inputs {
+			if !l.streamInput(in, yield) {
+				return
+			}
+		}
+	}
+}
+
+// streamInput 解析单个输入，返回false表示遍历应当结束
+func (l *Loader) streamInput(in Input, yield func(types.Credential, error) bool) bool {
+	if in.Directory {
+		return l.streamDirectory(in, yield)
+	}
+
+	reader, name, closeFn, err := l.open(in.Path)
+	if err != nil {
+		yield(types.Credential{}, fmt.Errorf("%s: %w", in.Path, err))
+		return false
+	}
+	defer closeFn()
+
+	formatParser, err := l.selectParser(reader)
+	if err != nil {
+		// 目录中无法识别的文件跳过
+		var detectionErr *parser.DetectionError
+		if in.FromDirectory && errors.As(err, &detectionErr) {
+			l.report(Progress{Source: types.CredentialSource{File: name}, Skipped: err})
+			return true
+		}
+		yield(types.Credential{}, fmt.Errorf("%s: %w", in.Path, err))
+		return false
+	}
+
+	source := types.CredentialSource{File: name, Provider: formatParser.Name()}
+	count := 0
+	for credential, err := range parser.Stream(formatParser, reader) {
+		if err != nil {
+			yield(types.Credential{}, fmt.Errorf("%s: %w", in.Path, err))
+			return false
+		}
+		credential.Sources = []types.CredentialSource{source}
+		count++
+		if !yield(credential, nil) {
+			return false
+		}
+	}
+
+	l.report(Progress{Source: source, Credentials: count})
+	return true
+}
+
+// streamDirectory 用目录解析器整体读取目录
+func (l *Loader) streamDirectory(in Input, yield func(types.Credential, error) bool) bool {
+	directoryParser, ok := l.selectDirectoryParser(in.Path)
+	if !ok {
+		yield(types.Credential{}, fmt.Errorf("%s: no parser recognizes this directory", in.Path))
+		return false
+	}
+
+	credentials, err := directoryParser.ParseDirectory(in.Path)
+	if err != nil {
+		yield(types.Credential{}, fmt.Errorf("%s: %w", in.Path, err))
+		return false
+	}
+
+	source := types.CredentialSource{File: in.Path, Provider: directoryParser.Name()}
+	for _, credential := range credentials {
+		credential.Sources = []types.CredentialSource{source}
+		if !yield(credential, nil) {
+			return false
+		}
+	}
+
+	l.report(Progress{Source: source, Credentials: len(credentials)})
+	return true
+}
+
+func (l *Loader) report(progress Progress) {
+	if l.progress != nil {
+		l.progress(progress)
+	}
+}
+
+// open 打开输入；标准输入需要读入内存以支持格式识别时的回退
+func (l *Loader) open(path string) (io.ReadSeeker, string, func(), error) {
+	if path == StdinPath {
+		if l.stdinData == nil {
+			data, err := io.ReadAll(l.stdin)
+			if err != nil {
+				return nil, "", nil, fmt.Errorf("failed to read stdin: %w", err)
+			}
+			l.stdinData = data
+		}
+		return bytes.NewReader(l.stdinData), stdinSourceName, func() {}, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return file, path, func() { file.Close() }, nil
+}
+
+// selectParser 指定了输入格式时直接使用，否则按内容识别
+func (l *Loader) selectParser(reader io.ReadSeeker) (parser.Parser, error) {
+	if l.format == "" {
+		return l.registry.Detect(reader)
+	}
+
+	formatParser, exists := l.registry.Get(l.format)
+	if !exists {
+		return nil, fmt.Errorf("unknown input format: %s (supported: %s)", l.format, strings.Join(l.registry.Names(), ", "))
+	}
+	return formatParser, nil
+}
+
+// selectDirectoryParser 指定了输入格式时只使用该解析器识别目录
+func (l *Loader) selectDirectoryParser(dir string) (parser.DirectoryParser, bool) {
+	if l.format == "" {
+		return l.registry.DetectDirectory(dir)
+	}
+
+	formatParser, exists := l.registry.Get(l.format)
+	if !exists {
+		return nil, false
+	}
+	directoryParser, ok := formatParser.(parser.DirectoryParser)
+	if !ok {
+		return nil, false
+	}
+	if score, _ := directoryParser.DetectDirectory(dir); score <= parser.ConfidenceNone {
+		return nil, false
+	}
+	return directoryParser, true
+}
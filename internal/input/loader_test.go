@@ -0,0 +1,133 @@
+package input
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/yourorg/unpass/internal/parser"
+	"github.com/yourorg/unpass/internal/types"
+)
+
+func TestLoader_Stream(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not a vault"), 0600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	registry := parser.NewRegistry()
+	registry.Register("json", parser.NewJSONParser())
+
+	loader := NewLoader(registry, "")
+	loader.SetStdin(strings.NewReader(`[{"id": "1", "title": "GitHub", "username": "alice", "password": "pw"}]`))
+	defer loader.Close()
+
+	var progress []Progress
+	loader.SetProgressFunc(func(p Progress) {
+		progress = append(progress, p)
+	})
+
+	inputs := []Input{
+		{Path: StdinPath},
+		{Path: filepath.Join(dir, "notes.txt"), FromDirectory: true},
+	}
+
+	// 标准输入被缓存，可以遍历多次
+	for pass := 0; pass < 2; pass++ {
+		credentials, err := parser.Collect(loader.Stream(inputs))
+		if err != nil {
+			t.Fatalf("Stream failed: %v", err)
+		}
+		expected := types.CredentialSource{File: "stdin", Provider: "json"}
+		if len(credentials) != 1 || len(credentials[0].Sources) != 1 || credentials[0].Sources[0] != expected {
+			t.Errorf("Expected credential to carry its source, got %+v", credentials)
+		}
+	}
+
+	if len(progress) != 4 || progress[0].Credentials != 1 || progress[1].Skipped == nil {
+		t.Errorf("Unexpected progress events: %+v", progress)
+	}
+}
+
+func TestLoader_StreamUnrecognizedFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(path, []byte("not a vault"), 0600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	registry := parser.NewRegistry()
+	registry.Register("json", parser.NewJSONParser())
+
+	// 明确指定的文件无法识别时应报错
+	_, err := parser.Collect(NewLoader(registry, "").Stream([]Input{{Path: path}}))
+	if err == nil || !strings.Contains(err.Error(), path) {
+		t.Errorf("Expected error mentioning %s, got %v", path, err)
+	}
+}
+
+// markerParser 包含marker文件的目录作为整体解析的测试解析器
+type markerParser struct{}
+
+func (markerParser) Name() string                                { return "marker" }
+func (markerParser) Parse(io.Reader) ([]types.Credential, error) { return nil, nil }
+func (markerParser) SupportedFormats() []string                  { return []string{"marker"} }
+func (markerParser) Detect(io.ReadSeeker) (float64, error)       { return parser.ConfidenceNone, nil }
+func (markerParser) ParseDirectory(string) ([]types.Credential, error) {
+	return []types.Credential{{ID: "1", Title: "GitHub", Password: "pw"}}, nil
+}
+func (markerParser) DetectDirectory(dir string) (float64, error) {
+	if _, err := os.Stat(filepath.Join(dir, "marker")); err != nil {
+		return parser.ConfidenceNone, err
+	}
+	return parser.ConfidenceCertain, nil
+}
+
+func TestLoader_ExpandDirectorySource(t *testing.T) {
+	root := t.TempDir()
+	profile := filepath.Join(root, "profile")
+	if err := os.Mkdir(profile, 0700); err != nil {
+		t.Fatalf("Mkdir failed: %v", err)
+	}
+	writeFiles(t, profile, "marker", "other.json")
+	writeFiles(t, root, "vault.json")
+
+	registry := parser.NewRegistry()
+	registry.Register("json", parser.NewJSONParser())
+	registry.Register("marker", markerParser{})
+	loader := NewLoader(registry, "")
+
+	// 被识别的目录作为一个输入，其中的文件不再展开
+	inputs, err := loader.Expand([]string{profile, root})
+	if err != nil {
+		t.Fatalf("Expand failed: %v", err)
+	}
+	expected := []Input{
+		{Path: profile, Directory: true},
+		{Path: filepath.Join(root, "vault.json"), FromDirectory: true},
+	}
+	if !slices.Equal(inputs, expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, inputs)
+	}
+
+	credentials, err := parser.Collect(loader.Stream(inputs[:1]))
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+	source := types.CredentialSource{File: profile, Provider: "marker"}
+	if len(credentials) != 1 || credentials[0].Sources[0] != source {
+		t.Errorf("Expected directory credential with its source, got %+v", credentials)
+	}
+
+	// 指定的输入格式不支持目录时按普通目录展开
+	inputs, err = NewLoader(registry, "json").Expand([]string{profile})
+	if err != nil {
+		t.Fatalf("Expand failed: %v", err)
+	}
+	if len(inputs) != 2 || inputs[0].Directory {
+		t.Errorf("Expected directory files to be expanded, got %+v", inputs)
+	}
+}
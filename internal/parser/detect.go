@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// 格式识别置信度
+const (
+	ConfidenceNone    = 0.0
+	ConfidenceLow     = 0.3 // 结构兼容，但没有可区分的特征
+	ConfidenceMedium  = 0.6 // 包含该格式常见的字段
+	ConfidenceHigh    = 0.9 // 包含该格式独有的字段
+	ConfidenceCertain = 1.0 // 文件签名匹配
+)
+
+// Rejection 解析器拒绝输入的原因
+type Rejection struct {
+	Parser string
+	Reason string
+}
+
+// DetectionError 没有解析器能识别输入时返回，列出每个解析器的拒绝原因
+type DetectionError struct {
+	Rejections []Rejection
+}
+
+func (e *DetectionError) Error() string {
+	var b strings.Builder
+	b.WriteString("unable to detect input format")
+	for _, rejection := range e.Rejections {
+		fmt.Fprintf(&b, "\n  %s: %s", rejection.Parser, rejection.Reason)
+	}
+	return b.String()
+}
+
+// Detect 让每个已注册的解析器识别输入，返回置信度最高的解析器
+// 返回前输入会被重置到开头
+func (r *Registry) Detect(reader io.ReadSeeker) (Parser, error) {
+	var (
+		best       Parser
+		bestScore  float64
+		rejections []Rejection
+	)
+
+	for _, name := range r.order {
+		if _, err := reader.Seek(0, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("failed to rewind input: %w", err)
+		}
+
+		score, err := r.parsers[name].Detect(reader)
+		if score <= ConfidenceNone {
+			reason := "format not recognized"
+			if err != nil {
+				reason = err.Error()
+			}
+			rejections = append(rejections, Rejection{Parser: name, Reason: reason})
+			continue
+		}
+
+		// 置信度相同时保留先注册的解析器
+		if score > bestScore {
+			best, bestScore = r.parsers[name], score
+		}
+	}
+
+	if _, err := reader.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to rewind input: %w", err)
+	}
+
+	if best == nil {
+		return nil, &DetectionError{Rejections: rejections}
+	}
+	return best, nil
+}
+
+// Names 按注册顺序返回解析器名称
+func (r *Registry) Names() []string {
+	return append([]string(nil), r.order...)
+}
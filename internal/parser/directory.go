@@ -0,0 +1,35 @@
+package parser
+
+import "github.com/yourorg/unpass/internal/types"
+
+// DirectoryParser 读取由多个文件组成的数据源（如浏览器配置目录）的解析器
+// 目录被识别后作为一个整体解析，不再逐个文件识别格式
+type DirectoryParser interface {
+	Parser
+	// DetectDirectory 判断目录是否为该解析器的数据源，返回0~1的置信度
+	DetectDirectory(dir string) (float64, error)
+	ParseDirectory(dir string) ([]types.Credential, error)
+}
+
+// DetectDirectory 返回能识别该目录的解析器中置信度最高的一个
+// 没有解析器能识别时返回false，目录按普通文件逐个展开
+func (r *Registry) DetectDirectory(dir string) (DirectoryParser, bool) {
+	var (
+		best      DirectoryParser
+		bestScore float64
+	)
+
+	for _, name := range r.order {
+		directoryParser, ok := r.parsers[name].(DirectoryParser)
+		if !ok {
+			continue
+		}
+
+		// 置信度相同时保留先注册的解析器
+		if score, _ := directoryParser.DetectDirectory(dir); score > bestScore {
+			best, bestScore = directoryParser, score
+		}
+	}
+
+	return best, best != nil
+}
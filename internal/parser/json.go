@@ -2,7 +2,9 @@ package parser
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
+	"iter"
 	"github.com/yourorg/unpass/internal/types"
 )
 
@@ -17,16 +19,48 @@ func (p *JSONParser) Name() string {
 }
 
 func (p *JSONParser) Parse(reader io.Reader) ([]types.Credential, error) {
-	var credentials []types.Credential
-	decoder := json.NewDecoder(reader)
-	
-	if err := decoder.Decode(&credentials); err != nil {
-		return nil, err
+	return Collect(p.Stream(reader))
+}
+
+// Stream 逐个解码数组中的凭据
+func (p *JSONParser) Stream(reader io.Reader) iter.Seq2[types.Credential, error] {
+	return func(yield func(types.Credential, error) bool) {
+		decoder := json.NewDecoder(reader)
+		err := WalkJSONArray(decoder, func() (bool, error) {
+			var credential types.Credential
+			if err := decoder.Decode(&credential); err != nil {
+				return false, err
+			}
+			return yield(credential, nil), nil
+		})
+		if err != nil {
+			yield(types.Credential{}, err)
+		}
 	}
-	
-	return credentials, nil
 }
 
 func (p *JSONParser) SupportedFormats() []string {
 	return []string{"json"}
-} 
\ No newline at end of file
+}
+
+// Detect 通用格式为凭据对象数组，只检查第一个元素
+func (p *JSONParser) Detect(reader io.ReadSeeker) (float64, error) {
+	decoder := json.NewDecoder(reader)
+	if err := ExpectJSONDelim(decoder, '['); err != nil {
+		return ConfidenceNone, err
+	}
+	if !decoder.More() {
+		return ConfidenceLow, nil
+	}
+
+	var first map[string]json.RawMessage
+	if err := DecodeJSONProbe(decoder, &first); err != nil {
+		return ConfidenceNone, err
+	}
+	for _, key := range []string{"username", "password", "url"} {
+		if _, exists := first[key]; exists {
+			return ConfidenceMedium, nil
+		}
+	}
+	return ConfidenceNone, errors.New("array elements have no username, password or url field")
+}
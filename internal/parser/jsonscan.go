@@ -0,0 +1,143 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// 以下辅助函数按token读取JSON，格式识别和流式解析都不需要把整个文档读入内存
+
+// ExpectJSONDelim 读取下一个token并确认是指定的分隔符
+func ExpectJSONDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return jsonSyntaxError(err)
+	}
+	if token != delim {
+		switch delim {
+		case '[':
+			return errors.New("top-level JSON value is not an array")
+		case '{':
+			return errors.New("top-level JSON value is not an object")
+		}
+		return fmt.Errorf("expected JSON %s", delim)
+	}
+	return nil
+}
+
+// WalkJSONObject 逐个访问对象的键，visit必须读取或跳过对应的值
+// visit返回false时停止遍历，剩余内容不再读取
+func WalkJSONObject(decoder *json.Decoder, visit func(key string) (bool, error)) error {
+	if err := ExpectJSONDelim(decoder, '{'); err != nil {
+		return err
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return jsonSyntaxError(err)
+		}
+		key, _ := token.(string)
+
+		next, err := visit(key)
+		if err != nil || !next {
+			return err
+		}
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return jsonSyntaxError(err)
+	}
+	return nil
+}
+
+// WalkJSONArray 逐个访问数组元素，visit必须读取或跳过当前元素；值为null时视为空数组
+func WalkJSONArray(decoder *json.Decoder, visit func() (bool, error)) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return jsonSyntaxError(err)
+	}
+	if token == nil {
+		return nil
+	}
+	if token != json.Delim('[') {
+		return errors.New("expected JSON array")
+	}
+
+	for decoder.More() {
+		next, err := visit()
+		if err != nil || !next {
+			return err
+		}
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return jsonSyntaxError(err)
+	}
+	return nil
+}
+
+// SkipJSONValue 跳过下一个值，嵌套结构按token逐个丢弃
+func SkipJSONValue(decoder *json.Decoder) error {
+	depth := 0
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return jsonSyntaxError(err)
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// FindJSONArrayObjectKey 读取数组的第一个对象，返回其中最先出现的指定键
+// 只按token读取到找到的键为止，不会把整个对象读入内存；empty表示数组为空
+func FindJSONArrayObjectKey(decoder *json.Decoder, keys ...string) (found string, empty bool, err error) {
+	empty = true
+	err = WalkJSONArray(decoder, func() (bool, error) {
+		empty = false
+		return false, WalkJSONObject(decoder, func(key string) (bool, error) {
+			if slices.Contains(keys, key) {
+				found = key
+				return false, nil
+			}
+			return true, SkipJSONValue(decoder)
+		})
+	})
+	return found, empty, err
+}
+
+// DecodeJSONProbe 将下一个值解码到探测结构，返回的错误可直接作为拒绝原因
+func DecodeJSONProbe(decoder *json.Decoder, probe any) error {
+	err := decoder.Decode(probe)
+	if err == nil {
+		return nil
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		if typeErr.Field == "" {
+			return fmt.Errorf("unexpected JSON %s", typeErr.Value)
+		}
+		return fmt.Errorf("unexpected JSON %s in field %q", typeErr.Value, typeErr.Field)
+	}
+	return jsonSyntaxError(err)
+}
+
+// jsonSyntaxError 统一JSON语法错误的描述
+func jsonSyntaxError(err error) error {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return fmt.Errorf("not valid JSON: %w", err)
+}
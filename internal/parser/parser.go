@@ -9,10 +9,14 @@ type Parser interface {
 	Name() string
 	Parse(reader io.Reader) ([]types.Credential, error)
 	SupportedFormats() []string
+	// Detect 根据内容判断输入是否为该解析器的格式，返回0~1的置信度
+	// 置信度为0时error说明拒绝的原因
+	Detect(reader io.ReadSeeker) (float64, error)
 }
 
 type Registry struct {
 	parsers map[string]Parser
+	order   []string // 注册顺序，保证识别结果稳定
 }
 
 func NewRegistry() *Registry {
@@ -22,6 +26,9 @@ func NewRegistry() *Registry {
 }
 
 func (r *Registry) Register(name string, parser Parser) {
+	if _, exists := r.parsers[name]; !exists {
+		r.order = append(r.order, name)
+	}
 	r.parsers[name] = parser
 }
 
@@ -0,0 +1,10 @@
+package parser
+
+import "errors"
+
+// ErrPassphraseRequired 加密的导出文件需要口令但未提供口令来源
+var ErrPassphraseRequired = errors.New("passphrase required to decrypt export")
+
+// PassphraseFunc 按需获取解密口令，prompt用于交互式提示
+// 返回的切片由调用方在使用完毕后清零
+type PassphraseFunc func(prompt string) ([]byte, error)
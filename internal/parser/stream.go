@@ -0,0 +1,47 @@
+package parser
+
+import (
+	"io"
+	"iter"
+
+	"github.com/yourorg/unpass/internal/types"
+)
+
+// StreamParser 支持逐条解码的解析器，不需要将整个导出文件读入内存
+type StreamParser interface {
+	Parser
+	Stream(reader io.Reader) iter.Seq2[types.Credential, error]
+}
+
+// Stream 返回解析器的凭据流；不支持流式解析的解析器退化为一次性解析
+// 流在产生错误后结束
+func Stream(parser Parser, reader io.Reader) iter.Seq2[types.Credential, error] {
+	if streamParser, ok := parser.(StreamParser); ok {
+		return streamParser.Stream(reader)
+	}
+
+	return func(yield func(types.Credential, error) bool) {
+		credentials, err := parser.Parse(reader)
+		if err != nil {
+			yield(types.Credential{}, err)
+			return
+		}
+		for _, credential := range credentials {
+			if !yield(credential, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Collect 将凭据流收集为切片，遇到错误立即返回
+func Collect(credentials iter.Seq2[types.Credential, error]) ([]types.Credential, error) {
+	var result []types.Credential
+	for credential, err := range credentials {
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, credential)
+	}
+	return result, nil
+}
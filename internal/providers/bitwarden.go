@@ -1 +1,317 @@
-package providers
\ No newline at end of file
+package providers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/yourorg/unpass/internal/parser"
+	"github.com/yourorg/unpass/internal/types"
+)
+
+// BitwardenItemType Bitwarden项目类型枚举
+type BitwardenItemType int
+
+const (
+	ItemTypeLogin      BitwardenItemType = 1
+	ItemTypeSecureNote BitwardenItemType = 2
+	ItemTypeCard       BitwardenItemType = 3
+	ItemTypeIdentity   BitwardenItemType = 4
+	ItemTypeSSHKey     BitwardenItemType = 5
+)
+
+// BitwardenFieldType Bitwarden自定义字段类型枚举
+type BitwardenFieldType int
+
+const (
+	BitwardenFieldText    BitwardenFieldType = 0
+	BitwardenFieldHidden  BitwardenFieldType = 1
+	BitwardenFieldBoolean BitwardenFieldType = 2
+	BitwardenFieldLinked  BitwardenFieldType = 3
+)
+
+// BitwardenData Bitwarden/Vaultwarden导出的原始数据结构
+// 口令保护的导出文件只包含加密头部，明文数据解密后仍为此结构
+type BitwardenData struct {
+	Encrypted bool              `json:"encrypted"`
+	Folders   []BitwardenFolder `json:"folders"`
+	Items     []BitwardenItem   `json:"items"`
+	BitwardenEncryption
+}
+
+type BitwardenFolder struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type BitwardenItem struct {
+	ID          string            `json:"id"`
+	FolderID    *string           `json:"folderId"`
+	Type        BitwardenItemType `json:"type"`
+	Name        string            `json:"name"`
+	Notes       *string           `json:"notes"`
+	Fields      []BitwardenField  `json:"fields"`
+	Login       *BitwardenLogin   `json:"login"`
+	DeletedDate *string           `json:"deletedDate"`
+}
+
+type BitwardenField struct {
+	Name  string             `json:"name"`
+	Value *string            `json:"value"`
+	Type  BitwardenFieldType `json:"type"`
+}
+
+type BitwardenLogin struct {
+	URIs             []BitwardenURI             `json:"uris"`
+	Username         *string                    `json:"username"`
+	Password         *string                    `json:"password"`
+	TOTP             *string                    `json:"totp"`
+	FIDO2Credentials []BitwardenFIDO2Credential `json:"fido2Credentials"`
+}
+
+type BitwardenURI struct {
+	URI   *string `json:"uri"`
+	Match *int    `json:"match"`
+}
+
+type BitwardenFIDO2Credential struct {
+	CredentialID string `json:"credentialId"`
+	RpID         string `json:"rpId"`
+	RpName       string `json:"rpName"`
+	UserName     string `json:"userName"`
+	CreationDate string `json:"creationDate"`
+}
+
+// BitwardenParser Bitwarden数据解析器
+type BitwardenParser struct {
+	passphrase parser.PassphraseFunc
+}
+
+func NewBitwardenParser() *BitwardenParser {
+	return &BitwardenParser{}
+}
+
+// SetPassphraseFunc 设置口令保护导出文件的口令来源
+func (p *BitwardenParser) SetPassphraseFunc(fn parser.PassphraseFunc) {
+	p.passphrase = fn
+}
+
+func (p *BitwardenParser) Name() string {
+	return "bitwarden"
+}
+
+func (p *BitwardenParser) Parse(reader io.Reader) ([]types.Credential, error) {
+	var bitwardenData BitwardenData
+	decoder := json.NewDecoder(reader)
+
+	if err := decoder.Decode(&bitwardenData); err != nil {
+		return nil, fmt.Errorf("failed to decode Bitwarden JSON: %w", err)
+	}
+
+	if bitwardenData.Encrypted {
+		decrypted, err := p.decrypt(bitwardenData.BitwardenEncryption)
+		if err != nil {
+			return nil, err
+		}
+		bitwardenData = *decrypted
+	}
+
+	return p.extractCredentials(bitwardenData), nil
+}
+
+// decrypt 解密口令保护的导出文件，明文只存在于内存中
+func (p *BitwardenParser) decrypt(enc BitwardenEncryption) (*BitwardenData, error) {
+	// 账户密钥加密的导出只能在Bitwarden客户端内解密
+	if !enc.PasswordProtected {
+		return nil, fmt.Errorf("account-restricted encrypted Bitwarden export cannot be decrypted; export with a file password instead")
+	}
+	if p.passphrase == nil {
+		return nil, parser.ErrPassphraseRequired
+	}
+
+	passphrase, err := p.passphrase("Bitwarden export password: ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	defer clear(passphrase)
+
+	plaintext, err := decryptBitwardenExport(enc, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(plaintext)
+
+	var decrypted BitwardenData
+	if err := json.Unmarshal(plaintext, &decrypted); err != nil {
+		return nil, fmt.Errorf("failed to decode decrypted Bitwarden JSON: %w", err)
+	}
+	if decrypted.Encrypted {
+		return nil, fmt.Errorf("decrypted Bitwarden export is still marked as encrypted")
+	}
+
+	return &decrypted, nil
+}
+
+func (p *BitwardenParser) SupportedFormats() []string {
+	return []string{"bitwarden"}
+}
+
+// Detect Bitwarden导出以encrypted标记开头，items带有数字类型
+func (p *BitwardenParser) Detect(reader io.ReadSeeker) (float64, error) {
+	decoder := json.NewDecoder(reader)
+	confidence := parser.ConfidenceNone
+	reason := errors.New("missing Bitwarden encrypted flag and typed items")
+
+	err := parser.WalkJSONObject(decoder, func(key string) (bool, error) {
+		switch key {
+		case "encrypted":
+			confidence, reason = parser.ConfidenceHigh, nil
+			return false, nil
+		case "items":
+			var first struct {
+				Type *BitwardenItemType `json:"type"`
+			}
+			if err := parser.ExpectJSONDelim(decoder, '['); err != nil || !decoder.More() {
+				return false, nil
+			}
+			if err := parser.DecodeJSONProbe(decoder, &first); err != nil {
+				return false, err
+			}
+			if first.Type != nil {
+				confidence, reason = parser.ConfidenceMedium, nil
+			}
+			return false, nil
+		}
+		return true, parser.SkipJSONValue(decoder)
+	})
+	if err != nil {
+		return parser.ConfidenceNone, err
+	}
+	return confidence, reason
+}
+
+// extractCredentials 将Bitwarden导出数据映射为凭据列表
+func (p *BitwardenParser) extractCredentials(data BitwardenData) []types.Credential {
+	// 文件夹ID到名称的映射
+	folders := make(map[string]string, len(data.Folders))
+	for _, folder := range data.Folders {
+		folders[folder.ID] = folder.Name
+	}
+
+	var credentials []types.Credential
+
+	for _, item := range data.Items {
+		// 跳过不符合条件的数据
+		if !p.shouldProcessItem(item) {
+			continue
+		}
+
+		// 提取字段数据
+		credential := p.extractCredential(item, folders)
+
+		// 验证必要字段
+		if isValidCredential(credential) {
+			credentials = append(credentials, credential)
+		}
+	}
+
+	return credentials
+}
+
+// shouldProcessItem 判断是否应该处理该项目
+func (p *BitwardenParser) shouldProcessItem(item BitwardenItem) bool {
+	// 跳过已删除（回收站中）的数据
+	if item.DeletedDate != nil && *item.DeletedDate != "" {
+		return false
+	}
+
+	// 只处理login类型的数据
+	if item.Type != ItemTypeLogin || item.Login == nil {
+		return false
+	}
+
+	return true
+}
+
+// extractCredential 从Bitwarden项目中提取凭据信息
+func (p *BitwardenParser) extractCredential(item BitwardenItem, folders map[string]string) types.Credential {
+	login := item.Login
+	credential := types.Credential{
+		ID:       item.ID,
+		Title:    item.Name,
+		Username: stringValue(login.Username),
+		Password: stringValue(login.Password),
+		TOTP:     strings.TrimSpace(stringValue(login.TOTP)),
+	}
+
+	// 收集所有URL
+	var urls []string
+	for _, uri := range login.URIs {
+		if value := strings.TrimSpace(stringValue(uri.URI)); value != "" {
+			urls = append(urls, value)
+		}
+	}
+	if len(urls) > 0 {
+		credential.URLs = urls
+		credential.URL = urls[0] // 第一个URL作为主URL，保持向后兼容
+	}
+
+	// Passkey信息：记录依赖方ID，缺失时回退到凭据ID
+	var passkeys []string
+	for _, fido2 := range login.FIDO2Credentials {
+		if fido2.RpID != "" {
+			passkeys = append(passkeys, fido2.RpID)
+		} else if fido2.CredentialID != "" {
+			passkeys = append(passkeys, fido2.CredentialID)
+		}
+	}
+	if len(passkeys) > 0 {
+		credential.Passkey = strings.Join(passkeys, ", ")
+	}
+
+	// 文件夹名称作为标签
+	if item.FolderID != nil {
+		if name, exists := folders[*item.FolderID]; exists && name != "" {
+			credential.Tags = []string{name}
+		}
+	}
+
+	// 合并备注和自定义字段
+	var notes []string
+	if value := strings.TrimSpace(stringValue(item.Notes)); value != "" {
+		notes = append(notes, value)
+	}
+	for _, field := range item.Fields {
+		p.processField(&notes, field)
+	}
+	if len(notes) > 0 {
+		credential.Notes = strings.Join(notes, "; ")
+	}
+
+	return credential
+}
+
+// processField 处理单个自定义字段
+func (p *BitwardenParser) processField(notes *[]string, field BitwardenField) {
+	// 关联字段只是指向用户名/密码的引用，没有独立的值
+	if field.Type == BitwardenFieldLinked {
+		return
+	}
+
+	value := stringValue(field.Value)
+	if strings.TrimSpace(value) == "" || field.Name == "" {
+		return
+	}
+
+	*notes = append(*notes, fmt.Sprintf("%s: %s", field.Name, value))
+}
+
+// stringValue 安全地解引用可能为null的字符串
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
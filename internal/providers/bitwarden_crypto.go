@@ -0,0 +1,191 @@
+package providers
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// BitwardenKdfType Bitwarden密钥派生算法枚举
+type BitwardenKdfType int
+
+const (
+	KdfPBKDF2SHA256 BitwardenKdfType = 0
+	KdfArgon2id     BitwardenKdfType = 1
+)
+
+// encStringAesCbc256HmacSha256 EncString类型2：AES-256-CBC + HMAC-SHA256
+const encStringAesCbc256HmacSha256 = 2
+
+// ErrBitwardenWrongPassword 口令错误（校验串MAC验证失败）
+var ErrBitwardenWrongPassword = errors.New("invalid password for encrypted Bitwarden export")
+
+// BitwardenEncryption 口令保护导出文件的头部信息
+type BitwardenEncryption struct {
+	PasswordProtected bool             `json:"passwordProtected"`
+	Salt              string           `json:"salt"`
+	KdfType           BitwardenKdfType `json:"kdfType"`
+	KdfIterations     int              `json:"kdfIterations"`
+	KdfMemory         int              `json:"kdfMemory"`      // Argon2内存，单位MiB
+	KdfParallelism    int              `json:"kdfParallelism"` // Argon2并行度
+	EncKeyValidation  string           `json:"encKeyValidation_DO_NOT_EDIT"`
+	Data              string           `json:"data"`
+}
+
+// bitwardenKey 拉伸后的加密密钥与MAC密钥
+type bitwardenKey struct {
+	encKey []byte
+	macKey []byte
+}
+
+// wipe 清零密钥材料
+func (k *bitwardenKey) wipe() {
+	clear(k.encKey)
+	clear(k.macKey)
+}
+
+// deriveBitwardenKey 按导出头部指定的KDF从口令派生密钥，并用HKDF拉伸为加密/MAC密钥
+func deriveBitwardenKey(passphrase []byte, enc BitwardenEncryption) (*bitwardenKey, error) {
+	if enc.Salt == "" {
+		return nil, fmt.Errorf("encrypted Bitwarden export is missing salt")
+	}
+	if enc.KdfIterations <= 0 {
+		return nil, fmt.Errorf("invalid KDF iterations: %d", enc.KdfIterations)
+	}
+
+	var masterKey []byte
+	switch enc.KdfType {
+	case KdfPBKDF2SHA256:
+		key, err := pbkdf2.Key(sha256.New, string(passphrase), []byte(enc.Salt), enc.KdfIterations, 32)
+		if err != nil {
+			return nil, fmt.Errorf("PBKDF2 key derivation failed: %w", err)
+		}
+		masterKey = key
+
+	case KdfArgon2id:
+		if enc.KdfMemory <= 0 || enc.KdfParallelism <= 0 {
+			return nil, fmt.Errorf("invalid Argon2id parameters: memory=%d parallelism=%d", enc.KdfMemory, enc.KdfParallelism)
+		}
+		// Bitwarden对Argon2使用盐值的SHA-256摘要
+		salt := sha256.Sum256([]byte(enc.Salt))
+		masterKey = argon2.IDKey(passphrase, salt[:], uint32(enc.KdfIterations), uint32(enc.KdfMemory)*1024, uint8(enc.KdfParallelism), 32)
+
+	default:
+		return nil, fmt.Errorf("unsupported Bitwarden KDF type: %d", enc.KdfType)
+	}
+	defer clear(masterKey)
+
+	encKey, err := hkdf.Expand(sha256.New, masterKey, "enc", 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stretch encryption key: %w", err)
+	}
+	macKey, err := hkdf.Expand(sha256.New, masterKey, "mac", 32)
+	if err != nil {
+		clear(encKey)
+		return nil, fmt.Errorf("failed to stretch MAC key: %w", err)
+	}
+
+	return &bitwardenKey{encKey: encKey, macKey: macKey}, nil
+}
+
+// decryptEncString 解密Bitwarden EncString（格式: "2.iv|data|mac"，各部分为base64）
+func decryptEncString(encString string, key *bitwardenKey) ([]byte, error) {
+	typeSep := strings.IndexByte(encString, '.')
+	if typeSep < 0 {
+		return nil, fmt.Errorf("malformed EncString: missing type")
+	}
+	if encType, err := strconv.Atoi(encString[:typeSep]); err != nil || encType != encStringAesCbc256HmacSha256 {
+		return nil, fmt.Errorf("unsupported EncString type: %s", encString[:typeSep])
+	}
+
+	parts := strings.Split(encString[typeSep+1:], "|")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed EncString: expected 3 parts, got %d", len(parts))
+	}
+
+	iv, err := base64.StdEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("malformed EncString IV: %w", err)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("malformed EncString data: %w", err)
+	}
+	mac, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed EncString MAC: %w", err)
+	}
+
+	// 先验证MAC再解密
+	h := hmac.New(sha256.New, key.macKey)
+	h.Write(iv)
+	h.Write(ciphertext)
+	if !hmac.Equal(h.Sum(nil), mac) {
+		return nil, ErrBitwardenWrongPassword
+	}
+
+	if len(iv) != aes.BlockSize || len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("malformed EncString: invalid IV or ciphertext length")
+	}
+
+	block, err := aes.NewCipher(key.encKey)
+	if err != nil {
+		return nil, err
+	}
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+
+	return unpadPKCS7(plaintext, aes.BlockSize)
+}
+
+// unpadPKCS7 去除PKCS#7填充
+func unpadPKCS7(data []byte, blockSize int) ([]byte, error) {
+	if len(data) == 0 || len(data)%blockSize != 0 {
+		return nil, fmt.Errorf("invalid padded data length")
+	}
+	padding := int(data[len(data)-1])
+	if padding == 0 || padding > blockSize || padding > len(data) {
+		return nil, fmt.Errorf("invalid padding")
+	}
+	for _, b := range data[len(data)-padding:] {
+		if int(b) != padding {
+			return nil, fmt.Errorf("invalid padding")
+		}
+	}
+	return data[:len(data)-padding], nil
+}
+
+// decryptBitwardenExport 在内存中解密口令保护的导出文件，返回明文JSON
+func decryptBitwardenExport(enc BitwardenEncryption, passphrase []byte) ([]byte, error) {
+	key, err := deriveBitwardenKey(passphrase, enc)
+	if err != nil {
+		return nil, err
+	}
+	defer key.wipe()
+
+	// 校验串用于在解密数据前确认口令正确
+	if enc.EncKeyValidation != "" {
+		validation, err := decryptEncString(enc.EncKeyValidation, key)
+		if err != nil {
+			return nil, err
+		}
+		clear(validation)
+	}
+
+	plaintext, err := decryptEncString(enc.Data, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt export data: %w", err)
+	}
+
+	return plaintext, nil
+}
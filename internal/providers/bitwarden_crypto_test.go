@@ -0,0 +1,154 @@
+package providers
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// encryptEncString 测试辅助函数：生成类型2的EncString
+func encryptEncString(t *testing.T, plaintext []byte, key *bitwardenKey) string {
+	t.Helper()
+
+	iv := []byte("0123456789abcdef")
+	padding := aes.BlockSize - len(plaintext)%aes.BlockSize
+	padded := append([]byte{}, plaintext...)
+	for i := 0; i < padding; i++ {
+		padded = append(padded, byte(padding))
+	}
+
+	block, err := aes.NewCipher(key.encKey)
+	if err != nil {
+		t.Fatalf("NewCipher failed: %v", err)
+	}
+	ciphertext := make([]byte, len(padded))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, padded)
+
+	h := hmac.New(sha256.New, key.macKey)
+	h.Write(iv)
+	h.Write(ciphertext)
+
+	return "2." + base64.StdEncoding.EncodeToString(iv) + "|" +
+		base64.StdEncoding.EncodeToString(ciphertext) + "|" +
+		base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// buildEncryptedExport 测试辅助函数：生成口令保护的导出文件
+func buildEncryptedExport(t *testing.T, enc BitwardenEncryption, password string, plaintext string) string {
+	t.Helper()
+
+	key, err := deriveBitwardenKey([]byte(password), enc)
+	if err != nil {
+		t.Fatalf("deriveBitwardenKey failed: %v", err)
+	}
+
+	enc.PasswordProtected = true
+	enc.EncKeyValidation = encryptEncString(t, []byte("6b0c5b1e-validation"), key)
+	enc.Data = encryptEncString(t, []byte(plaintext), key)
+
+	header := map[string]interface{}{
+		"encrypted":                    true,
+		"passwordProtected":            enc.PasswordProtected,
+		"salt":                         enc.Salt,
+		"kdfType":                      enc.KdfType,
+		"kdfIterations":                enc.KdfIterations,
+		"kdfMemory":                    enc.KdfMemory,
+		"kdfParallelism":               enc.KdfParallelism,
+		"encKeyValidation_DO_NOT_EDIT": enc.EncKeyValidation,
+		"data":                         enc.Data,
+	}
+	data, err := json.Marshal(header)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	return string(data)
+}
+
+const plainBitwardenExport = `{
+	"encrypted": false,
+	"folders": [],
+	"items": [
+		{
+			"id": "enc-uuid-1",
+			"type": 1,
+			"name": "GitLab",
+			"login": {
+				"uris": [{ "uri": "https://gitlab.com" }],
+				"username": "alice",
+				"password": "s3cret",
+				"totp": null
+			}
+		}
+	]
+}`
+
+func staticPassphrase(password string) func(string) ([]byte, error) {
+	return func(prompt string) ([]byte, error) {
+		return []byte(password), nil
+	}
+}
+
+func TestBitwardenParser_PasswordProtectedExport(t *testing.T) {
+	testCases := []struct {
+		name string
+		enc  BitwardenEncryption
+	}{
+		{
+			name: "pbkdf2",
+			enc:  BitwardenEncryption{Salt: "c2FsdHNhbHQ=", KdfType: KdfPBKDF2SHA256, KdfIterations: 1000},
+		},
+		{
+			name: "argon2id",
+			enc:  BitwardenEncryption{Salt: "YXJnb25zYWx0", KdfType: KdfArgon2id, KdfIterations: 1, KdfMemory: 1, KdfParallelism: 1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			export := buildEncryptedExport(t, tc.enc, "correct horse", plainBitwardenExport)
+
+			parser := NewBitwardenParser()
+			parser.SetPassphraseFunc(staticPassphrase("correct horse"))
+
+			credentials, err := parser.Parse(strings.NewReader(export))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if len(credentials) != 1 {
+				t.Fatalf("Expected 1 credential, got %d", len(credentials))
+			}
+			if credentials[0].Password != "s3cret" || credentials[0].URL != "https://gitlab.com" {
+				t.Errorf("Unexpected credential: %+v", credentials[0])
+			}
+		})
+	}
+}
+
+func TestBitwardenParser_WrongPassword(t *testing.T) {
+	enc := BitwardenEncryption{Salt: "c2FsdHNhbHQ=", KdfType: KdfPBKDF2SHA256, KdfIterations: 1000}
+	export := buildEncryptedExport(t, enc, "correct horse", plainBitwardenExport)
+
+	parser := NewBitwardenParser()
+	parser.SetPassphraseFunc(staticPassphrase("wrong"))
+
+	_, err := parser.Parse(strings.NewReader(export))
+	if !errors.Is(err, ErrBitwardenWrongPassword) {
+		t.Errorf("Expected ErrBitwardenWrongPassword, got %v", err)
+	}
+}
+
+func TestBitwardenParser_AccountRestrictedExport(t *testing.T) {
+	parser := NewBitwardenParser()
+	parser.SetPassphraseFunc(staticPassphrase("anything"))
+
+	_, err := parser.Parse(strings.NewReader(`{"encrypted": true, "encKeyValidation_DO_NOT_EDIT": "2.a|b|c", "folders": [], "items": []}`))
+	if err == nil {
+		t.Error("Expected error for account-restricted export")
+	}
+}
@@ -0,0 +1,207 @@
+package providers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBitwardenParser_Parse(t *testing.T) {
+	parser := NewBitwardenParser()
+
+	testJSON := `{
+		"encrypted": false,
+		"folders": [
+			{ "id": "folder-1", "name": "Work" }
+		],
+		"items": [
+			{
+				"id": "bw-uuid-1",
+				"folderId": "folder-1",
+				"type": 1,
+				"name": "GitHub Account",
+				"notes": "Development account",
+				"fields": [
+					{ "name": "Recovery Email", "value": "backup@example.com", "type": 0 },
+					{ "name": "PIN", "value": "1234", "type": 1 },
+					{ "name": "Linked", "value": null, "type": 3, "linkedId": 100 }
+				],
+				"login": {
+					"uris": [
+						{ "match": null, "uri": "https://github.com" },
+						{ "match": null, "uri": "https://gist.github.com" }
+					],
+					"username": "testuser",
+					"password": "testpass123",
+					"totp": "otpauth://totp/GitHub:testuser?secret=JBSWY3DPEHPK3PXP",
+					"fido2Credentials": [
+						{ "credentialId": "cred-id-1", "rpId": "github.com", "userName": "testuser" }
+					]
+				}
+			},
+			{
+				"id": "bw-uuid-2",
+				"folderId": null,
+				"type": 1,
+				"name": "Deleted Account",
+				"deletedDate": "2024-01-01T00:00:00.000Z",
+				"login": { "username": "deleted", "password": "deleted" }
+			},
+			{
+				"id": "bw-uuid-3",
+				"folderId": null,
+				"type": 2,
+				"name": "Secure Note",
+				"notes": "secret note",
+				"secureNote": { "type": 0 }
+			},
+			{
+				"id": "bw-uuid-4",
+				"folderId": "missing-folder",
+				"type": 1,
+				"name": "No URL Account",
+				"login": { "uris": [], "username": null, "password": "onlypass", "totp": null }
+			}
+		]
+	}`
+
+	credentials, err := parser.Parse(strings.NewReader(testJSON))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	// 应该只有2个有效凭据（跳过已删除和非login的）
+	if len(credentials) != 2 {
+		t.Fatalf("Expected 2 credentials, got %d", len(credentials))
+	}
+
+	cred1 := credentials[0]
+	if cred1.ID != "bw-uuid-1" {
+		t.Errorf("Expected ID 'bw-uuid-1', got '%s'", cred1.ID)
+	}
+	if cred1.Title != "GitHub Account" {
+		t.Errorf("Expected title 'GitHub Account', got '%s'", cred1.Title)
+	}
+	if cred1.Username != "testuser" || cred1.Password != "testpass123" {
+		t.Errorf("Unexpected username/password: '%s'/'%s'", cred1.Username, cred1.Password)
+	}
+	if cred1.URL != "https://github.com" {
+		t.Errorf("Expected URL 'https://github.com', got '%s'", cred1.URL)
+	}
+	if len(cred1.URLs) != 2 {
+		t.Errorf("Expected 2 URLs, got %d", len(cred1.URLs))
+	}
+	if !strings.HasPrefix(cred1.TOTP, "otpauth://") {
+		t.Errorf("Expected TOTP URI, got '%s'", cred1.TOTP)
+	}
+	if cred1.Passkey != "github.com" {
+		t.Errorf("Expected Passkey 'github.com', got '%s'", cred1.Passkey)
+	}
+	if len(cred1.Tags) != 1 || cred1.Tags[0] != "Work" {
+		t.Errorf("Expected tags [Work], got %v", cred1.Tags)
+	}
+
+	// 验证备注包含原始备注和自定义字段，但不包含关联字段
+	expectedNotes := []string{"Development account", "Recovery Email: backup@example.com", "PIN: 1234"}
+	for _, expected := range expectedNotes {
+		if !strings.Contains(cred1.Notes, expected) {
+			t.Errorf("Expected notes to contain '%s', got '%s'", expected, cred1.Notes)
+		}
+	}
+	if strings.Contains(cred1.Notes, "Linked") {
+		t.Errorf("Expected linked field to be skipped, got '%s'", cred1.Notes)
+	}
+
+	cred2 := credentials[1]
+	if cred2.ID != "bw-uuid-4" {
+		t.Errorf("Expected ID 'bw-uuid-4', got '%s'", cred2.ID)
+	}
+	if cred2.URL != "" || len(cred2.URLs) != 0 {
+		t.Errorf("Expected no URLs, got '%s' / %v", cred2.URL, cred2.URLs)
+	}
+	if len(cred2.Tags) != 0 {
+		t.Errorf("Expected no tags for unknown folder, got %v", cred2.Tags)
+	}
+}
+
+// 只有Passkey的登录项应保留
+func TestBitwardenParser_PasskeyOnly(t *testing.T) {
+	parser := NewBitwardenParser()
+
+	testJSON := `{
+		"encrypted": false,
+		"items": [
+			{
+				"id": "bw-passkey",
+				"type": 1,
+				"name": "Passkey Demo",
+				"login": {
+					"uris": [ { "match": null, "uri": "https://passkeys.example.com" } ],
+					"username": null,
+					"password": null,
+					"fido2Credentials": [ { "credentialId": "cred-id-2", "rpId": "passkeys.example.com" } ]
+				}
+			}
+		]
+	}`
+
+	credentials, err := parser.Parse(strings.NewReader(testJSON))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(credentials) != 1 {
+		t.Fatalf("Expected the passkey-only login to be kept, got %d credentials", len(credentials))
+	}
+	if credentials[0].Passkey != "passkeys.example.com" {
+		t.Errorf("Expected Passkey 'passkeys.example.com', got '%s'", credentials[0].Passkey)
+	}
+}
+
+func TestBitwardenParser_ShouldProcessItem(t *testing.T) {
+	parser := NewBitwardenParser()
+	deleted := "2024-01-01T00:00:00.000Z"
+
+	testCases := []struct {
+		name     string
+		item     BitwardenItem
+		expected bool
+	}{
+		{
+			name:     "valid login item",
+			item:     BitwardenItem{Type: ItemTypeLogin, Login: &BitwardenLogin{}},
+			expected: true,
+		},
+		{
+			name:     "deleted item",
+			item:     BitwardenItem{Type: ItemTypeLogin, Login: &BitwardenLogin{}, DeletedDate: &deleted},
+			expected: false,
+		},
+		{
+			name:     "card item",
+			item:     BitwardenItem{Type: ItemTypeCard},
+			expected: false,
+		},
+		{
+			name:     "login type without login data",
+			item:     BitwardenItem{Type: ItemTypeLogin},
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := parser.shouldProcessItem(tc.item)
+			if result != tc.expected {
+				t.Errorf("shouldProcessItem() = %v, expected %v", result, tc.expected)
+			}
+		})
+	}
+}
+
+func TestBitwardenParser_EncryptedExport(t *testing.T) {
+	parser := NewBitwardenParser()
+
+	_, err := parser.Parse(strings.NewReader(`{"encrypted": true, "passwordProtected": true, "data": "2.abc"}`))
+	if err == nil {
+		t.Error("Expected error for encrypted export")
+	}
+}
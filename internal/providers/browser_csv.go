@@ -0,0 +1,205 @@
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"slices"
+
+	"github.com/yourorg/unpass/internal/parser"
+	"github.com/yourorg/unpass/internal/types"
+)
+
+// BrowserCSVDialect 浏览器密码导出的CSV方言
+type BrowserCSVDialect string
+
+const (
+	DialectChromium BrowserCSVDialect = "chromium" // Chrome/Edge/Brave/Opera
+	DialectFirefox  BrowserCSVDialect = "firefox"
+	DialectApple    BrowserCSVDialect = "apple" // Apple Passwords / Safari
+)
+
+// ErrUnknownCSVHeader CSV表头不属于任何已知的浏览器导出格式
+var ErrUnknownCSVHeader = errors.New("unrecognized browser CSV header")
+
+// browserCSVColumns 方言到凭据字段的列名映射（列名均为小写）
+type browserCSVColumns struct {
+	dialect  BrowserCSVDialect
+	required []string // 识别方言所需的列
+	known    []string // 该方言导出的全部列，出现其他列时降低置信度
+	title    string
+	url      string
+	username string
+	password string
+	notes    string
+	totp     string
+	id       string
+}
+
+// browserCSVDialects 按识别顺序排列，特征更明确的方言在前
+var browserCSVDialects = []browserCSVColumns{
+	{
+		dialect:  DialectFirefox,
+		required: []string{"url", "username", "password", "guid"},
+		known:    []string{"url", "username", "password", "httprealm", "formactionorigin", "guid", "timecreated", "timelastused", "timepasswordchanged"},
+		url:      "url",
+		username: "username",
+		password: "password",
+		id:       "guid",
+	},
+	{
+		dialect:  DialectApple,
+		required: []string{"title", "url", "username", "password"},
+		known:    []string{"title", "url", "username", "password", "notes", "otpauth"},
+		title:    "title",
+		url:      "url",
+		username: "username",
+		password: "password",
+		notes:    "notes",
+		totp:     "otpauth",
+	},
+	{
+		dialect:  DialectChromium,
+		required: []string{"name", "url", "username", "password"},
+		known:    []string{"name", "url", "username", "password", "note"},
+		title:    "name",
+		url:      "url",
+		username: "username",
+		password: "password",
+		notes:    "note",
+	},
+}
+
+// BrowserCSVParser 浏览器密码CSV导出解析器，自动识别Chromium、Firefox和Apple表头
+type BrowserCSVParser struct{}
+
+func NewBrowserCSVParser() *BrowserCSVParser {
+	return &BrowserCSVParser{}
+}
+
+func (p *BrowserCSVParser) Name() string {
+	return "browser-csv"
+}
+
+func (p *BrowserCSVParser) Parse(reader io.Reader) ([]types.Credential, error) {
+	return parser.Collect(p.Stream(reader))
+}
+
+// Stream 逐行解析CSV
+func (p *BrowserCSVParser) Stream(reader io.Reader) iter.Seq2[types.Credential, error] {
+	return func(yield func(types.Credential, error) bool) {
+		csvReader := newCSVReader(reader)
+
+		headerRecord, err := csvReader.Read()
+		if err != nil {
+			yield(types.Credential{}, fmt.Errorf("failed to read CSV header: %w", err))
+			return
+		}
+
+		header := parseCSVHeader(headerRecord)
+		columns, err := p.detectDialect(header)
+		if err != nil {
+			yield(types.Credential{}, err)
+			return
+		}
+
+		for row := 1; ; row++ {
+			record, err := csvReader.Read()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				yield(types.Credential{}, fmt.Errorf("failed to read CSV row %d: %w", row, err))
+				return
+			}
+
+			// 提取字段数据
+			credential := p.extractCredential(header, columns, record, row)
+
+			// 验证必要字段
+			if isValidCredential(credential) && !yield(credential, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (p *BrowserCSVParser) SupportedFormats() []string {
+	return []string{"csv"}
+}
+
+// Detect 根据CSV表头识别浏览器方言，只读取文件开头部分
+func (p *BrowserCSVParser) Detect(reader io.ReadSeeker) (float64, error) {
+	headerRecord, err := newCSVReader(io.LimitReader(reader, csvHeaderLimit)).Read()
+	if err != nil {
+		return parser.ConfidenceNone, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	header := parseCSVHeader(headerRecord)
+	columns, err := p.detectDialect(header)
+	if err != nil {
+		return parser.ConfidenceNone, err
+	}
+
+	// 其他密码管理器的CSV可能包含相同的列名，带有额外列时交给更具体的解析器
+	for name := range header {
+		if name != "" && !slices.Contains(columns.known, name) {
+			return parser.ConfidenceMedium, nil
+		}
+	}
+	return parser.ConfidenceHigh, nil
+}
+
+// detectDialect 根据表头识别导出来源
+func (p *BrowserCSVParser) detectDialect(header csvHeader) (browserCSVColumns, error) {
+	for _, columns := range browserCSVDialects {
+		if header.has(columns.required) {
+			return columns, nil
+		}
+	}
+	return browserCSVColumns{}, ErrUnknownCSVHeader
+}
+
+// extractCredential 从CSV行中提取凭据信息
+func (p *BrowserCSVParser) extractCredential(header csvHeader, columns browserCSVColumns, record []string, row int) types.Credential {
+	credential := types.Credential{
+		ID:       header.value(record, columns.id),
+		Title:    header.value(record, columns.title),
+		Username: header.value(record, columns.username),
+		Password: header.rawValue(record, columns.password),
+		Notes:    header.value(record, columns.notes),
+		TOTP:     header.rawValue(record, columns.totp),
+	}
+
+	// 浏览器导出通常不含ID，使用方言和行号生成
+	if credential.ID == "" {
+		credential.ID = fmt.Sprintf("%s-%d", columns.dialect, row)
+	}
+
+	var urls []string
+	if rawURL := header.value(record, columns.url); rawURL != "" {
+		urls = append(urls, rawURL)
+	}
+
+	// Firefox额外记录表单提交地址和HTTP认证域
+	if columns.dialect == DialectFirefox {
+		if formAction := header.value(record, "formactionorigin"); formAction != "" && (len(urls) == 0 || formAction != urls[0]) {
+			urls = append(urls, formAction)
+		}
+		if realm := header.value(record, "httprealm"); realm != "" {
+			credential.Notes = fmt.Sprintf("HTTP Realm: %s", realm)
+		}
+	}
+
+	if len(urls) > 0 {
+		credential.URLs = urls
+		credential.URL = urls[0]
+	}
+
+	// 没有标题时使用主机名
+	if credential.Title == "" {
+		credential.Title = hostnameTitle(credential.URL)
+	}
+
+	return credential
+}
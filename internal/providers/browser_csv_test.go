@@ -0,0 +1,124 @@
+package providers
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBrowserCSVParser_Chromium(t *testing.T) {
+	data := "\ufeffname,url,username,password,note\n" +
+		"github.com,https://github.com/login,alice,hunter2,\"line one\nline two\"\n" +
+		"empty.com,https://empty.com,,,\n"
+
+	parser := NewBrowserCSVParser()
+	credentials, err := parser.Parse(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	// 没有用户名和密码的行应该被跳过
+	if len(credentials) != 1 {
+		t.Fatalf("Expected 1 credential, got %d", len(credentials))
+	}
+
+	cred := credentials[0]
+	if cred.ID != "chromium-1" || cred.Title != "github.com" {
+		t.Errorf("Unexpected ID/title: '%s'/'%s'", cred.ID, cred.Title)
+	}
+	if cred.URL != "https://github.com/login" || cred.Username != "alice" || cred.Password != "hunter2" {
+		t.Errorf("Unexpected credential: %+v", cred)
+	}
+	if cred.Notes != "line one\nline two" {
+		t.Errorf("Expected multi-line note, got '%s'", cred.Notes)
+	}
+}
+
+// 密码首尾的空格是密码的一部分，其他列照常去除空白
+func TestBrowserCSVParser_PasswordWhitespace(t *testing.T) {
+	data := "name,url,username,password,note\n" +
+		"example.com,https://example.com, alice ,\" hunter2 \",\n"
+
+	credentials, err := NewBrowserCSVParser().Parse(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(credentials) != 1 {
+		t.Fatalf("Expected 1 credential, got %d", len(credentials))
+	}
+	if credentials[0].Username != "alice" || credentials[0].Password != " hunter2 " {
+		t.Errorf("Unexpected username/password: %q/%q", credentials[0].Username, credentials[0].Password)
+	}
+}
+
+func TestBrowserCSVParser_Firefox(t *testing.T) {
+	data := `"url","username","password","httpRealm","formActionOrigin","guid","timeCreated","timeLastUsed","timePasswordChanged"
+"https://accounts.example.com","bob","s3cret",,"https://login.example.com","{5ec0d12f-1111}","1700000000000","1700000000000","1700000000000"
+"https://intranet.local","carol","pw","Intranet",,"{5ec0d12f-2222}","1700000000000","1700000000000","1700000000000"
+`
+
+	parser := NewBrowserCSVParser()
+	credentials, err := parser.Parse(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(credentials) != 2 {
+		t.Fatalf("Expected 2 credentials, got %d", len(credentials))
+	}
+
+	cred1 := credentials[0]
+	if cred1.ID != "{5ec0d12f-1111}" || cred1.Title != "accounts.example.com" {
+		t.Errorf("Unexpected ID/title: '%s'/'%s'", cred1.ID, cred1.Title)
+	}
+	if len(cred1.URLs) != 2 || cred1.URLs[1] != "https://login.example.com" {
+		t.Errorf("Expected form action origin as second URL, got %v", cred1.URLs)
+	}
+
+	if credentials[1].Notes != "HTTP Realm: Intranet" {
+		t.Errorf("Expected HTTP realm in notes, got '%s'", credentials[1].Notes)
+	}
+}
+
+func TestBrowserCSVParser_Apple(t *testing.T) {
+	data := "Title,URL,Username,Password,Notes,OTPAuth\r\n" +
+		"GitHub (alice),https://github.com/,alice,hunter2,,otpauth://totp/GitHub:alice?secret=JBSWY3DPEHPK3PXP&issuer=GitHub\r\n"
+
+	parser := NewBrowserCSVParser()
+	credentials, err := parser.Parse(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(credentials) != 1 {
+		t.Fatalf("Expected 1 credential, got %d", len(credentials))
+	}
+
+	cred := credentials[0]
+	if cred.ID != "apple-1" || cred.Title != "GitHub (alice)" {
+		t.Errorf("Unexpected ID/title: '%s'/'%s'", cred.ID, cred.Title)
+	}
+	if !strings.HasPrefix(cred.TOTP, "otpauth://totp/") {
+		t.Errorf("Expected OTPAuth mapped to TOTP, got '%s'", cred.TOTP)
+	}
+}
+
+func TestBrowserCSVParser_DuplicateColumns(t *testing.T) {
+	data := "name,url,username,password,url\n" +
+		"Example,,alice,pw,https://example.com\n"
+
+	parser := NewBrowserCSVParser()
+	credentials, err := parser.Parse(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(credentials) != 1 || credentials[0].URL != "https://example.com" {
+		t.Errorf("Expected first non-empty duplicate column to be used, got %+v", credentials)
+	}
+}
+
+func TestBrowserCSVParser_UnknownHeader(t *testing.T) {
+	parser := NewBrowserCSVParser()
+	_, err := parser.Parse(strings.NewReader("foo,bar\n1,2\n"))
+	if !errors.Is(err, ErrUnknownCSVHeader) {
+		t.Errorf("Expected ErrUnknownCSVHeader, got %v", err)
+	}
+}
@@ -0,0 +1,12 @@
+package providers
+
+import "github.com/yourorg/unpass/internal/types"
+
+// isValidCredential 所有解析器共用的验证规则：必须有ID和标题，以及用户名、密码或Passkey之一
+// 只有Passkey的登录项同样需要审计（如Passkey检测和2FA检测）
+func isValidCredential(credential types.Credential) bool {
+	if credential.ID == "" || credential.Title == "" {
+		return false
+	}
+	return credential.Username != "" || credential.Password != "" || credential.Passkey != ""
+}
@@ -0,0 +1,28 @@
+package providers
+
+import (
+	"testing"
+
+	"github.com/yourorg/unpass/internal/types"
+)
+
+func TestIsValidCredential(t *testing.T) {
+	testCases := []struct {
+		name       string
+		credential types.Credential
+		expected   bool
+	}{
+		{"username only", types.Credential{ID: "1", Title: "Site", Username: "alice"}, true},
+		{"password only", types.Credential{ID: "1", Title: "Site", Password: "pw"}, true},
+		{"passkey only", types.Credential{ID: "1", Title: "Site", Passkey: "example.com"}, true},
+		{"no login data", types.Credential{ID: "1", Title: "Site", URL: "https://example.com"}, false},
+		{"no ID", types.Credential{Title: "Site", Username: "alice"}, false},
+		{"no title", types.Credential{ID: "1", Username: "alice"}, false},
+	}
+
+	for _, tc := range testCases {
+		if got := isValidCredential(tc.credential); got != tc.expected {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.expected, got)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package providers
+
+import (
+	"encoding/csv"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// csvHeaderLimit 识别格式时最多读取的字节数
+const csvHeaderLimit = 64 << 10
+
+// csvHeader 解析后的表头，同名列可能出现多次
+type csvHeader map[string][]int
+
+// value 返回同名列中第一个非空值
+func (h csvHeader) value(record []string, column string) string {
+	if column == "" {
+		return ""
+	}
+	for _, index := range h[column] {
+		if index < len(record) {
+			if value := strings.TrimSpace(record[index]); value != "" {
+				return value
+			}
+		}
+	}
+	return ""
+}
+
+// rawValue 与value相同但不去除空白，用于密码和TOTP密钥：首尾空格是密码的一部分
+func (h csvHeader) rawValue(record []string, column string) string {
+	if column == "" {
+		return ""
+	}
+	for _, index := range h[column] {
+		if index < len(record) && record[index] != "" {
+			return record[index]
+		}
+	}
+	return ""
+}
+
+// has 判断表头是否包含所有指定列
+func (h csvHeader) has(columns []string) bool {
+	for _, column := range columns {
+		if _, exists := h[column]; !exists {
+			return false
+		}
+	}
+	return true
+}
+
+// newCSVReader 创建宽松的CSV读取器：允许列数不一致和不规范的引号
+func newCSVReader(reader io.Reader) *csv.Reader {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.LazyQuotes = true
+	return csvReader
+}
+
+// parseCSVHeader 规范化表头：去除BOM和空白，统一小写
+func parseCSVHeader(record []string) csvHeader {
+	header := make(csvHeader)
+	for index, name := range record {
+		if index == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		header[name] = append(header[name], index)
+	}
+	return header
+}
+
+// hostnameTitle 从URL中提取主机名作为标题
+func hostnameTitle(rawURL string) string {
+	if rawURL == "" {
+		return ""
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Hostname() == "" {
+		return rawURL
+	}
+	return parsed.Hostname()
+}
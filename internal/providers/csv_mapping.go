@@ -0,0 +1,232 @@
+package providers
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CSVField 通用CSV可以映射到的凭据字段
+type CSVField string
+
+const (
+	CSVFieldID       CSVField = "id"
+	CSVFieldTitle    CSVField = "title"
+	CSVFieldURL      CSVField = "url" // 多值，按分隔符拆分
+	CSVFieldUsername CSVField = "username"
+	CSVFieldPassword CSVField = "password"
+	CSVFieldNotes    CSVField = "notes"
+	CSVFieldTOTP     CSVField = "totp"
+	CSVFieldTags     CSVField = "tags" // 多值，按分隔符拆分
+	CSVFieldCategory CSVField = "category"
+)
+
+// csvFields 可映射的字段，用于校验和错误提示
+var csvFields = []CSVField{
+	CSVFieldID, CSVFieldTitle, CSVFieldURL, CSVFieldUsername, CSVFieldPassword,
+	CSVFieldNotes, CSVFieldTOTP, CSVFieldTags, CSVFieldCategory,
+}
+
+// csvMappingHeaderKey 映射规则中控制是否有表头的特殊键
+const csvMappingHeaderKey = "header"
+
+// CSVMapping 通用CSV的列映射
+type CSVMapping struct {
+	Name      string
+	HasHeader bool
+	// Columns 字段到列的映射；列名不区分大小写，"#N"表示第N列（从1开始）
+	// 同一字段映射多列时取第一个非空值，多值字段则合并所有列
+	Columns map[CSVField][]string
+	// Detect 根据表头识别预设时要求存在的列
+	Detect []string
+	// Ignore 既不映射也不放入备注的列
+	Ignore []string
+	// ExtraPairs 映射列之后的列按"名称,值"成对出现（Keeper的自定义字段）
+	ExtraPairs bool
+}
+
+// NewCSVMapping 创建空的自定义映射
+func NewCSVMapping() *CSVMapping {
+	return &CSVMapping{
+		Name:      "csv",
+		HasHeader: true,
+		Columns:   make(map[CSVField][]string),
+	}
+}
+
+// csvPresets 内置的映射预设
+var csvPresets = map[string]CSVMapping{
+	"roboform": {
+		Name:      "roboform",
+		HasHeader: true,
+		Columns: map[CSVField][]string{
+			CSVFieldTitle:    {"Name"},
+			CSVFieldURL:      {"Url"},
+			CSVFieldUsername: {"Login"},
+			CSVFieldPassword: {"Pwd"},
+			CSVFieldNotes:    {"Note"},
+			CSVFieldTags:     {"Folder"},
+		},
+		Detect: []string{"name", "url", "matchurl", "login", "pwd"},
+		Ignore: []string{"MatchUrl", "RfFieldsV2"},
+	},
+	"keeper": {
+		Name:      "keeper",
+		HasHeader: false,
+		Columns: map[CSVField][]string{
+			CSVFieldTags:     {"#1", "#7"}, // 文件夹和共享文件夹
+			CSVFieldTitle:    {"#2"},
+			CSVFieldUsername: {"#3"},
+			CSVFieldPassword: {"#4"},
+			CSVFieldURL:      {"#5"},
+			CSVFieldNotes:    {"#6"},
+		},
+		ExtraPairs: true,
+	},
+	"nordpass": {
+		Name:      "nordpass",
+		HasHeader: true,
+		Columns: map[CSVField][]string{
+			CSVFieldTitle:    {"name"},
+			CSVFieldURL:      {"url", "additional_urls"},
+			CSVFieldUsername: {"username", "email"},
+			CSVFieldPassword: {"password"},
+			CSVFieldNotes:    {"note"},
+			CSVFieldTags:     {"folder"},
+			CSVFieldCategory: {"type"},
+		},
+		Detect: []string{"name", "url", "additional_urls", "username", "password"},
+		Ignore: []string{"cardholdername", "cardnumber", "cvc", "expirydate", "zipcode"},
+	},
+	"lastpass": {
+		Name:      "lastpass",
+		HasHeader: true,
+		Columns: map[CSVField][]string{
+			CSVFieldTitle:    {"name"},
+			CSVFieldURL:      {"url"},
+			CSVFieldUsername: {"username"},
+			CSVFieldPassword: {"password"},
+			CSVFieldTOTP:     {"totp"},
+			CSVFieldNotes:    {"extra"},
+			CSVFieldTags:     {"grouping"},
+		},
+		Detect: []string{"url", "username", "password", "extra", "name", "grouping"},
+		Ignore: []string{"fav"},
+	},
+}
+
+// CSVPreset 返回内置预设的副本
+func CSVPreset(name string) (*CSVMapping, bool) {
+	preset, exists := csvPresets[strings.ToLower(name)]
+	if !exists {
+		return nil, false
+	}
+	return preset.clone(), true
+}
+
+// CSVPresetNames 返回所有内置预设的名称
+func CSVPresetNames() []string {
+	names := make([]string, 0, len(csvPresets))
+	for name := range csvPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// clone 深拷贝映射，避免修改内置预设
+func (m *CSVMapping) clone() *CSVMapping {
+	cloned := *m
+	cloned.Columns = make(map[CSVField][]string, len(m.Columns))
+	for field, columns := range m.Columns {
+		cloned.Columns[field] = slices.Clone(columns)
+	}
+	cloned.Detect = slices.Clone(m.Detect)
+	cloned.Ignore = slices.Clone(m.Ignore)
+	return &cloned
+}
+
+// Apply 应用"字段=列名"形式的映射规则，规则之间用逗号或换行分隔，#开头的行为注释
+// 规则中出现的字段替换原有映射，同一字段出现多次时依次追加；header=false表示没有表头
+func (m *CSVMapping) Apply(spec string) error {
+	replaced := make(map[CSVField]bool)
+
+	for _, line := range strings.Split(spec, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		for _, rule := range strings.Split(line, ",") {
+			rule = strings.TrimSpace(rule)
+			if rule == "" {
+				continue
+			}
+
+			key, column, found := strings.Cut(rule, "=")
+			key, column = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(column)
+			if !found || column == "" {
+				return fmt.Errorf("invalid CSV mapping rule %q (expected field=Column)", rule)
+			}
+
+			if key == csvMappingHeaderKey {
+				hasHeader, err := strconv.ParseBool(column)
+				if err != nil {
+					return fmt.Errorf("invalid CSV mapping rule %q: %w", rule, err)
+				}
+				m.HasHeader = hasHeader
+				continue
+			}
+
+			field := CSVField(key)
+			if !slices.Contains(csvFields, field) {
+				return fmt.Errorf("unknown CSV mapping field %q (supported: %s)", key, joinCSVFields())
+			}
+			if strings.HasPrefix(column, "#") {
+				if _, err := parseCSVColumnIndex(column); err != nil {
+					return fmt.Errorf("invalid CSV mapping rule %q: %w", rule, err)
+				}
+			}
+
+			if !replaced[field] {
+				m.Columns[field] = nil
+				replaced[field] = true
+			}
+			m.Columns[field] = append(m.Columns[field], column)
+		}
+	}
+
+	return nil
+}
+
+// ApplyFile 从映射文件读取规则，格式与Apply相同
+func (m *CSVMapping) ApplyFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read CSV mapping file: %w", err)
+	}
+	return m.Apply(string(data))
+}
+
+// parseCSVColumnIndex 解析"#N"形式的列序号，返回从0开始的下标
+func parseCSVColumnIndex(column string) (int, error) {
+	if !strings.HasPrefix(column, "#") {
+		return 0, fmt.Errorf("not a column index: %s", column)
+	}
+	n, err := strconv.Atoi(column[1:])
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("column index must be a positive number: %s", column)
+	}
+	return n - 1, nil
+}
+
+func joinCSVFields() string {
+	names := make([]string, len(csvFields))
+	for i, field := range csvFields {
+		names[i] = string(field)
+	}
+	return strings.Join(names, ", ")
+}
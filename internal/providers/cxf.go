@@ -0,0 +1,361 @@
+package providers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/yourorg/unpass/internal/parser"
+	"github.com/yourorg/unpass/internal/types"
+)
+
+// CXFCredentialType FIDO凭据交换格式中的凭据类型
+type CXFCredentialType string
+
+const (
+	CXFCredentialBasicAuth    CXFCredentialType = "basic-auth"
+	CXFCredentialTOTP         CXFCredentialType = "totp"
+	CXFCredentialPasskey      CXFCredentialType = "passkey"
+	CXFCredentialNote         CXFCredentialType = "note"
+	CXFCredentialCustomFields CXFCredentialType = "custom-fields"
+)
+
+// CXFData FIDO Credential Exchange Format导出的头部结构
+type CXFData struct {
+	Version struct {
+		Major int `json:"major"`
+		Minor int `json:"minor"`
+	} `json:"version"`
+	ExporterRpID        string       `json:"exporterRpId"`
+	ExporterDisplayName string       `json:"exporterDisplayName"`
+	Accounts            []CXFAccount `json:"accounts"`
+}
+
+type CXFAccount struct {
+	ID          string          `json:"id"`
+	Username    string          `json:"username"`
+	Email       string          `json:"email"`
+	Collections []CXFCollection `json:"collections"`
+	Items       []CXFItem       `json:"items"`
+}
+
+// CXFCollection 项目集合（文件夹），可以嵌套
+type CXFCollection struct {
+	ID             string          `json:"id"`
+	Title          string          `json:"title"`
+	Items          []CXFLinkedItem `json:"items"`
+	SubCollections []CXFCollection `json:"subCollections"`
+}
+
+type CXFLinkedItem struct {
+	Item string `json:"item"`
+}
+
+type CXFItem struct {
+	ID          string          `json:"id"`
+	Title       string          `json:"title"`
+	Scope       *CXFScope       `json:"scope"`
+	Credentials []CXFCredential `json:"credentials"`
+	Tags        []string        `json:"tags"`
+}
+
+type CXFScope struct {
+	URLs []string `json:"urls"`
+}
+
+// CXFCredential 凭据对象，按type区分字段，未用到的类型只保留type
+type CXFCredential struct {
+	Type CXFCredentialType `json:"type"`
+
+	// basic-auth；早期草案中URL位于凭据内
+	Username CXFField `json:"username"`
+	Password CXFField `json:"password"`
+	URLs     []string `json:"urls"`
+
+	// totp
+	Secret    string `json:"secret"`
+	Period    int    `json:"period"`
+	Digits    int    `json:"digits"`
+	Algorithm string `json:"algorithm"`
+	Issuer    string `json:"issuer"`
+
+	// passkey
+	CredentialID string `json:"credentialId"`
+	RpID         string `json:"rpId"`
+
+	// note
+	Content CXFField `json:"content"`
+
+	// custom-fields
+	Label  string     `json:"label"`
+	Fields []CXFField `json:"fields"`
+}
+
+// CXFField 可编辑字段，兼容直接使用字符串的导出
+type CXFField struct {
+	FieldType string `json:"fieldType"`
+	Value     string `json:"value"`
+	Label     string `json:"label"`
+}
+
+func (f *CXFField) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(`"`)) {
+		return json.Unmarshal(data, &f.Value)
+	}
+	type field CXFField
+	return json.Unmarshal(data, (*field)(f))
+}
+
+// cxfCollections 项目ID到所属集合路径的映射
+type cxfCollections map[string][]string
+
+// add 递归记录集合及子集合中的项目
+func (c cxfCollections) add(collection CXFCollection, parent string) {
+	path := collection.Title
+	if parent != "" {
+		path = parent + "/" + collection.Title
+	}
+	for _, linked := range collection.Items {
+		if linked.Item != "" && collection.Title != "" && !slices.Contains(c[linked.Item], path) {
+			c[linked.Item] = append(c[linked.Item], path)
+		}
+	}
+	for _, sub := range collection.SubCollections {
+		c.add(sub, path)
+	}
+}
+
+// CXFParser FIDO凭据交换格式（CXF）解析器
+type CXFParser struct{}
+
+func NewCXFParser() *CXFParser {
+	return &CXFParser{}
+}
+
+func (p *CXFParser) Name() string {
+	return "cxf"
+}
+
+func (p *CXFParser) Parse(reader io.Reader) ([]types.Credential, error) {
+	var cxfData CXFData
+	decoder := json.NewDecoder(reader)
+
+	if err := decoder.Decode(&cxfData); err != nil {
+		return nil, fmt.Errorf("failed to decode CXF JSON: %w", err)
+	}
+	if cxfData.Version.Major > 1 {
+		return nil, fmt.Errorf("unsupported CXF version: %d.%d", cxfData.Version.Major, cxfData.Version.Minor)
+	}
+
+	var credentials []types.Credential
+
+	for _, account := range cxfData.Accounts {
+		collections := make(cxfCollections)
+		for _, collection := range account.Collections {
+			collections.add(collection, "")
+		}
+
+		for _, item := range account.Items {
+			// 跳过不符合条件的数据
+			if !p.shouldProcessItem(item) {
+				continue
+			}
+
+			// 提取字段数据
+			credential := p.extractCredential(item, collections[item.ID])
+
+			// 验证必要字段
+			if isValidCredential(credential) {
+				credentials = append(credentials, credential)
+			}
+		}
+	}
+
+	return credentials, nil
+}
+
+func (p *CXFParser) SupportedFormats() []string {
+	return []string{"cxf"}
+}
+
+// Detect CXF头部带有exporterRpId，或accounts中的账户直接包含items
+func (p *CXFParser) Detect(reader io.ReadSeeker) (float64, error) {
+	decoder := json.NewDecoder(reader)
+	confidence := parser.ConfidenceNone
+	reason := errors.New("missing CXF exporterRpId and accounts")
+
+	err := parser.WalkJSONObject(decoder, func(key string) (bool, error) {
+		switch key {
+		case "exporterRpId":
+			confidence, reason = parser.ConfidenceCertain, nil
+			return false, nil
+		case "accounts":
+			// 1Password导出同样有accounts数组，但账户下是attrs和vaults
+			accountKey, empty, err := parser.FindJSONArrayObjectKey(decoder, "items", "collections", "vaults", "attrs")
+			if err != nil {
+				return false, err
+			}
+			switch {
+			case accountKey == "items" || accountKey == "collections":
+				confidence, reason = parser.ConfidenceHigh, nil
+			case empty:
+				reason = errors.New("CXF accounts array is empty")
+			default:
+				reason = errors.New("accounts do not contain CXF items")
+			}
+			return false, nil
+		}
+		return true, parser.SkipJSONValue(decoder)
+	})
+	if err != nil {
+		return parser.ConfidenceNone, err
+	}
+	return confidence, reason
+}
+
+// shouldProcessItem 只处理包含登录凭据或Passkey的项目
+func (p *CXFParser) shouldProcessItem(item CXFItem) bool {
+	for _, credential := range item.Credentials {
+		if credential.Type == CXFCredentialBasicAuth || credential.Type == CXFCredentialPasskey {
+			return true
+		}
+	}
+	return false
+}
+
+// extractCredential 从CXF项目中提取凭据信息
+func (p *CXFParser) extractCredential(item CXFItem, collections []string) types.Credential {
+	credential := types.Credential{
+		ID:    item.ID,
+		Title: item.Title,
+	}
+
+	var urls []string
+	addURL := func(raw string) {
+		if value := strings.TrimSpace(raw); value != "" && !slices.Contains(urls, value) {
+			urls = append(urls, value)
+		}
+	}
+	if item.Scope != nil {
+		for _, u := range item.Scope.URLs {
+			addURL(u)
+		}
+	}
+
+	// 用于收集备注信息的字段
+	var notes []string
+	var passkeys []string
+
+	for _, cred := range item.Credentials {
+		switch cred.Type {
+		case CXFCredentialBasicAuth:
+			if credential.Username == "" {
+				credential.Username = strings.TrimSpace(cred.Username.Value)
+			}
+			if credential.Password == "" {
+				credential.Password = cred.Password.Value
+			}
+			for _, u := range cred.URLs {
+				addURL(u)
+			}
+
+		case CXFCredentialTOTP:
+			if credential.TOTP == "" && strings.TrimSpace(cred.Secret) != "" {
+				credential.TOTP = cxfTOTPURI(cred)
+			}
+
+		case CXFCredentialPasskey:
+			// Passkey信息：记录依赖方ID，缺失时回退到凭据ID
+			if cred.RpID != "" {
+				passkeys = append(passkeys, cred.RpID)
+			} else if cred.CredentialID != "" {
+				passkeys = append(passkeys, cred.CredentialID)
+			}
+			if credential.Username == "" {
+				credential.Username = strings.TrimSpace(cred.Username.Value)
+			}
+
+		case CXFCredentialNote:
+			if value := strings.TrimSpace(cred.Content.Value); value != "" {
+				notes = append(notes, value)
+			}
+
+		case CXFCredentialCustomFields:
+			for _, field := range cred.Fields {
+				if value := strings.TrimSpace(field.Value); value != "" && field.Label != "" {
+					notes = append(notes, fmt.Sprintf("%s: %s", field.Label, value))
+				}
+			}
+		}
+	}
+
+	// 只有Passkey的项目可能没有URL，使用依赖方ID作为站点
+	if len(urls) == 0 {
+		for _, cred := range item.Credentials {
+			if cred.Type == CXFCredentialPasskey && cred.RpID != "" {
+				addURL("https://" + cred.RpID)
+			}
+		}
+	}
+	if len(urls) > 0 {
+		credential.URLs = urls
+		credential.URL = urls[0]
+	}
+	if len(passkeys) > 0 {
+		credential.Passkey = strings.Join(passkeys, ", ")
+	}
+
+	// 集合路径和项目标签作为标签
+	tags := append(slices.Clone(collections), item.Tags...)
+	if len(tags) > 0 {
+		credential.Tags = tags
+	}
+
+	// 合并备注信息
+	if len(notes) > 0 {
+		credential.Notes = strings.Join(notes, "; ")
+	}
+
+	// 没有标题时使用主机名
+	if credential.Title == "" {
+		credential.Title = hostnameTitle(credential.URL)
+	}
+
+	return credential
+}
+
+// cxfTOTPURI 将CXF的TOTP凭据转换为otpauth URI
+func cxfTOTPURI(cred CXFCredential) string {
+	params := url.Values{}
+	params.Set("secret", strings.ReplaceAll(strings.TrimSpace(cred.Secret), " ", ""))
+	if cred.Issuer != "" {
+		params.Set("issuer", cred.Issuer)
+	}
+	if cred.Algorithm != "" {
+		params.Set("algorithm", strings.ToUpper(cred.Algorithm))
+	}
+	if cred.Digits > 0 {
+		params.Set("digits", strconv.Itoa(cred.Digits))
+	}
+	if cred.Period > 0 {
+		params.Set("period", strconv.Itoa(cred.Period))
+	}
+
+	label := cred.Username.Value
+	if cred.Issuer != "" {
+		label = cred.Issuer + ":" + label
+	}
+
+	return (&url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + label,
+		RawQuery: params.Encode(),
+	}).String()
+}
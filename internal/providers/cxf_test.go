@@ -0,0 +1,180 @@
+package providers
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const testCXFExport = `{
+	"version": {"major": 1, "minor": 0},
+	"exporterRpId": "exporter.example.com",
+	"exporterDisplayName": "Example Manager",
+	"timestamp": 1705228800,
+	"accounts": [
+		{
+			"id": "YWNjb3VudA",
+			"username": "",
+			"email": "alice@example.com",
+			"collections": [
+				{
+					"id": "Y29sbGVjdGlvbg",
+					"title": "Work",
+					"items": [{"item": "aXRlbS0x", "account": "YWNjb3VudA"}],
+					"subCollections": [
+						{"id": "c3Vi", "title": "Dev", "items": [{"item": "aXRlbS0x"}]}
+					]
+				}
+			],
+			"items": [
+				{
+					"id": "aXRlbS0x",
+					"creationAt": 1705228800,
+					"title": "GitHub",
+					"scope": {"urls": ["https://github.com"], "androidApps": []},
+					"credentials": [
+						{
+							"type": "basic-auth",
+							"username": {"id": "dQ", "fieldType": "string", "value": "alice"},
+							"password": {"id": "cA", "fieldType": "concealed-string", "value": "hunter2"}
+						},
+						{
+							"type": "totp",
+							"secret": "JBSWY3DPEHPK3PXP",
+							"period": 30,
+							"digits": 6,
+							"username": "alice",
+							"algorithm": "sha1",
+							"issuer": "GitHub"
+						},
+						{
+							"type": "passkey",
+							"credentialId": "Y3JlZA",
+							"rpId": "github.com",
+							"username": "alice",
+							"userDisplayName": "Alice",
+							"userHandle": "aGFuZGxl",
+							"key": "a2V5"
+						},
+						{"type": "note", "content": {"fieldType": "string", "value": "Work account"}},
+						{
+							"type": "custom-fields",
+							"fields": [{"id": "Zg", "fieldType": "string", "value": "1234", "label": "Recovery PIN"}]
+						}
+					],
+					"tags": ["important"]
+				},
+				{
+					"id": "aXRlbS0y",
+					"title": "",
+					"credentials": [
+						{"type": "passkey", "credentialId": "Y3JlZDI", "rpId": "example.com", "username": "bob", "userHandle": "aA", "key": "aw"}
+					]
+				},
+				{
+					"id": "aXRlbS0z",
+					"title": "Visa",
+					"credentials": [{"type": "credit-card", "number": {"fieldType": "string", "value": "4111"}}]
+				}
+			]
+		}
+	]
+}`
+
+func TestCXFParser_Parse(t *testing.T) {
+	parser := NewCXFParser()
+	credentials, err := parser.Parse(strings.NewReader(testCXFExport))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	// 信用卡等非登录项目应该被跳过
+	if len(credentials) != 2 {
+		t.Fatalf("Expected 2 credentials, got %d", len(credentials))
+	}
+
+	cred1 := credentials[0]
+	if cred1.ID != "aXRlbS0x" || cred1.Title != "GitHub" || cred1.Username != "alice" || cred1.Password != "hunter2" {
+		t.Errorf("Unexpected credential: %+v", cred1)
+	}
+	if cred1.URL != "https://github.com" {
+		t.Errorf("Expected scope URL, got '%s'", cred1.URL)
+	}
+	if cred1.TOTP != "otpauth://totp/GitHub:alice?algorithm=SHA1&digits=6&issuer=GitHub&period=30&secret=JBSWY3DPEHPK3PXP" {
+		t.Errorf("Expected TOTP credential converted to otpauth URI, got '%s'", cred1.TOTP)
+	}
+	if cred1.Passkey != "github.com" {
+		t.Errorf("Expected passkey rpId, got '%s'", cred1.Passkey)
+	}
+	if !slices.Equal(cred1.Tags, []string{"Work", "Work/Dev", "important"}) {
+		t.Errorf("Expected collection paths and tags, got %v", cred1.Tags)
+	}
+	if cred1.Notes != "Work account; Recovery PIN: 1234" {
+		t.Errorf("Unexpected notes: '%s'", cred1.Notes)
+	}
+
+	// 只有Passkey的项目使用依赖方ID作为站点
+	cred2 := credentials[1]
+	if cred2.Username != "bob" || cred2.Passkey != "example.com" || cred2.URL != "https://example.com" || cred2.Title != "example.com" {
+		t.Errorf("Unexpected passkey-only credential: %+v", cred2)
+	}
+}
+
+// 没有用户名的Passkey项目同样应保留
+func TestCXFParser_PasskeyWithoutUsername(t *testing.T) {
+	export := `{
+		"version": {"major": 1, "minor": 0},
+		"accounts": [{"id": "YWNjb3VudA", "items": [
+			{
+				"id": "aXRlbS00",
+				"title": "Passkey Demo",
+				"credentials": [{"type": "passkey", "credentialId": "Y3JlZDM", "rpId": "passkeys.example.com", "userHandle": "aA", "key": "aw"}]
+			}
+		]}]
+	}`
+
+	credentials, err := NewCXFParser().Parse(strings.NewReader(export))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(credentials) != 1 {
+		t.Fatalf("Expected the passkey-only item to be kept, got %d credentials", len(credentials))
+	}
+	if cred := credentials[0]; cred.Username != "" || cred.Passkey != "passkeys.example.com" {
+		t.Errorf("Unexpected credential: %+v", cred)
+	}
+}
+
+func TestCXFParser_UnsupportedVersion(t *testing.T) {
+	parser := NewCXFParser()
+	_, err := parser.Parse(strings.NewReader(`{"version": {"major": 2, "minor": 0}, "exporterRpId": "x", "accounts": []}`))
+	if err == nil {
+		t.Error("Expected error for unsupported CXF version")
+	}
+}
+
+func TestRegistry_DetectCXF(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{name: "cxf", data: testCXFExport, expected: "cxf"},
+		{name: "cxf without exporter", data: `{"accounts": [{"id": "a", "items": []}]}`, expected: "cxf"},
+		{name: "1password", data: `{"accounts": [{"attrs": {"name": "Alice"}, "vaults": []}]}`, expected: "1password"},
+	}
+
+	registry := newTestRegistry()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			detected, err := registry.Detect(bytes.NewReader([]byte(tc.data)))
+			if err != nil {
+				t.Fatalf("Detect failed: %v", err)
+			}
+			if detected.Name() != tc.expected {
+				t.Errorf("Expected parser '%s', got '%s'", tc.expected, detected.Name())
+			}
+		})
+	}
+}
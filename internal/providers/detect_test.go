@@ -0,0 +1,108 @@
+package providers
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/yourorg/unpass/internal/parser"
+)
+
+func newTestRegistry() *parser.Registry {
+	registry := parser.NewRegistry()
+	registry.Register("json", parser.NewJSONParser())
+	registry.Register("enpass", NewEnpassParser())
+	registry.Register("bitwarden", NewBitwardenParser())
+	registry.Register("1password", NewOnePasswordParser())
+	registry.Register("keepass", NewKeePassParser())
+	registry.Register("browser-csv", NewBrowserCSVParser())
+	registry.Register("csv", NewGenericCSVParser())
+	registry.Register("protonpass", NewProtonPassParser())
+	registry.Register("cxf", NewCXFParser())
+	registry.Register("firefox", NewFirefoxParser())
+	return registry
+}
+
+func TestRegistry_Detect(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     []byte
+		expected string
+	}{
+		{
+			name:     "generic json",
+			data:     []byte(`[{"id": "1", "title": "GitHub", "url": "https://github.com", "username": "alice", "password": "pw"}]`),
+			expected: "json",
+		},
+		{
+			name:     "enpass",
+			data:     []byte(`{"folders": [], "items": [{"category": "login", "title": "GitHub", "uuid": "1", "fields": []}]}`),
+			expected: "enpass",
+		},
+		{
+			name:     "bitwarden",
+			data:     []byte(`{"encrypted": false, "folders": [], "items": [{"id": "1", "type": 1, "name": "GitHub"}]}`),
+			expected: "bitwarden",
+		},
+		{
+			name:     "bitwarden encrypted",
+			data:     []byte(`{"encrypted": true, "passwordProtected": true, "salt": "c2FsdA==", "data": "2.x|y|z"}`),
+			expected: "bitwarden",
+		},
+		{
+			name:     "1password archive",
+			data:     buildOnePUX(t, testOnePasswordExportData),
+			expected: "1password",
+		},
+		{
+			name:     "kdbx",
+			data:     []byte{0x03, 0xd9, 0xa2, 0x9a, 0x67, 0xfb, 0x4b, 0xb5, 0x01, 0x00, 0x04, 0x00},
+			expected: "keepass",
+		},
+		{
+			name:     "chromium csv",
+			data:     []byte("name,url,username,password\nGitHub,https://github.com,alice,pw\n"),
+			expected: "browser-csv",
+		},
+	}
+
+	registry := newTestRegistry()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reader := bytes.NewReader(tc.data)
+			detected, err := registry.Detect(reader)
+			if err != nil {
+				t.Fatalf("Detect failed: %v", err)
+			}
+			if detected.Name() != tc.expected {
+				t.Errorf("Expected parser '%s', got '%s'", tc.expected, detected.Name())
+			}
+
+			// 识别后输入应重置到开头
+			if offset, _ := reader.Seek(0, io.SeekCurrent); offset != 0 {
+				t.Errorf("Expected reader rewound to start, got offset %d", offset)
+			}
+		})
+	}
+}
+
+func TestRegistry_DetectReportsEveryRejection(t *testing.T) {
+	registry := newTestRegistry()
+
+	_, err := registry.Detect(strings.NewReader("just some text"))
+
+	var detectionErr *parser.DetectionError
+	if !errors.As(err, &detectionErr) {
+		t.Fatalf("Expected DetectionError, got %v", err)
+	}
+	if len(detectionErr.Rejections) != len(registry.Names()) {
+		t.Errorf("Expected a rejection from every parser, got %v", detectionErr.Rejections)
+	}
+	for _, name := range registry.Names() {
+		if !strings.Contains(err.Error(), name+": ") {
+			t.Errorf("Expected error to mention parser '%s': %v", name, err)
+		}
+	}
+}
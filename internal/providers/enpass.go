@@ -2,10 +2,14 @@ package providers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"iter"
+	"slices"
 	"strings"
 
+	"github.com/yourorg/unpass/internal/parser"
 	"github.com/yourorg/unpass/internal/types"
 )
 
@@ -45,7 +49,15 @@ var fieldTypeMappings = map[EnpassFieldType]FieldTypeMapping{
 
 // EnpassData Enpass导出的原始数据结构
 type EnpassData struct {
-	Items []EnpassItem `json:"items"`
+	Folders []EnpassFolder `json:"folders"`
+	Items   []EnpassItem   `json:"items"`
+}
+
+// EnpassFolder Enpass文件夹（新版本界面中称为标签），可以嵌套
+type EnpassFolder struct {
+	UUID       string `json:"uuid"`
+	Title      string `json:"title"`
+	ParentUUID string `json:"parent_uuid"`
 }
 
 type EnpassItem struct {
@@ -54,9 +66,29 @@ type EnpassItem struct {
 	Title    string        `json:"title"`
 	UUID     string        `json:"uuid"`
 	Trashed  int           `json:"trashed"`
+	Folders  []string      `json:"folders"` // 所属文件夹的UUID
 	Fields   []EnpassField `json:"fields"`
 }
 
+// enpassFolders 文件夹UUID到文件夹的索引
+type enpassFolders map[string]EnpassFolder
+
+// path 返回文件夹的完整路径，嵌套文件夹用"/"连接
+func (f enpassFolders) path(uuid string) string {
+	var parts []string
+	seen := make(map[string]bool)
+	for uuid != "" && !seen[uuid] {
+		seen[uuid] = true
+		folder, exists := f[uuid]
+		if !exists {
+			break
+		}
+		parts = append([]string{folder.Title}, parts...)
+		uuid = folder.ParentUUID
+	}
+	return strings.Join(parts, "/")
+}
+
 type EnpassField struct {
 	Deleted   int    `json:"deleted"`
 	Label     string `json:"label"`
@@ -77,37 +109,137 @@ func (p *EnpassParser) Name() string {
 }
 
 func (p *EnpassParser) Parse(reader io.Reader) ([]types.Credential, error) {
-	var enpassData EnpassData
-	decoder := json.NewDecoder(reader)
+	return parser.Collect(p.Stream(reader))
+}
 
-	if err := decoder.Decode(&enpassData); err != nil {
-		return nil, fmt.Errorf("failed to decode Enpass JSON: %w", err)
-	}
+// Stream 逐个解码items中的项目，大型导出不需要整体读入内存
+func (p *EnpassParser) Stream(reader io.Reader) iter.Seq2[types.Credential, error] {
+	return func(yield func(types.Credential, error) bool) {
+		decoder := json.NewDecoder(reader)
+		err := p.walkItems(decoder, func(item EnpassItem, folders enpassFolders) bool {
+			// 跳过不符合条件的数据
+			if !p.shouldProcessItem(item) {
+				return true
+			}
 
-	var credentials []types.Credential
+			// 提取字段数据
+			credential := p.extractCredential(item)
+			credential.Tags = p.extractTags(item, folders)
 
-	for _, item := range enpassData.Items {
-		// 跳过不符合条件的数据
-		if !p.shouldProcessItem(item) {
-			continue
+			// 验证必要字段
+			if !isValidCredential(credential) {
+				return true
+			}
+			return yield(credential, nil)
+		})
+		if err != nil {
+			yield(types.Credential{}, fmt.Errorf("failed to decode Enpass JSON: %w", err))
 		}
+	}
+}
 
-		// 提取字段数据
-		credential := p.extractCredential(item)
-
-		// 验证必要字段
-		if p.isValidCredential(credential) {
-			credentials = append(credentials, credential)
+// walkItems 遍历顶层对象中items数组的每个项目，其他键直接跳过
+// Enpass导出中folders通常位于items之前；若items在前，引用了文件夹的项目先缓存，
+// 待folders读取后（或对象结束时）再访问，避免丢失标签
+func (p *EnpassParser) walkItems(decoder *json.Decoder, visit func(EnpassItem, enpassFolders) bool) error {
+	folders := make(enpassFolders)
+	foldersRead := false
+	var pending []EnpassItem
+
+	flush := func() bool {
+		for _, item := range pending {
+			if !visit(item, folders) {
+				return false
+			}
 		}
+		pending = nil
+		return true
 	}
 
-	return credentials, nil
+	next := true
+	err := parser.WalkJSONObject(decoder, func(key string) (bool, error) {
+		switch key {
+		case "items":
+		case "folders":
+			var list []EnpassFolder
+			if err := decoder.Decode(&list); err != nil {
+				return false, err
+			}
+			for _, folder := range list {
+				folders[folder.UUID] = folder
+			}
+			foldersRead = true
+			next = flush()
+			return next, nil
+		default:
+			return true, parser.SkipJSONValue(decoder)
+		}
+
+		err := parser.WalkJSONArray(decoder, func() (bool, error) {
+			var item EnpassItem
+			if err := decoder.Decode(&item); err != nil {
+				return false, err
+			}
+			if !foldersRead && len(item.Folders) > 0 {
+				pending = append(pending, item)
+				return true, nil
+			}
+			next = visit(item, folders)
+			return next, nil
+		})
+		return next, err
+	})
+	if err != nil || !next {
+		return err
+	}
+	// 导出中没有folders时，缓存的项目按无标签处理
+	flush()
+	return nil
 }
 
 func (p *EnpassParser) SupportedFormats() []string {
 	return []string{"enpass"}
 }
 
+// Detect Enpass导出的items带有category和fields，只检查第一个项目
+func (p *EnpassParser) Detect(reader io.ReadSeeker) (float64, error) {
+	decoder := json.NewDecoder(reader)
+	confidence := parser.ConfidenceNone
+	reason := errors.New("missing Enpass items array")
+
+	err := parser.WalkJSONObject(decoder, func(key string) (bool, error) {
+		if key != "items" {
+			return true, parser.SkipJSONValue(decoder)
+		}
+
+		if err := parser.ExpectJSONDelim(decoder, '['); err != nil {
+			return false, errors.New("items is not an array")
+		}
+		if !decoder.More() {
+			confidence, reason = parser.ConfidenceLow, nil
+			return false, nil
+		}
+
+		var first struct {
+			Category *string          `json:"category"`
+			Fields   *json.RawMessage `json:"fields"`
+		}
+		if err := parser.DecodeJSONProbe(decoder, &first); err != nil {
+			return false, err
+		}
+		if first.Category == nil || first.Fields == nil {
+			reason = errors.New("items have no category or fields")
+		} else {
+			confidence, reason = parser.ConfidenceHigh, nil
+		}
+		return false, nil
+	})
+	if err != nil {
+		return parser.ConfidenceNone, err
+	}
+	return confidence, reason
+}
+
 // shouldProcessItem 判断是否应该处理该项目
 func (p *EnpassParser) shouldProcessItem(item EnpassItem) bool {
 	// 跳过已归档的数据
@@ -120,19 +252,48 @@ func (p *EnpassParser) shouldProcessItem(item EnpassItem) bool {
 		return false
 	}
 
-	// 只处理login类型的数据
-	if item.Category != "login" {
-		return false
+	// login类型总是处理，其他类别（finance、computer、自定义模板等）需要同时有URL和密码
+	if item.Category == "login" {
+		return true
+	}
+
+	return p.hasURLAndPassword(item)
+}
+
+// hasURLAndPassword 判断项目是否包含非空的URL和密码字段
+func (p *EnpassParser) hasURLAndPassword(item EnpassItem) bool {
+	var hasURL, hasPassword bool
+	for _, field := range item.Fields {
+		if field.Deleted == 1 || strings.TrimSpace(field.Value) == "" {
+			continue
+		}
+		switch EnpassFieldType(field.Type) {
+		case FieldTypeURL:
+			hasURL = true
+		case FieldTypePassword:
+			hasPassword = true
+		}
 	}
+	return hasURL && hasPassword
+}
 
-	return true
+// extractTags 将项目所属的文件夹路径映射为标签
+func (p *EnpassParser) extractTags(item EnpassItem, folders enpassFolders) []string {
+	var tags []string
+	for _, uuid := range item.Folders {
+		if path := folders.path(uuid); path != "" && !slices.Contains(tags, path) {
+			tags = append(tags, path)
+		}
+	}
+	return tags
 }
 
 // extractCredential 从Enpass项目中提取凭据信息
 func (p *EnpassParser) extractCredential(item EnpassItem) types.Credential {
 	credential := types.Credential{
-		ID:    item.UUID,
-		Title: item.Title,
+		ID:       item.UUID,
+		Title:    item.Title,
+		Category: item.Category,
 	}
 
 	// 用于收集备注信息的字段
@@ -250,21 +411,6 @@ func (p *EnpassParser) cleanURL(rawURL string) string {
 	return rawURL
 }
 
-// isValidCredential 验证凭据是否有效
-func (p *EnpassParser) isValidCredential(credential types.Credential) bool {
-	// 必须有ID和标题
-	if credential.ID == "" || credential.Title == "" {
-		return false
-	}
-
-	// 必须有用户名或密码之一
-	if credential.Username == "" && credential.Password == "" {
-		return false
-	}
-
-	return true
-}
-
 // GetSupportedFieldTypes 获取支持的字段类型列表
 func GetSupportedFieldTypes() []EnpassFieldType {
 	var types []EnpassFieldType
@@ -419,6 +419,28 @@ func TestEnpassParser_ShouldProcessItem(t *testing.T) {
 			},
 			expected: false,
 		},
+		{
+			name: "finance item with url and password",
+			item: EnpassItem{
+				Category: "finance",
+				Fields: []EnpassField{
+					{Type: "url", Value: "https://bank.example.com"},
+					{Type: "password", Value: "secret"},
+				},
+			},
+			expected: true,
+		},
+		{
+			name: "computer item without url",
+			item: EnpassItem{
+				Category: "computer",
+				Fields: []EnpassField{
+					{Type: "password", Value: "secret"},
+					{Type: "url", Value: "https://deleted.example.com", Deleted: 1},
+				},
+			},
+			expected: false,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -615,3 +637,106 @@ func TestEnpassParser_TOTPAndPasskey(t *testing.T) {
 		t.Error("Expected Passkey information in Notes field")
 	}
 }
+
+func TestEnpassParser_FoldersAndCategories(t *testing.T) {
+	parser := NewEnpassParser()
+
+	testJSON := `{
+		"folders": [
+			{ "uuid": "f-work", "title": "Work", "parent_uuid": "" },
+			{ "uuid": "f-infra", "title": "Infra", "parent_uuid": "f-work" },
+			{ "uuid": "f-personal", "title": "Personal", "parent_uuid": "" }
+		],
+		"items": [
+			{
+				"category": "computer",
+				"title": "Build Server",
+				"uuid": "computer-1",
+				"folders": ["f-infra", "f-personal"],
+				"fields": [
+					{ "type": "username", "value": "root" },
+					{ "type": "password", "value": "s3cret" },
+					{ "type": "url", "value": "https://ci.example.com" }
+				]
+			},
+			{
+				"category": "finance",
+				"title": "Card PIN",
+				"uuid": "finance-1",
+				"fields": [
+					{ "type": "password", "value": "1234" }
+				]
+			}
+		]
+	}`
+
+	credentials, err := parser.Parse(strings.NewReader(testJSON))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	// 没有URL的非login项目应该被跳过
+	if len(credentials) != 1 {
+		t.Fatalf("Expected 1 credential, got %d", len(credentials))
+	}
+
+	cred := credentials[0]
+	if cred.Category != "computer" {
+		t.Errorf("Expected category 'computer', got '%s'", cred.Category)
+	}
+	if len(cred.Tags) != 2 || cred.Tags[0] != "Work/Infra" || cred.Tags[1] != "Personal" {
+		t.Errorf("Expected tags [Work/Infra Personal], got %v", cred.Tags)
+	}
+}
+
+// folders位于items之后时，标签仍应正确解析
+func TestEnpassParser_FoldersAfterItems(t *testing.T) {
+	parser := NewEnpassParser()
+
+	testJSON := `{
+		"items": [
+			{
+				"category": "login",
+				"title": "Build Server",
+				"uuid": "login-1",
+				"folders": ["f-infra"],
+				"fields": [
+					{ "type": "username", "value": "root" },
+					{ "type": "password", "value": "s3cret" }
+				]
+			},
+			{
+				"category": "login",
+				"title": "Mail",
+				"uuid": "login-2",
+				"fields": [
+					{ "type": "username", "value": "alice" },
+					{ "type": "password", "value": "pw" }
+				]
+			}
+		],
+		"folders": [
+			{ "uuid": "f-work", "title": "Work", "parent_uuid": "" },
+			{ "uuid": "f-infra", "title": "Infra", "parent_uuid": "f-work" }
+		]
+	}`
+
+	credentials, err := parser.Parse(strings.NewReader(testJSON))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(credentials) != 2 {
+		t.Fatalf("Expected 2 credentials, got %d", len(credentials))
+	}
+
+	for _, cred := range credentials {
+		if cred.Title != "Build Server" {
+			continue
+		}
+		if len(cred.Tags) != 1 || cred.Tags[0] != "Work/Infra" {
+			t.Errorf("Expected tags [Work/Infra], got %v", cred.Tags)
+		}
+		return
+	}
+	t.Error("Expected the Build Server credential")
+}
@@ -0,0 +1,301 @@
+package providers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yourorg/unpass/internal/parser"
+	"github.com/yourorg/unpass/internal/types"
+)
+
+// Firefox配置目录中保存密码的文件
+const (
+	firefoxLoginsFile = "logins.json"
+	firefoxKeyDBFile  = "key4.db"
+)
+
+// nssKeyIDPassword key4.db中metaData表保存全局盐和口令校验串的行
+const nssKeyIDPassword = "password"
+
+// ErrFirefoxProfileRequired 单独的logins.json无法解密，需要同目录的key4.db
+var ErrFirefoxProfileRequired = errors.New("encrypted Firefox logins.json must be read from its profile directory together with key4.db")
+
+// FirefoxLogins Firefox配置目录中logins.json的数据结构
+type FirefoxLogins struct {
+	Logins []FirefoxLogin `json:"logins"`
+}
+
+type FirefoxLogin struct {
+	Hostname          string  `json:"hostname"`
+	HTTPRealm         *string `json:"httpRealm"`
+	FormSubmitURL     *string `json:"formSubmitURL"`
+	EncryptedUsername string  `json:"encryptedUsername"`
+	EncryptedPassword string  `json:"encryptedPassword"`
+	GUID              string  `json:"guid"`
+}
+
+// FirefoxParser Firefox配置目录解析器，用key4.db中的密钥解密logins.json
+type FirefoxParser struct {
+	passphrase parser.PassphraseFunc
+}
+
+func NewFirefoxParser() *FirefoxParser {
+	return &FirefoxParser{}
+}
+
+// SetPassphraseFunc 设置主密码的来源，未设置主密码的配置目录不会调用
+func (p *FirefoxParser) SetPassphraseFunc(fn parser.PassphraseFunc) {
+	p.passphrase = fn
+}
+
+func (p *FirefoxParser) Name() string {
+	return "firefox"
+}
+
+// Parse 单独的logins.json缺少密钥，只能通过ParseDirectory读取整个配置目录
+func (p *FirefoxParser) Parse(reader io.Reader) ([]types.Credential, error) {
+	return nil, ErrFirefoxProfileRequired
+}
+
+func (p *FirefoxParser) SupportedFormats() []string {
+	return []string{"firefox"}
+}
+
+// Detect 单独的文件无法解密，遇到logins.json时在拒绝原因中提示读取配置目录
+func (p *FirefoxParser) Detect(reader io.ReadSeeker) (float64, error) {
+	decoder := json.NewDecoder(reader)
+	reason := errors.New("not a Firefox profile directory")
+
+	err := parser.WalkJSONObject(decoder, func(key string) (bool, error) {
+		if key == "logins" {
+			reason = ErrFirefoxProfileRequired
+			return false, nil
+		}
+		return true, parser.SkipJSONValue(decoder)
+	})
+	if err != nil {
+		return parser.ConfidenceNone, err
+	}
+	return parser.ConfidenceNone, reason
+}
+
+// DetectDirectory 配置目录需同时包含logins.json和key4.db
+func (p *FirefoxParser) DetectDirectory(dir string) (float64, error) {
+	for _, name := range []string{firefoxLoginsFile, firefoxKeyDBFile} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil || !info.Mode().IsRegular() {
+			return parser.ConfidenceNone, fmt.Errorf("directory does not contain %s", name)
+		}
+	}
+	return parser.ConfidenceCertain, nil
+}
+
+// ParseDirectory 解密配置目录中保存的密码，明文只存在于内存中
+func (p *FirefoxParser) ParseDirectory(dir string) ([]types.Credential, error) {
+	keys, err := p.loadKeys(filepath.Join(dir, firefoxKeyDBFile))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for _, key := range keys {
+			clear(key)
+		}
+	}()
+
+	file, err := os.Open(filepath.Join(dir, firefoxLoginsFile))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var firefoxLogins FirefoxLogins
+	if err := json.NewDecoder(file).Decode(&firefoxLogins); err != nil {
+		return nil, fmt.Errorf("failed to decode Firefox %s: %w", firefoxLoginsFile, err)
+	}
+
+	var credentials []types.Credential
+
+	for _, login := range firefoxLogins.Logins {
+		// 跳过不符合条件的数据
+		if !p.shouldProcessItem(login) {
+			continue
+		}
+
+		// 提取字段数据
+		credential, err := p.extractCredential(login, keys)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt login %s: %w", login.GUID, err)
+		}
+
+		// 验证必要字段
+		if isValidCredential(credential) {
+			credentials = append(credentials, credential)
+		}
+	}
+
+	return credentials, nil
+}
+
+// loadKeys 用主密码（未设置时为空）解开key4.db中的密钥，返回密钥ID到密钥的映射
+func (p *FirefoxParser) loadKeys(path string) (map[string][]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	db, err := openSQLite(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", firefoxKeyDBFile, err)
+	}
+
+	globalSalt, passwordCheck, err := p.readPasswordCheck(db)
+	if err != nil {
+		return nil, err
+	}
+
+	// 大多数配置没有设置主密码，先用空密码尝试
+	password := []byte{}
+	ok, err := checkNSSPassword(passwordCheck, globalSalt, password)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if p.passphrase == nil {
+			return nil, fmt.Errorf("Firefox profile is protected by a primary password: %w", parser.ErrPassphraseRequired)
+		}
+		password, err = p.passphrase("Firefox primary password: ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read passphrase: %w", err)
+		}
+		defer clear(password)
+
+		ok, err = checkNSSPassword(passwordCheck, globalSalt, password)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrFirefoxWrongPassword
+		}
+	}
+
+	table, err := db.table("nssPrivate")
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.rows(table)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string][]byte)
+	for _, row := range rows {
+		encryptedKey, _ := row["a11"].([]byte)
+		keyID, _ := row["a102"].([]byte)
+		if len(encryptedKey) == 0 || len(keyID) == 0 {
+			continue
+		}
+		key, err := decryptNSSItem(encryptedKey, globalSalt, password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt key %x: %w", keyID, err)
+		}
+		keys[string(keyID)] = key
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%s contains no login encryption keys", firefoxKeyDBFile)
+	}
+
+	return keys, nil
+}
+
+// readPasswordCheck 读取metaData表中的全局盐和加密的校验串
+func (p *FirefoxParser) readPasswordCheck(db *sqliteDB) ([]byte, []byte, error) {
+	table, err := db.table("metaData")
+	if err != nil {
+		return nil, nil, err
+	}
+	rows, err := db.rows(table)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, row := range rows {
+		if id, _ := row["id"].(string); id != nssKeyIDPassword {
+			continue
+		}
+		globalSalt, _ := row["item1"].([]byte)
+		passwordCheck, _ := row["item2"].([]byte)
+		if len(passwordCheck) == 0 {
+			break
+		}
+		return globalSalt, passwordCheck, nil
+	}
+	return nil, nil, fmt.Errorf("%s is missing the password check entry", firefoxKeyDBFile)
+}
+
+// shouldProcessItem 判断是否应该处理该登录
+func (p *FirefoxParser) shouldProcessItem(login FirefoxLogin) bool {
+	// 没有加密字段的记录无法解密
+	return login.EncryptedUsername != "" || login.EncryptedPassword != ""
+}
+
+// extractCredential 解密登录的用户名和密码
+func (p *FirefoxParser) extractCredential(login FirefoxLogin, keys map[string][]byte) (types.Credential, error) {
+	username, err := decryptFirefoxField(login.EncryptedUsername, keys)
+	if err != nil {
+		return types.Credential{}, fmt.Errorf("username: %w", err)
+	}
+	password, err := decryptFirefoxField(login.EncryptedPassword, keys)
+	if err != nil {
+		return types.Credential{}, fmt.Errorf("password: %w", err)
+	}
+
+	credential := types.Credential{
+		ID:       login.GUID,
+		Username: username,
+		Password: password,
+	}
+
+	// 表单提交地址与站点不同时作为第二个URL
+	var urls []string
+	if hostname := strings.TrimSpace(login.Hostname); hostname != "" {
+		urls = append(urls, hostname)
+	}
+	if login.FormSubmitURL != nil {
+		if formAction := strings.TrimSpace(*login.FormSubmitURL); formAction != "" && (len(urls) == 0 || formAction != urls[0]) {
+			urls = append(urls, formAction)
+		}
+	}
+	if len(urls) > 0 {
+		credential.URLs = urls
+		credential.URL = urls[0]
+	}
+
+	if login.HTTPRealm != nil && *login.HTTPRealm != "" {
+		credential.Notes = fmt.Sprintf("HTTP Realm: %s", *login.HTTPRealm)
+	}
+
+	credential.Title = hostnameTitle(credential.URL)
+
+	return credential, nil
+}
+
+// decryptFirefoxField 解密logins.json中Base64编码的加密字段
+func decryptFirefoxField(encoded string, keys map[string][]byte) (string, error) {
+	if encoded == "" {
+		return "", nil
+	}
+	der, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("invalid base64: %w", err)
+	}
+	plaintext, err := decryptNSSLogin(der, keys)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
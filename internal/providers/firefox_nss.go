@@ -0,0 +1,235 @@
+package providers
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"hash"
+)
+
+// NSS（Firefox的密钥库key4.db）使用的算法OID
+var (
+	oidPBES2              = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2             = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidHMACWithSHA1       = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA256     = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidAES256CBC          = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidDESEDE3CBC         = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+	oidPBEWithSHA1And3DES = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 5, 1, 3}
+)
+
+// nssPasswordCheck 用主密码解密后应得到的校验串
+const nssPasswordCheck = "password-check"
+
+// ErrFirefoxWrongPassword 主密码错误（password-check校验失败）
+var ErrFirefoxWrongPassword = errors.New("invalid Firefox primary password")
+
+// errNSSBadPadding 解密结果填充错误，通常意味着密钥（主密码）不对
+var errNSSBadPadding = errors.New("invalid NSS padding")
+
+// nssAlgorithm AlgorithmIdentifier
+type nssAlgorithm struct {
+	Algorithm  asn1.ObjectIdentifier
+	Parameters asn1.RawValue `asn1:"optional"`
+}
+
+// nssEncryptedItem key4.db中口令加密的条目：metaData.item2和nssPrivate.a11
+type nssEncryptedItem struct {
+	Algorithm  nssAlgorithm
+	Ciphertext []byte
+}
+
+// nssPBES2Params PBES2参数：密钥派生函数和加密算法
+type nssPBES2Params struct {
+	KeyDerivation nssAlgorithm
+	Encryption    nssAlgorithm
+}
+
+type nssPBKDF2Params struct {
+	Salt       []byte
+	Iterations int
+	KeyLength  int          `asn1:"optional"`
+	PRF        nssAlgorithm `asn1:"optional"`
+}
+
+// nssPBEParams 旧版本key4.db使用的PKCS#12 PBE参数
+type nssPBEParams struct {
+	Salt       []byte
+	Iterations int
+}
+
+// nssLoginData logins.json中encryptedUsername/encryptedPassword的结构
+type nssLoginData struct {
+	KeyID      []byte
+	Algorithm  nssAlgorithm
+	Ciphertext []byte
+}
+
+// checkNSSPassword 用主密码解密metaData中的校验串，密码错误时返回false
+// 格式错误或不支持的算法返回错误，以免被误报为密码错误
+func checkNSSPassword(passwordCheck, globalSalt, password []byte) (bool, error) {
+	plaintext, err := decryptNSSItem(passwordCheck, globalSalt, password)
+	if errors.Is(err, errNSSBadPadding) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to verify Firefox primary password: %w", err)
+	}
+	return string(plaintext) == nssPasswordCheck, nil
+}
+
+// decryptNSSItem 用全局盐和主密码解密key4.db中的条目
+func decryptNSSItem(der, globalSalt, password []byte) ([]byte, error) {
+	var item nssEncryptedItem
+	if _, err := asn1.Unmarshal(der, &item); err != nil {
+		return nil, fmt.Errorf("malformed NSS encrypted item: %w", err)
+	}
+
+	switch {
+	case item.Algorithm.Algorithm.Equal(oidPBES2):
+		return decryptNSSPBES2(item, globalSalt, password)
+	case item.Algorithm.Algorithm.Equal(oidPBEWithSHA1And3DES):
+		return decryptNSSPBE(item, globalSalt, password)
+	}
+	return nil, fmt.Errorf("unsupported NSS encryption algorithm: %s", item.Algorithm.Algorithm)
+}
+
+// decryptNSSPBES2 PBKDF2派生密钥后用AES-256-CBC解密，口令为SHA1(全局盐+主密码)
+func decryptNSSPBES2(item nssEncryptedItem, globalSalt, password []byte) ([]byte, error) {
+	var params nssPBES2Params
+	if _, err := asn1.Unmarshal(item.Algorithm.Parameters.FullBytes, &params); err != nil {
+		return nil, fmt.Errorf("malformed PBES2 parameters: %w", err)
+	}
+	if !params.KeyDerivation.Algorithm.Equal(oidPBKDF2) {
+		return nil, fmt.Errorf("unsupported NSS key derivation: %s", params.KeyDerivation.Algorithm)
+	}
+	if !params.Encryption.Algorithm.Equal(oidAES256CBC) {
+		return nil, fmt.Errorf("unsupported NSS cipher: %s", params.Encryption.Algorithm)
+	}
+
+	var kdf nssPBKDF2Params
+	if _, err := asn1.Unmarshal(params.KeyDerivation.Parameters.FullBytes, &kdf); err != nil {
+		return nil, fmt.Errorf("malformed PBKDF2 parameters: %w", err)
+	}
+	if kdf.Iterations < 1 {
+		return nil, fmt.Errorf("invalid PBKDF2 iterations: %d", kdf.Iterations)
+	}
+	keyLength := kdf.KeyLength
+	if keyLength == 0 {
+		keyLength = 32
+	}
+
+	var prf func() hash.Hash
+	switch {
+	case len(kdf.PRF.Algorithm) == 0 || kdf.PRF.Algorithm.Equal(oidHMACWithSHA1):
+		prf = sha1.New
+	case kdf.PRF.Algorithm.Equal(oidHMACWithSHA256):
+		prf = sha256.New
+	default:
+		return nil, fmt.Errorf("unsupported PBKDF2 PRF: %s", kdf.PRF.Algorithm)
+	}
+
+	var iv []byte
+	if _, err := asn1.Unmarshal(params.Encryption.Parameters.FullBytes, &iv); err != nil {
+		return nil, fmt.Errorf("malformed AES parameters: %w", err)
+	}
+	// NSS只保存IV的后14字节，前两字节是OCTET STRING的标签和长度
+	if len(iv) == aes.BlockSize-2 {
+		iv = append([]byte{0x04, 0x0e}, iv...)
+	}
+
+	passwordHash := sha1.Sum(append(append([]byte(nil), globalSalt...), password...))
+	key, err := pbkdf2.Key(prf, string(passwordHash[:]), kdf.Salt, kdf.Iterations, keyLength)
+	if err != nil {
+		return nil, fmt.Errorf("PBKDF2 key derivation failed: %w", err)
+	}
+	defer clear(key)
+
+	return decryptNSSCBC(aes.NewCipher, key, iv, item.Ciphertext)
+}
+
+// decryptNSSPBE 旧版本key4.db的PKCS#12风格派生，3DES-CBC解密
+func decryptNSSPBE(item nssEncryptedItem, globalSalt, password []byte) ([]byte, error) {
+	var params nssPBEParams
+	if _, err := asn1.Unmarshal(item.Algorithm.Parameters.FullBytes, &params); err != nil {
+		return nil, fmt.Errorf("malformed PBE parameters: %w", err)
+	}
+
+	hp := sha1.Sum(append(append([]byte(nil), globalSalt...), password...))
+	pes := make([]byte, sha1.Size)
+	copy(pes, params.Salt)
+	chp := sha1.Sum(append(hp[:], params.Salt...))
+
+	mac := func(data ...[]byte) []byte {
+		h := hmac.New(sha1.New, chp[:])
+		for _, d := range data {
+			h.Write(d)
+		}
+		return h.Sum(nil)
+	}
+	k1 := mac(pes, params.Salt)
+	tk := mac(pes)
+	k2 := mac(tk, params.Salt)
+	k := append(k1, k2...)
+	defer clear(k)
+
+	return decryptNSSCBC(des.NewTripleDESCipher, k[:24], k[len(k)-8:], item.Ciphertext)
+}
+
+// decryptNSSLogin 用key4.db中的密钥解密logins.json的字段，keys以密钥ID为键
+func decryptNSSLogin(der []byte, keys map[string][]byte) ([]byte, error) {
+	var login nssLoginData
+	if _, err := asn1.Unmarshal(der, &login); err != nil {
+		return nil, fmt.Errorf("malformed encrypted login field: %w", err)
+	}
+
+	key, exists := keys[string(login.KeyID)]
+	if !exists {
+		return nil, fmt.Errorf("key %x not found in key4.db", login.KeyID)
+	}
+
+	var iv []byte
+	if _, err := asn1.Unmarshal(login.Algorithm.Parameters.FullBytes, &iv); err != nil {
+		return nil, fmt.Errorf("malformed login cipher parameters: %w", err)
+	}
+
+	switch {
+	case login.Algorithm.Algorithm.Equal(oidDESEDE3CBC):
+		if len(key) < 24 {
+			return nil, errors.New("3DES key in key4.db is too short")
+		}
+		return decryptNSSCBC(des.NewTripleDESCipher, key[:24], iv, login.Ciphertext)
+	case login.Algorithm.Algorithm.Equal(oidAES256CBC):
+		if len(key) < 32 {
+			return nil, errors.New("AES key in key4.db is too short")
+		}
+		return decryptNSSCBC(aes.NewCipher, key[:32], iv, login.Ciphertext)
+	}
+	return nil, fmt.Errorf("unsupported login cipher: %s", login.Algorithm.Algorithm)
+}
+
+// decryptNSSCBC CBC模式解密并去除PKCS#7填充
+func decryptNSSCBC(newCipher func([]byte) (cipher.Block, error), key, iv, ciphertext []byte) ([]byte, error) {
+	block, err := newCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != block.BlockSize() || len(ciphertext)%block.BlockSize() != 0 {
+		return nil, fmt.Errorf("invalid CBC IV or ciphertext length")
+	}
+
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+	unpadded, err := unpadPKCS7(plaintext, block.BlockSize())
+	if err != nil {
+		return nil, errNSSBadPadding
+	}
+	return unpadded, nil
+}
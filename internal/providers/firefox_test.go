@@ -0,0 +1,98 @@
+package providers
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/yourorg/unpass/internal/parser"
+)
+
+// testdata/firefox中的key4.db由sqlite3生成：profile未设置主密码（PBES2，3DES和AES两个密钥），
+// primary设置了主密码"primary secret"（旧版PBE-SHA1-3DES，512字节页面以覆盖内部页和溢出页）
+const testFirefoxPrimaryPassword = "primary secret"
+
+func TestFirefoxParser_ParseDirectory(t *testing.T) {
+	firefox := NewFirefoxParser()
+	credentials, err := firefox.ParseDirectory(filepath.Join("testdata", "firefox", "profile"))
+	if err != nil {
+		t.Fatalf("ParseDirectory failed: %v", err)
+	}
+
+	if len(credentials) != 3 {
+		t.Fatalf("Expected 3 credentials, got %d", len(credentials))
+	}
+
+	// 3DES密钥加密的登录
+	cred1 := credentials[0]
+	if cred1.ID != "{2c8a7e0f-1f34-4d38-9b7e-5d1c4a1b0001}" || cred1.Title != "github.com" || cred1.Username != "alice" || cred1.Password != "hunter2" {
+		t.Errorf("Unexpected credential: %+v", cred1)
+	}
+	if !slices.Equal(cred1.URLs, []string{"https://github.com"}) {
+		t.Errorf("Expected identical form action to be merged, got %v", cred1.URLs)
+	}
+
+	// AES密钥加密的登录，表单提交地址作为第二个URL
+	cred2 := credentials[1]
+	if cred2.Username != "bob@example.com" || cred2.Password != "correct horse battery staple" {
+		t.Errorf("Unexpected AES credential: %+v", cred2)
+	}
+	if !slices.Equal(cred2.URLs, []string{"https://example.com", "https://accounts.example.com/session"}) {
+		t.Errorf("Expected form action as second URL, got %v", cred2.URLs)
+	}
+
+	cred3 := credentials[2]
+	if cred3.URL != "http://192.168.1.1" || cred3.Notes != "HTTP Realm: Router Admin" {
+		t.Errorf("Unexpected HTTP auth credential: %+v", cred3)
+	}
+}
+
+func TestFirefoxParser_PrimaryPassword(t *testing.T) {
+	dir := filepath.Join("testdata", "firefox", "primary")
+
+	// 没有口令来源时不应尝试解密
+	if _, err := NewFirefoxParser().ParseDirectory(dir); !errors.Is(err, parser.ErrPassphraseRequired) {
+		t.Errorf("Expected ErrPassphraseRequired, got %v", err)
+	}
+
+	firefox := NewFirefoxParser()
+	firefox.SetPassphraseFunc(staticPassphrase("wrong"))
+	if _, err := firefox.ParseDirectory(dir); !errors.Is(err, ErrFirefoxWrongPassword) {
+		t.Errorf("Expected ErrFirefoxWrongPassword, got %v", err)
+	}
+
+	firefox.SetPassphraseFunc(staticPassphrase(testFirefoxPrimaryPassword))
+	credentials, err := firefox.ParseDirectory(dir)
+	if err != nil {
+		t.Fatalf("ParseDirectory failed: %v", err)
+	}
+	if len(credentials) != 1 || credentials[0].Username != "carol" || credentials[0].Password != "s3cret!" || credentials[0].Title != "mail.example.org" {
+		t.Errorf("Unexpected credentials: %+v", credentials)
+	}
+}
+
+func TestFirefoxParser_Detect(t *testing.T) {
+	registry := newTestRegistry()
+
+	detected, ok := registry.DetectDirectory(filepath.Join("testdata", "firefox", "profile"))
+	if !ok || detected.Name() != "firefox" {
+		t.Errorf("Expected profile directory to be detected as firefox, got %v", detected)
+	}
+	if _, ok := registry.DetectDirectory("testdata"); ok {
+		t.Error("Expected directory without key4.db not to be detected")
+	}
+
+	// 单独的logins.json无法解密，拒绝原因应提示读取配置目录
+	file, err := os.Open(filepath.Join("testdata", "firefox", "profile", firefoxLoginsFile))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer file.Close()
+	_, err = registry.Detect(file)
+	if err == nil || !strings.Contains(err.Error(), "profile directory") {
+		t.Errorf("Expected detection to point at the profile directory, got %v", err)
+	}
+}
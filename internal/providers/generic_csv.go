@@ -0,0 +1,359 @@
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"slices"
+	"strings"
+
+	"github.com/yourorg/unpass/internal/parser"
+	"github.com/yourorg/unpass/internal/types"
+)
+
+// 多值字段的分隔符
+const (
+	csvURLSeparators = ",; \t\r\n|"
+	csvTagSeparators = ",;\r\n|"
+)
+
+// ErrNoCSVMapping 没有指定映射，表头也不匹配任何内置预设
+var ErrNoCSVMapping = errors.New("no CSV mapping matches header (use --map or --csv-preset)")
+
+// csvLayout 映射解析到具体列下标后的结果
+type csvLayout struct {
+	mapping *CSVMapping
+	columns map[CSVField][]int
+	labels  []string     // 列名，用于备注中的标签
+	skip    map[int]bool // 已映射或忽略的列
+	extras  int          // ExtraPairs时成对字段的起始列
+}
+
+// GenericCSVParser 按列映射解析任意CSV导出
+type GenericCSVParser struct {
+	mapping *CSVMapping // 为nil时按表头识别内置预设
+}
+
+func NewGenericCSVParser() *GenericCSVParser {
+	return &GenericCSVParser{}
+}
+
+// SetMapping 设置列映射，设置后不再自动识别预设
+func (p *GenericCSVParser) SetMapping(mapping *CSVMapping) {
+	p.mapping = mapping
+}
+
+func (p *GenericCSVParser) Name() string {
+	return "csv"
+}
+
+func (p *GenericCSVParser) Parse(reader io.Reader) ([]types.Credential, error) {
+	return parser.Collect(p.Stream(reader))
+}
+
+// Stream 逐行解析CSV
+func (p *GenericCSVParser) Stream(reader io.Reader) iter.Seq2[types.Credential, error] {
+	return func(yield func(types.Credential, error) bool) {
+		csvReader := newCSVReader(reader)
+
+		first, err := csvReader.Read()
+		if err != nil {
+			yield(types.Credential{}, fmt.Errorf("failed to read CSV header: %w", err))
+			return
+		}
+
+		mapping, err := p.resolveMapping(first)
+		if err != nil {
+			yield(types.Credential{}, err)
+			return
+		}
+		layout := newCSVLayout(mapping, first)
+
+		// 没有表头时第一行就是数据
+		row := 1
+		record := first
+		if mapping.HasHeader {
+			record = nil
+		}
+
+		for ; ; row++ {
+			if record == nil {
+				record, err = csvReader.Read()
+				if err == io.EOF {
+					return
+				}
+				if err != nil {
+					yield(types.Credential{}, fmt.Errorf("failed to read CSV row %d: %w", row, err))
+					return
+				}
+			}
+
+			// 提取字段数据
+			credential := p.extractCredential(layout, record, row)
+			record = nil
+
+			// 验证必要字段
+			if isValidCredential(credential) && !yield(credential, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (p *GenericCSVParser) SupportedFormats() []string {
+	return []string{"csv"}
+}
+
+// Detect 指定了映射时检查表头是否包含映射的列，否则按表头识别内置预设
+func (p *GenericCSVParser) Detect(reader io.ReadSeeker) (float64, error) {
+	first, err := newCSVReader(io.LimitReader(reader, csvHeaderLimit)).Read()
+	if err != nil {
+		return parser.ConfidenceNone, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	if p.mapping == nil {
+		if _, found := detectCSVPreset(parseCSVHeader(first)); !found {
+			return parser.ConfidenceNone, ErrNoCSVMapping
+		}
+		return parser.ConfidenceHigh, nil
+	}
+
+	if !p.mapping.HasHeader {
+		// 无表头时只能检查列数，置信度低于能识别表头的解析器
+		layout := newCSVLayout(p.mapping, first)
+		for _, indexes := range layout.columns {
+			for _, index := range indexes {
+				if index >= len(first) {
+					return parser.ConfidenceNone, fmt.Errorf("row has %d columns, mapping expects at least %d", len(first), index+1)
+				}
+			}
+		}
+		return parser.ConfidenceMedium, nil
+	}
+
+	// 用户明确给出的映射与表头吻合时优先使用
+	if missing := missingCSVColumns(p.mapping, parseCSVHeader(first)); len(missing) > 0 {
+		return parser.ConfidenceNone, fmt.Errorf("header has no column for mapped field(s): %s", strings.Join(missing, ", "))
+	}
+	return parser.ConfidenceCertain, nil
+}
+
+// resolveMapping 返回设置的映射，未设置时按表头识别预设
+func (p *GenericCSVParser) resolveMapping(first []string) (*CSVMapping, error) {
+	if p.mapping != nil {
+		return p.mapping, nil
+	}
+	mapping, found := detectCSVPreset(parseCSVHeader(first))
+	if !found {
+		return nil, ErrNoCSVMapping
+	}
+	return mapping, nil
+}
+
+// detectCSVPreset 按名称顺序查找表头匹配的预设
+func detectCSVPreset(header csvHeader) (*CSVMapping, bool) {
+	for _, name := range CSVPresetNames() {
+		preset := csvPresets[name]
+		if preset.HasHeader && len(preset.Detect) > 0 && header.has(preset.Detect) {
+			return preset.clone(), true
+		}
+	}
+	return nil, false
+}
+
+// missingCSVColumns 返回在表头中找不到任何映射列的字段
+func missingCSVColumns(mapping *CSVMapping, header csvHeader) []string {
+	var missing []string
+	for _, field := range csvFields {
+		columns := mapping.Columns[field]
+		if len(columns) == 0 {
+			continue
+		}
+		found := false
+		for _, column := range columns {
+			if _, err := parseCSVColumnIndex(column); err == nil {
+				found = true
+				break
+			}
+			if _, exists := header[strings.ToLower(column)]; exists {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, string(field))
+		}
+	}
+	return missing
+}
+
+// newCSVLayout 将映射中的列名和序号解析为列下标
+func newCSVLayout(mapping *CSVMapping, first []string) *csvLayout {
+	layout := &csvLayout{
+		mapping: mapping,
+		columns: make(map[CSVField][]int),
+		labels:  make([]string, len(first)),
+		skip:    make(map[int]bool),
+	}
+
+	var header csvHeader
+	if mapping.HasHeader {
+		header = parseCSVHeader(first)
+	}
+	for i := range first {
+		if mapping.HasHeader {
+			layout.labels[i] = strings.TrimSpace(strings.TrimPrefix(first[i], "\ufeff"))
+		} else {
+			layout.labels[i] = fmt.Sprintf("Column %d", i+1)
+		}
+	}
+
+	resolve := func(column string) []int {
+		if index, err := parseCSVColumnIndex(column); err == nil {
+			return []int{index}
+		}
+		return header[strings.ToLower(column)]
+	}
+
+	for field, columns := range mapping.Columns {
+		for _, column := range columns {
+			for _, index := range resolve(column) {
+				layout.columns[field] = append(layout.columns[field], index)
+				layout.skip[index] = true
+				layout.extras = max(layout.extras, index+1)
+			}
+		}
+	}
+	for _, column := range mapping.Ignore {
+		for _, index := range resolve(column) {
+			layout.skip[index] = true
+		}
+	}
+
+	return layout
+}
+
+// value 返回字段映射列中第一个非空值
+func (l *csvLayout) value(record []string, field CSVField) string {
+	for _, index := range l.columns[field] {
+		if index < len(record) {
+			if value := strings.TrimSpace(record[index]); value != "" {
+				return value
+			}
+		}
+	}
+	return ""
+}
+
+// rawValue 与value相同但不去除空白，用于密码和TOTP密钥：首尾空格是密码的一部分
+func (l *csvLayout) rawValue(record []string, field CSVField) string {
+	for _, index := range l.columns[field] {
+		if index < len(record) && record[index] != "" {
+			return record[index]
+		}
+	}
+	return ""
+}
+
+// values 合并字段所有映射列的值，并按分隔符拆分
+func (l *csvLayout) values(record []string, field CSVField, separators string) []string {
+	var values []string
+	for _, index := range l.columns[field] {
+		if index >= len(record) {
+			continue
+		}
+		for _, part := range strings.FieldsFunc(record[index], func(r rune) bool {
+			return strings.ContainsRune(separators, r)
+		}) {
+			// NordPass等导出的多值列为JSON数组形式
+			part = strings.Trim(strings.TrimSpace(part), `[]"`)
+			if part != "" && !slices.Contains(values, part) {
+				values = append(values, part)
+			}
+		}
+	}
+	return values
+}
+
+// extractCredential 从CSV行中提取凭据信息
+func (p *GenericCSVParser) extractCredential(layout *csvLayout, record []string, row int) types.Credential {
+	credential := types.Credential{
+		ID:       layout.value(record, CSVFieldID),
+		Title:    layout.value(record, CSVFieldTitle),
+		Username: layout.value(record, CSVFieldUsername),
+		Password: layout.rawValue(record, CSVFieldPassword),
+		TOTP:     layout.rawValue(record, CSVFieldTOTP),
+		Category: layout.value(record, CSVFieldCategory),
+	}
+
+	if credential.ID == "" {
+		credential.ID = fmt.Sprintf("%s-%d", layout.mapping.Name, row)
+	}
+
+	if urls := layout.values(record, CSVFieldURL, csvURLSeparators); len(urls) > 0 {
+		credential.URLs = urls
+		credential.URL = urls[0]
+	}
+
+	for _, tag := range layout.values(record, CSVFieldTags, csvTagSeparators) {
+		// RoboForm的文件夹路径以"/"开头
+		if tag = strings.Trim(tag, "/"); tag != "" {
+			credential.Tags = append(credential.Tags, tag)
+		}
+	}
+
+	// 用于收集备注信息的字段
+	var notes []string
+	if note := layout.value(record, CSVFieldNotes); note != "" {
+		notes = append(notes, note)
+	}
+	p.processUnmappedColumns(&credential, &notes, layout, record)
+	if len(notes) > 0 {
+		credential.Notes = strings.Join(notes, "; ")
+	}
+
+	// 没有标题时使用主机名
+	if credential.Title == "" {
+		credential.Title = hostnameTitle(credential.URL)
+	}
+
+	return credential
+}
+
+// processUnmappedColumns 未映射的列作为文本字段放入备注，otpauth URI作为TOTP
+func (p *GenericCSVParser) processUnmappedColumns(credential *types.Credential, notes *[]string, layout *csvLayout, record []string) {
+	for i := 0; i < len(record); i++ {
+		if layout.skip[i] {
+			continue
+		}
+
+		label := ""
+		if i < len(layout.labels) {
+			label = layout.labels[i]
+		}
+		value := strings.TrimSpace(record[i])
+
+		// 成对出现的自定义字段：名称在前，值在后
+		if layout.mapping.ExtraPairs && i >= layout.extras {
+			label = value
+			value = ""
+			if i+1 < len(record) {
+				value = strings.TrimSpace(record[i+1])
+			}
+			i++
+		}
+
+		if value == "" {
+			continue
+		}
+		if credential.TOTP == "" && strings.HasPrefix(strings.ToLower(value), "otpauth://") {
+			credential.TOTP = value
+			continue
+		}
+		if label == "" {
+			label = fmt.Sprintf("Column %d", i+1)
+		}
+		*notes = append(*notes, fmt.Sprintf("%s: %s", label, value))
+	}
+}
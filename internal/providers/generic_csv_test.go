@@ -0,0 +1,197 @@
+package providers
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGenericCSVParser_CustomMapping(t *testing.T) {
+	data := "Account,Login URL,User,Secret,OTP Secret,Folder,Security Question\n" +
+		"GitHub,\"https://github.com, https://gist.github.com\",alice,hunter2,otpauth://totp/GitHub?secret=JBSWY3DPEHPK3PXP,Work;Dev,First pet\n" +
+		",https://example.com/login, bob ,\" pw \",,,\n" +
+		"Empty,https://empty.com,,,,,\n"
+
+	mapping := NewCSVMapping()
+	if err := mapping.Apply("title=Account,url=Login URL,username=User,password=Secret\ntotp=OTP Secret,tags=Folder"); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	parser := NewGenericCSVParser()
+	parser.SetMapping(mapping)
+	credentials, err := parser.Parse(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	// 没有用户名和密码的行应该被跳过
+	if len(credentials) != 2 {
+		t.Fatalf("Expected 2 credentials, got %d", len(credentials))
+	}
+
+	cred1 := credentials[0]
+	if cred1.ID != "csv-1" || cred1.Title != "GitHub" || cred1.Username != "alice" || cred1.Password != "hunter2" {
+		t.Errorf("Unexpected credential: %+v", cred1)
+	}
+	if !slices.Equal(cred1.URLs, []string{"https://github.com", "https://gist.github.com"}) || cred1.URL != "https://github.com" {
+		t.Errorf("Expected URL column split into URLs, got %v", cred1.URLs)
+	}
+	if !slices.Equal(cred1.Tags, []string{"Work", "Dev"}) {
+		t.Errorf("Expected folder column split into tags, got %v", cred1.Tags)
+	}
+	if !strings.HasPrefix(cred1.TOTP, "otpauth://") {
+		t.Errorf("Expected TOTP mapped, got '%s'", cred1.TOTP)
+	}
+
+	// 未映射的列应保留在备注中
+	if cred1.Notes != "Security Question: First pet" {
+		t.Errorf("Expected unknown column in notes, got '%s'", cred1.Notes)
+	}
+
+	// 密码首尾的空格是密码的一部分，其他列照常去除空白
+	if credentials[1].Username != "bob" || credentials[1].Password != " pw " {
+		t.Errorf("Unexpected username/password: %q/%q", credentials[1].Username, credentials[1].Password)
+	}
+
+	// 没有标题时使用主机名
+	if credentials[1].Title != "example.com" {
+		t.Errorf("Expected hostname title, got '%s'", credentials[1].Title)
+	}
+}
+
+func TestGenericCSVParser_Presets(t *testing.T) {
+	t.Run("nordpass", func(t *testing.T) {
+		data := "name,url,additional_urls,username,password,note,cardholdername,cardnumber,cvc,expirydate,zipcode,folder,full_name,phone_number,email,address1,address2,city,country,state,type\n" +
+			"GitHub,https://github.com,\"[\"\"https://gist.github.com\"\"]\",,hunter2,,,,,,,Work,,,alice@example.com,,,,,,password\n"
+
+		parser := NewGenericCSVParser()
+		credentials, err := parser.Parse(strings.NewReader(data))
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		if len(credentials) != 1 {
+			t.Fatalf("Expected 1 credential, got %d", len(credentials))
+		}
+
+		cred := credentials[0]
+		if cred.ID != "nordpass-1" || cred.Username != "alice@example.com" || cred.Category != "password" {
+			t.Errorf("Unexpected credential: %+v", cred)
+		}
+		if !slices.Equal(cred.URLs, []string{"https://github.com", "https://gist.github.com"}) {
+			t.Errorf("Expected additional URLs merged, got %v", cred.URLs)
+		}
+		if !slices.Equal(cred.Tags, []string{"Work"}) || cred.Notes != "" {
+			t.Errorf("Expected folder tag and no notes, got %v / '%s'", cred.Tags, cred.Notes)
+		}
+	})
+
+	t.Run("roboform", func(t *testing.T) {
+		data := "Name,Url,MatchUrl,Login,Pwd,Note,Folder,RfFieldsV2\n" +
+			"GitHub,https://github.com/login,https://github.com,alice,hunter2,,/Work/Dev,\"field,value\"\n"
+
+		parser := NewGenericCSVParser()
+		credentials, err := parser.Parse(strings.NewReader(data))
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		if len(credentials) != 1 {
+			t.Fatalf("Expected 1 credential, got %d", len(credentials))
+		}
+		if cred := credentials[0]; cred.Username != "alice" || !slices.Equal(cred.Tags, []string{"Work/Dev"}) || cred.Notes != "" {
+			t.Errorf("Unexpected credential: %+v", cred)
+		}
+	})
+
+	t.Run("keeper", func(t *testing.T) {
+		data := "Work,GitHub,alice,hunter2,https://github.com,,Shared,TFC:Keeper,otpauth://totp/GitHub?secret=JBSWY3DPEHPK3PXP,Recovery PIN,1234\n"
+
+		mapping, exists := CSVPreset("keeper")
+		if !exists {
+			t.Fatal("Expected keeper preset")
+		}
+		parser := NewGenericCSVParser()
+		parser.SetMapping(mapping)
+		credentials, err := parser.Parse(strings.NewReader(data))
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		if len(credentials) != 1 {
+			t.Fatalf("Expected 1 credential, got %d", len(credentials))
+		}
+
+		// 无表头时第一行也是数据，自定义字段成对出现
+		cred := credentials[0]
+		if cred.ID != "keeper-1" || cred.Title != "GitHub" || cred.Password != "hunter2" {
+			t.Errorf("Unexpected credential: %+v", cred)
+		}
+		if !slices.Equal(cred.Tags, []string{"Work", "Shared"}) {
+			t.Errorf("Expected folder and shared folder tags, got %v", cred.Tags)
+		}
+		if !strings.HasPrefix(cred.TOTP, "otpauth://") {
+			t.Errorf("Expected otpauth custom field as TOTP, got '%s'", cred.TOTP)
+		}
+		if cred.Notes != "Recovery PIN: 1234" {
+			t.Errorf("Expected custom field in notes, got '%s'", cred.Notes)
+		}
+	})
+}
+
+func TestGenericCSVParser_NoMapping(t *testing.T) {
+	parser := NewGenericCSVParser()
+	_, err := parser.Parse(strings.NewReader("foo,bar\n1,2\n"))
+	if !errors.Is(err, ErrNoCSVMapping) {
+		t.Errorf("Expected ErrNoCSVMapping, got %v", err)
+	}
+}
+
+func TestCSVMapping_Apply(t *testing.T) {
+	mapping, _ := CSVPreset("roboform")
+	if err := mapping.Apply("# override\nurl=MatchUrl\nurl=Url\nheader=true"); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if !slices.Equal(mapping.Columns[CSVFieldURL], []string{"MatchUrl", "Url"}) {
+		t.Errorf("Expected url columns replaced then appended, got %v", mapping.Columns[CSVFieldURL])
+	}
+
+	// 预设本身不应被修改
+	if preset, _ := CSVPreset("roboform"); !slices.Equal(preset.Columns[CSVFieldURL], []string{"Url"}) {
+		t.Errorf("Expected preset unchanged, got %v", preset.Columns[CSVFieldURL])
+	}
+
+	for _, spec := range []string{"url", "website=URL", "url=#0", "header=maybe"} {
+		if err := NewCSVMapping().Apply(spec); err == nil {
+			t.Errorf("Expected error for rule %q", spec)
+		}
+	}
+}
+
+func TestRegistry_DetectGenericCSV(t *testing.T) {
+	registry := newTestRegistry()
+
+	// 带有额外列的表头交给预设，而不是浏览器解析器
+	lastpass := "url,username,password,totp,extra,name,grouping,fav\nhttps://github.com,alice,pw,,,GitHub,Work,0\n"
+	detected, err := registry.Detect(bytes.NewReader([]byte(lastpass)))
+	if err != nil {
+		t.Fatalf("Detect failed: %v", err)
+	}
+	if detected.Name() != "csv" {
+		t.Errorf("Expected parser 'csv', got '%s'", detected.Name())
+	}
+
+	// 明确指定的映射优先于浏览器方言
+	mapping := NewCSVMapping()
+	if err := mapping.Apply("title=name,url=url,username=username,password=password,notes=note"); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	csvParser, _ := registry.Get("csv")
+	csvParser.(*GenericCSVParser).SetMapping(mapping)
+	detected, err = registry.Detect(bytes.NewReader([]byte("name,url,username,password,note\nGitHub,https://github.com,alice,pw,\n")))
+	if err != nil {
+		t.Fatalf("Detect failed: %v", err)
+	}
+	if detected.Name() != "csv" {
+		t.Errorf("Expected parser 'csv', got '%s'", detected.Name())
+	}
+}
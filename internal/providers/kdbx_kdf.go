@@ -0,0 +1,370 @@
+package providers
+
+import (
+	"crypto/aes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+	"sync"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// KDBX支持的密钥派生函数UUID
+var (
+	kdfUUIDAESKDF   = mustParseUUID("c9d9f39a-628a-4460-bf74-0d08c18a4fea")
+	kdfUUIDAESKDF4  = mustParseUUID("7c02bb82-79a7-4ac0-927d-114a00648238")
+	kdfUUIDArgon2d  = mustParseUUID("ef636ddf-8c29-444b-91f7-a9a403e30a0c")
+	kdfUUIDArgon2id = mustParseUUID("9e298b19-56db-4773-b23d-fc3ec6f0a1e6")
+)
+
+const (
+	argon2Version13  uint32 = 0x13
+	argon2BlockWords        = 128 // 每个1KiB块包含的64位字数
+)
+
+// argon2Type Argon2变体（与RFC 9106中的y值一致）
+type argon2Type uint32
+
+const (
+	argon2d  argon2Type = 0
+	argon2id argon2Type = 2
+)
+
+// argon2Params Argon2参数，secret和data对应KDBX中的可选K/A参数
+type argon2Params struct {
+	typ         argon2Type
+	salt        []byte
+	secret      []byte
+	data        []byte
+	iterations  uint32
+	memoryKiB   uint32
+	parallelism uint32
+	keyLen      uint32
+}
+
+// transformKDBXKey 按KDF参数变换复合密钥
+func transformKDBXKey(compositeKey []byte, params kdbxVariantDictionary) ([]byte, error) {
+	kdfUUID, ok := params.bytes("$UUID")
+	if !ok {
+		return nil, fmt.Errorf("KDF parameters are missing $UUID")
+	}
+
+	switch uuid := string(kdfUUID); uuid {
+	case string(kdfUUIDAESKDF[:]), string(kdfUUIDAESKDF4[:]):
+		seed, ok := params.bytes("S")
+		if !ok || len(seed) != 32 {
+			return nil, fmt.Errorf("AES-KDF seed is missing or invalid")
+		}
+		rounds, ok := params.uint64("R")
+		if !ok {
+			return nil, fmt.Errorf("AES-KDF rounds are missing")
+		}
+		return aesKDF(compositeKey, seed, rounds)
+
+	case string(kdfUUIDArgon2d[:]), string(kdfUUIDArgon2id[:]):
+		typ := argon2d
+		if uuid == string(kdfUUIDArgon2id[:]) {
+			typ = argon2id
+		}
+		salt, ok := params.bytes("S")
+		if !ok {
+			return nil, fmt.Errorf("Argon2 salt is missing")
+		}
+		iterations, okI := params.uint64("I")
+		memory, okM := params.uint64("M")
+		parallelism, okP := params.uint32("P")
+		if !okI || !okM || !okP {
+			return nil, fmt.Errorf("Argon2 parameters are incomplete")
+		}
+		if version, ok := params.uint32("V"); ok && version != argon2Version13 {
+			return nil, fmt.Errorf("unsupported Argon2 version: 0x%x", version)
+		}
+		if iterations == 0 || iterations > 1<<32-1 || memory < 8*1024 || memory/1024 > 1<<32-1 || parallelism == 0 || parallelism > 1<<24-1 {
+			return nil, fmt.Errorf("Argon2 parameters out of range")
+		}
+		secret, _ := params.bytes("K")
+		data, _ := params.bytes("A")
+		return argon2Key(compositeKey, argon2Params{
+			typ:         typ,
+			salt:        salt,
+			secret:      secret,
+			data:        data,
+			iterations:  uint32(iterations),
+			memoryKiB:   uint32(memory / 1024),
+			parallelism: parallelism,
+			keyLen:      32,
+		}), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported KDF: %x", kdfUUID)
+	}
+}
+
+// aesKDF 用种子作为AES-256密钥对复合密钥做rounds轮ECB加密，再取SHA-256
+func aesKDF(compositeKey, seed []byte, rounds uint64) ([]byte, error) {
+	block, err := aes.NewCipher(seed)
+	if err != nil {
+		return nil, err
+	}
+
+	key := make([]byte, 32)
+	copy(key, compositeKey)
+	defer clear(key)
+	for i := uint64(0); i < rounds; i++ {
+		block.Encrypt(key[:16], key[:16])
+		block.Encrypt(key[16:], key[16:])
+	}
+
+	sum := sha256.Sum256(key)
+	return sum[:], nil
+}
+
+// argon2Key Argon2d/Argon2id实现（RFC 9106，版本0x13）
+// golang.org/x/crypto/argon2不提供KeePassXC默认使用的Argon2d
+func argon2Key(password []byte, p argon2Params) []byte {
+	lanes := p.parallelism
+	memory := p.memoryKiB
+	if memory < 8*lanes {
+		memory = 8 * lanes
+	}
+	segmentLen := memory / (4 * lanes)
+	laneLen := segmentLen * 4
+	memory = laneLen * lanes
+
+	h0 := argon2InitialHash(password, p)
+	blocks := make([][argon2BlockWords]uint64, memory)
+	defer clear(blocks)
+
+	// 每条lane的前两个块
+	var input [blake2b.Size + 8]byte
+	copy(input[:], h0[:])
+	for lane := uint32(0); lane < lanes; lane++ {
+		for i := uint32(0); i < 2; i++ {
+			binary.LittleEndian.PutUint32(input[blake2b.Size:], i)
+			binary.LittleEndian.PutUint32(input[blake2b.Size+4:], lane)
+			out := argon2Hash(input[:], 1024)
+			for w := range blocks[lane*laneLen+i] {
+				blocks[lane*laneLen+i][w] = binary.LittleEndian.Uint64(out[w*8:])
+			}
+		}
+	}
+
+	for pass := uint32(0); pass < p.iterations; pass++ {
+		for slice := uint32(0); slice < 4; slice++ {
+			var wg sync.WaitGroup
+			for lane := uint32(0); lane < lanes; lane++ {
+				wg.Add(1)
+				go func(lane uint32) {
+					defer wg.Done()
+					argon2FillSegment(blocks, p, memory, pass, lane, slice, lanes, laneLen, segmentLen)
+				}(lane)
+			}
+			wg.Wait()
+		}
+	}
+
+	// 最后一列所有块异或后输出
+	final := blocks[laneLen-1]
+	for lane := uint32(1); lane < lanes; lane++ {
+		last := &blocks[lane*laneLen+laneLen-1]
+		for w := range final {
+			final[w] ^= last[w]
+		}
+	}
+	var finalBytes [1024]byte
+	for w, v := range final {
+		binary.LittleEndian.PutUint64(finalBytes[w*8:], v)
+	}
+	return argon2Hash(finalBytes[:], p.keyLen)
+}
+
+// argon2InitialHash 计算H0
+func argon2InitialHash(password []byte, p argon2Params) [blake2b.Size]byte {
+	h, _ := blake2b.New512(nil)
+	var params [24]byte
+	binary.LittleEndian.PutUint32(params[0:], p.parallelism)
+	binary.LittleEndian.PutUint32(params[4:], p.keyLen)
+	binary.LittleEndian.PutUint32(params[8:], p.memoryKiB)
+	binary.LittleEndian.PutUint32(params[12:], p.iterations)
+	binary.LittleEndian.PutUint32(params[16:], argon2Version13)
+	binary.LittleEndian.PutUint32(params[20:], uint32(p.typ))
+	h.Write(params[:])
+	for _, field := range [][]byte{password, p.salt, p.secret, p.data} {
+		var length [4]byte
+		binary.LittleEndian.PutUint32(length[:], uint32(len(field)))
+		h.Write(length[:])
+		h.Write(field)
+	}
+
+	var h0 [blake2b.Size]byte
+	copy(h0[:], h.Sum(nil))
+	return h0
+}
+
+// argon2Hash 变长哈希函数H'
+func argon2Hash(input []byte, outLen uint32) []byte {
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], outLen)
+
+	if outLen <= blake2b.Size {
+		h, _ := blake2b.New(int(outLen), nil)
+		h.Write(length[:])
+		h.Write(input)
+		return h.Sum(nil)
+	}
+
+	// r = ceil(T/32) - 2，前r块各取32字节，最后一块输出剩余长度
+	r := (outLen+31)/32 - 2
+	out := make([]byte, 0, outLen)
+	h, _ := blake2b.New512(nil)
+	h.Write(length[:])
+	h.Write(input)
+	v := h.Sum(nil)
+	for i := uint32(1); i < r; i++ {
+		out = append(out, v[:32]...)
+		sum := blake2b.Sum512(v)
+		v = sum[:]
+	}
+	out = append(out, v[:32]...)
+
+	last, _ := blake2b.New(int(outLen-32*r), nil)
+	last.Write(v)
+	return last.Sum(out)
+}
+
+// argon2FillSegment 填充某条lane中的一个segment
+func argon2FillSegment(blocks [][argon2BlockWords]uint64, p argon2Params, memory, pass, lane, slice, lanes, laneLen, segmentLen uint32) {
+	dataIndependent := p.typ == argon2id && pass == 0 && slice < 2
+
+	var address, input, zero [argon2BlockWords]uint64
+	if dataIndependent {
+		input[0] = uint64(pass)
+		input[1] = uint64(lane)
+		input[2] = uint64(slice)
+		input[3] = uint64(memory)
+		input[4] = uint64(p.iterations)
+		input[5] = uint64(p.typ)
+	}
+
+	index := uint32(0)
+	if pass == 0 && slice == 0 {
+		index = 2 // 前两个块已初始化
+		if dataIndependent {
+			input[6]++
+			argon2ProcessBlock(&address, &input, &zero, false)
+			argon2ProcessBlock(&address, &address, &zero, false)
+		}
+	}
+
+	offset := lane*laneLen + slice*segmentLen + index
+	for ; index < segmentLen; index, offset = index+1, offset+1 {
+		prev := offset - 1
+		if index == 0 && slice == 0 {
+			prev += laneLen // lane首块引用lane末块
+		}
+
+		var random uint64
+		if dataIndependent {
+			if index%argon2BlockWords == 0 {
+				input[6]++
+				argon2ProcessBlock(&address, &input, &zero, false)
+				argon2ProcessBlock(&address, &address, &zero, false)
+			}
+			random = address[index%argon2BlockWords]
+		} else {
+			random = blocks[prev][0]
+		}
+
+		refLane := uint32(random>>32) % lanes
+		if pass == 0 && slice == 0 {
+			refLane = lane
+		}
+		refIndex := argon2ReferenceIndex(uint32(random), pass, slice, index, refLane == lane, laneLen, segmentLen)
+
+		argon2ProcessBlock(&blocks[offset], &blocks[prev], &blocks[refLane*laneLen+refIndex], pass > 0)
+	}
+}
+
+// argon2ReferenceIndex 计算引用块在lane中的位置
+func argon2ReferenceIndex(random, pass, slice, index uint32, sameLane bool, laneLen, segmentLen uint32) uint32 {
+	var area uint32
+	switch {
+	case pass == 0 && sameLane:
+		area = slice*segmentLen + index - 1
+	case pass == 0:
+		area = slice * segmentLen
+		if index == 0 {
+			area--
+		}
+	case sameLane:
+		area = laneLen - segmentLen + index - 1
+	default:
+		area = laneLen - segmentLen
+		if index == 0 {
+			area--
+		}
+	}
+
+	x := uint64(random) * uint64(random) >> 32
+	y := uint64(area) * x >> 32
+	relative := area - 1 - uint32(y)
+
+	start := uint32(0)
+	if pass > 0 && slice != 3 {
+		start = (slice + 1) * segmentLen
+	}
+	return (start + relative) % laneLen
+}
+
+// argon2ProcessBlock 压缩函数G：out = G(x, y)，xorOut为true时与out原值异或（版本0x13第二轮起）
+func argon2ProcessBlock(out, x, y *[argon2BlockWords]uint64, xorOut bool) {
+	var r, t [argon2BlockWords]uint64
+	for i := range r {
+		r[i] = x[i] ^ y[i]
+	}
+	t = r
+
+	// 按行
+	for i := 0; i < 128; i += 16 {
+		argon2Permute(&t[i], &t[i+1], &t[i+2], &t[i+3], &t[i+4], &t[i+5], &t[i+6], &t[i+7],
+			&t[i+8], &t[i+9], &t[i+10], &t[i+11], &t[i+12], &t[i+13], &t[i+14], &t[i+15])
+	}
+	// 按列
+	for i := 0; i < 16; i += 2 {
+		argon2Permute(&t[i], &t[i+1], &t[i+16], &t[i+17], &t[i+32], &t[i+33], &t[i+48], &t[i+49],
+			&t[i+64], &t[i+65], &t[i+80], &t[i+81], &t[i+96], &t[i+97], &t[i+112], &t[i+113])
+	}
+
+	for i := range out {
+		if xorOut {
+			out[i] ^= t[i] ^ r[i]
+		} else {
+			out[i] = t[i] ^ r[i]
+		}
+	}
+}
+
+// argon2Permute 置换P（BlaMka变体的BLAKE2b轮函数）
+func argon2Permute(v0, v1, v2, v3, v4, v5, v6, v7, v8, v9, v10, v11, v12, v13, v14, v15 *uint64) {
+	argon2GB(v0, v4, v8, v12)
+	argon2GB(v1, v5, v9, v13)
+	argon2GB(v2, v6, v10, v14)
+	argon2GB(v3, v7, v11, v15)
+	argon2GB(v0, v5, v10, v15)
+	argon2GB(v1, v6, v11, v12)
+	argon2GB(v2, v7, v8, v13)
+	argon2GB(v3, v4, v9, v14)
+}
+
+func argon2GB(a, b, c, d *uint64) {
+	*a += *b + 2*uint64(uint32(*a))*uint64(uint32(*b))
+	*d = bits.RotateLeft64(*d^*a, -32)
+	*c += *d + 2*uint64(uint32(*c))*uint64(uint32(*d))
+	*b = bits.RotateLeft64(*b^*c, -24)
+	*a += *b + 2*uint64(uint32(*a))*uint64(uint32(*b))
+	*d = bits.RotateLeft64(*d^*a, -16)
+	*c += *d + 2*uint64(uint32(*c))*uint64(uint32(*d))
+	*b = bits.RotateLeft64(*b^*c, -63)
+}
@@ -0,0 +1,419 @@
+package providers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/yourorg/unpass/internal/parser"
+	"github.com/yourorg/unpass/internal/types"
+)
+
+// KeePass标准字段名
+const (
+	keePassFieldTitle    = "Title"
+	keePassFieldUserName = "UserName"
+	keePassFieldPassword = "Password"
+	keePassFieldURL      = "URL"
+	keePassFieldNotes    = "Notes"
+)
+
+// KeePassXC扩展属性
+const (
+	keePassXCOTP                = "otp"
+	keePassXCPasskeyPrefix      = "KPEX_PASSKEY_"
+	keePassXCPasskeyRelyingPart = "KPEX_PASSKEY_RELYING_PARTY"
+	keePassXCPasskeyCredential  = "KPEX_PASSKEY_CREDENTIAL_ID"
+	keePassAdditionalURLPrefix  = "KP2A_URL"
+)
+
+// keePassTOTPFields 各客户端存放TOTP密钥的属性，按优先级排列
+var keePassTOTPFields = []string{
+	keePassXCOTP,            // KeePassXC
+	"TimeOtp-Secret-Base32", // KeePass 2.47+ 内置TOTP
+	"TimeOtp-Secret",
+	"TimeOtp-Secret-Hex",
+	"TOTP Seed", // KeePassXC旧版本
+}
+
+// keePassEntry 从XML中提取的条目
+type keePassEntry struct {
+	uuid      string
+	tags      string
+	strings   map[string]string
+	groupPath []string
+	recycled  bool
+}
+
+// keePassGroup XML遍历中的分组状态
+type keePassGroup struct {
+	name     string
+	recycled bool
+}
+
+// KeePassParser KeePass KDBX 4数据库解析器
+type KeePassParser struct {
+	passphrase  parser.PassphraseFunc
+	keyFilePath string
+}
+
+func NewKeePassParser() *KeePassParser {
+	return &KeePassParser{}
+}
+
+// SetPassphraseFunc 设置主密码来源，为nil时只使用密钥文件
+func (p *KeePassParser) SetPassphraseFunc(fn parser.PassphraseFunc) {
+	p.passphrase = fn
+}
+
+// SetKeyFile 设置密钥文件路径
+func (p *KeePassParser) SetKeyFile(path string) {
+	p.keyFilePath = path
+}
+
+func (p *KeePassParser) Name() string {
+	return "keepass"
+}
+
+func (p *KeePassParser) Parse(reader io.Reader) ([]types.Credential, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read KeePass database: %w", err)
+	}
+
+	compositeKey, err := p.compositeKey()
+	if err != nil {
+		return nil, err
+	}
+	defer clear(compositeKey)
+
+	xmlData, stream, err := decryptKDBX(data, compositeKey)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(xmlData)
+
+	entries, err := p.parseXML(xmlData, stream)
+	if err != nil {
+		return nil, err
+	}
+
+	var credentials []types.Credential
+
+	for _, entry := range entries {
+		// 跳过回收站中的数据
+		if entry.recycled {
+			continue
+		}
+
+		// 提取字段数据
+		credential := p.extractCredential(entry)
+
+		// 验证必要字段
+		if isValidCredential(credential) {
+			credentials = append(credentials, credential)
+		}
+	}
+
+	return credentials, nil
+}
+
+func (p *KeePassParser) SupportedFormats() []string {
+	return []string{"kdbx"}
+}
+
+// Detect 检查KDBX文件签名，版本不受支持时由Parse给出明确错误
+func (p *KeePassParser) Detect(reader io.ReadSeeker) (float64, error) {
+	signature := make([]byte, 8)
+	if _, err := io.ReadFull(reader, signature); err != nil || !isKDBX(signature) {
+		return parser.ConfidenceNone, errors.New("missing KDBX file signature")
+	}
+	return parser.ConfidenceCertain, nil
+}
+
+// compositeKey 由主密码和/或密钥文件计算复合密钥
+func (p *KeePassParser) compositeKey() ([]byte, error) {
+	var keyFileKey []byte
+	if p.keyFilePath != "" {
+		keyFileData, err := os.ReadFile(p.keyFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key file: %w", err)
+		}
+		keyFileKey, err = kdbxKeyFileKey(keyFileData)
+		clear(keyFileData)
+		if err != nil {
+			return nil, err
+		}
+		defer clear(keyFileKey)
+	}
+
+	var password []byte
+	if p.passphrase != nil {
+		var err error
+		password, err = p.passphrase("KeePass master password: ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read passphrase: %w", err)
+		}
+		defer clear(password)
+	}
+
+	if password == nil && keyFileKey == nil {
+		return nil, parser.ErrPassphraseRequired
+	}
+
+	return kdbxCompositeKey(password, keyFileKey), nil
+}
+
+// parseXML 按文档顺序遍历KeePass XML，受保护的值必须按出现顺序用内层流解密
+func (p *KeePassParser) parseXML(xmlData []byte, stream kdbxInnerStream) ([]keePassEntry, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(xmlData))
+
+	var (
+		entries      []keePassEntry
+		path         []string // 当前元素路径
+		groups       []keePassGroup
+		entryStack   []*keePassEntry // 历史版本条目嵌套在当前条目内
+		historyDepth int
+		text         strings.Builder
+		protected    bool
+		stringKey    string
+		stringValue  string
+		recycleBinID string
+		recycleBinOn = true
+	)
+
+	current := func() *keePassEntry {
+		if len(entryStack) == 0 {
+			return nil
+		}
+		return entryStack[len(entryStack)-1]
+	}
+	parent := func() string {
+		if len(path) < 2 {
+			return ""
+		}
+		return path[len(path)-2]
+	}
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse KeePass XML: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			path = append(path, t.Name.Local)
+			text.Reset()
+
+			switch t.Name.Local {
+			case "Group":
+				recycled := len(groups) > 0 && groups[len(groups)-1].recycled
+				groups = append(groups, keePassGroup{recycled: recycled})
+			case "History":
+				historyDepth++
+			case "Entry":
+				// 历史版本条目也要解析（以推进内层流），但不作为结果
+				entry := &keePassEntry{strings: make(map[string]string)}
+				entryStack = append(entryStack, entry)
+				if len(groups) > 0 {
+					entry.recycled = groups[len(groups)-1].recycled
+					for _, group := range groups[1:] { // 根分组为数据库名称，不作为标签
+						entry.groupPath = append(entry.groupPath, group.name)
+					}
+				}
+			case "String":
+				stringKey, stringValue = "", ""
+			case "Value":
+				protected = false
+				for _, attr := range t.Attr {
+					if attr.Name.Local == "Protected" && strings.EqualFold(attr.Value, "True") {
+						protected = true
+					}
+				}
+			}
+
+		case xml.CharData:
+			text.Write(t)
+
+		case xml.EndElement:
+			value := text.String()
+			text.Reset()
+
+			entry := current()
+			switch name := t.Name.Local; {
+			case name == "RecycleBinUUID" && parent() == "Meta":
+				recycleBinID = strings.TrimSpace(value)
+			case name == "RecycleBinEnabled" && parent() == "Meta":
+				recycleBinOn = strings.EqualFold(strings.TrimSpace(value), "True")
+			case name == "UUID" && parent() == "Group" && len(groups) > 0:
+				group := &groups[len(groups)-1]
+				if recycleBinOn && recycleBinID != "" && strings.TrimSpace(value) == recycleBinID {
+					group.recycled = true
+				}
+			case name == "Name" && parent() == "Group" && len(groups) > 0:
+				groups[len(groups)-1].name = value
+			case name == "UUID" && parent() == "Entry" && entry != nil:
+				entry.uuid = strings.TrimSpace(value)
+			case name == "Tags" && parent() == "Entry" && entry != nil:
+				entry.tags = value
+			case name == "Key" && parent() == "String":
+				stringKey = value
+			case name == "Value" && parent() == "String":
+				if protected {
+					plain, err := unprotectKeePassValue(value, stream)
+					if err != nil {
+						return nil, err
+					}
+					value = plain
+				}
+				stringValue = value
+			case name == "String" && parent() == "Entry" && entry != nil:
+				entry.strings[stringKey] = stringValue
+			case name == "Entry" && entry != nil:
+				if historyDepth == 0 {
+					entries = append(entries, *entry)
+				}
+				entryStack = entryStack[:len(entryStack)-1]
+			case name == "History":
+				historyDepth--
+			case name == "Group" && len(groups) > 0:
+				groups = groups[:len(groups)-1]
+			}
+
+			path = path[:len(path)-1]
+		}
+	}
+
+	return entries, nil
+}
+
+// unprotectKeePassValue 用内层随机流解密受保护的值
+func unprotectKeePassValue(value string, stream kdbxInnerStream) (string, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		return "", fmt.Errorf("invalid protected value in KeePass XML: %w", err)
+	}
+	plaintext := make([]byte, len(ciphertext))
+	stream.XORKeyStream(plaintext, ciphertext)
+	return string(plaintext), nil
+}
+
+// extractCredential 从KeePass条目中提取凭据信息
+func (p *KeePassParser) extractCredential(entry keePassEntry) types.Credential {
+	credential := types.Credential{
+		ID:       keePassEntryID(entry.uuid),
+		Title:    entry.strings[keePassFieldTitle],
+		Username: entry.strings[keePassFieldUserName],
+		Password: entry.strings[keePassFieldPassword],
+	}
+
+	// 主URL和KeePassXC附加URL
+	var urls []string
+	if value := strings.TrimSpace(entry.strings[keePassFieldURL]); value != "" {
+		urls = append(urls, value)
+	}
+
+	// 自定义属性按名称排序，保证输出稳定
+	keys := make([]string, 0, len(entry.strings))
+	for key := range entry.strings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var notes []string
+	if value := strings.TrimSpace(entry.strings[keePassFieldNotes]); value != "" {
+		notes = append(notes, value)
+	}
+
+	for _, key := range keys {
+		value := strings.TrimSpace(entry.strings[key])
+		if value == "" {
+			continue
+		}
+		switch {
+		case key == keePassFieldTitle, key == keePassFieldUserName, key == keePassFieldPassword,
+			key == keePassFieldURL, key == keePassFieldNotes:
+			// 标准字段已处理
+		case strings.HasPrefix(key, keePassAdditionalURLPrefix):
+			urls = append(urls, value)
+		case strings.HasPrefix(key, keePassXCPasskeyPrefix):
+			// Passkey属性（含私钥）单独处理，不进入备注
+		case isKeePassTOTPField(key):
+			// TOTP属性单独处理
+		default:
+			notes = append(notes, fmt.Sprintf("%s: %s", key, value))
+		}
+	}
+
+	if len(urls) > 0 {
+		credential.URLs = urls
+		credential.URL = urls[0] // 第一个URL作为主URL，保持向后兼容
+	}
+
+	for _, field := range keePassTOTPFields {
+		if value := strings.TrimSpace(entry.strings[field]); value != "" {
+			credential.TOTP = value
+			break
+		}
+	}
+
+	if rp := strings.TrimSpace(entry.strings[keePassXCPasskeyRelyingPart]); rp != "" {
+		credential.Passkey = rp
+	} else if id := strings.TrimSpace(entry.strings[keePassXCPasskeyCredential]); id != "" {
+		credential.Passkey = id
+	}
+
+	// 分组路径和条目标签作为标签
+	var tags []string
+	if len(entry.groupPath) > 0 {
+		tags = append(tags, strings.Join(entry.groupPath, "/"))
+	}
+	for _, tag := range strings.FieldsFunc(entry.tags, func(r rune) bool { return r == ';' || r == ',' }) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) > 0 {
+		credential.Tags = tags
+	}
+
+	// 合并备注信息
+	if len(notes) > 0 {
+		credential.Notes = strings.Join(notes, "; ")
+	}
+
+	return credential
+}
+
+// isKeePassTOTPField 判断属性是否为TOTP相关字段
+func isKeePassTOTPField(key string) bool {
+	if key == "TOTP Settings" || strings.HasPrefix(key, "TimeOtp-") {
+		return true
+	}
+	for _, field := range keePassTOTPFields {
+		if key == field {
+			return true
+		}
+	}
+	return false
+}
+
+// keePassEntryID 将base64编码的条目UUID转换为十六进制，与KeePassXC界面显示一致
+func keePassEntryID(uuid string) string {
+	decoded, err := base64.StdEncoding.DecodeString(uuid)
+	if err != nil || len(decoded) != 16 {
+		return uuid
+	}
+	return hex.EncodeToString(decoded)
+}
@@ -0,0 +1,370 @@
+package providers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/yourorg/unpass/internal/parser"
+	"github.com/yourorg/unpass/internal/types"
+)
+
+// onePasswordExportData 1PUX压缩包中的主数据文件
+const onePasswordExportData = "export.data"
+
+// OnePasswordCategory 1Password项目类别枚举
+type OnePasswordCategory string
+
+const (
+	OnePasswordCategoryLogin    OnePasswordCategory = "001"
+	OnePasswordCategoryPassword OnePasswordCategory = "005"
+)
+
+// OnePasswordData 1PUX导出的export.data数据结构
+type OnePasswordData struct {
+	Accounts []OnePasswordAccount `json:"accounts"`
+}
+
+type OnePasswordAccount struct {
+	Attrs struct {
+		AccountName string `json:"accountName"`
+		Name        string `json:"name"`
+		Email       string `json:"email"`
+		UUID        string `json:"uuid"`
+	} `json:"attrs"`
+	Vaults []OnePasswordVault `json:"vaults"`
+}
+
+type OnePasswordVault struct {
+	Attrs struct {
+		UUID string `json:"uuid"`
+		Name string `json:"name"`
+		Type string `json:"type"`
+	} `json:"attrs"`
+	Items []OnePasswordItem `json:"items"`
+}
+
+type OnePasswordItem struct {
+	UUID         string              `json:"uuid"`
+	State        string              `json:"state"`
+	CategoryUUID OnePasswordCategory `json:"categoryUuid"`
+	Details      OnePasswordDetails  `json:"details"`
+	Overview     OnePasswordOverview `json:"overview"`
+}
+
+type OnePasswordDetails struct {
+	LoginFields []OnePasswordLoginField `json:"loginFields"`
+	NotesPlain  string                  `json:"notesPlain"`
+	Sections    []OnePasswordSection    `json:"sections"`
+	Passkey     *OnePasswordPasskey     `json:"passkey"`
+}
+
+type OnePasswordLoginField struct {
+	Value       string `json:"value"`
+	Name        string `json:"name"`
+	FieldType   string `json:"fieldType"`
+	Designation string `json:"designation"`
+}
+
+type OnePasswordSection struct {
+	Title  string                    `json:"title"`
+	Name   string                    `json:"name"`
+	Fields []OnePasswordSectionField `json:"fields"`
+}
+
+type OnePasswordSectionField struct {
+	Title string                     `json:"title"`
+	ID    string                     `json:"id"`
+	Value map[string]json.RawMessage `json:"value"`
+}
+
+type OnePasswordPasskey struct {
+	CredentialID string `json:"credentialId"`
+	RpID         string `json:"rpId"`
+	UserHandle   string `json:"userHandle"`
+}
+
+type OnePasswordOverview struct {
+	Title string           `json:"title"`
+	URL   string           `json:"url"`
+	URLs  []OnePasswordURL `json:"urls"`
+	Tags  []string         `json:"tags"`
+}
+
+type OnePasswordURL struct {
+	Label string `json:"label"`
+	URL   string `json:"url"`
+}
+
+// OnePasswordParser 1Password 1PUX数据解析器
+type OnePasswordParser struct{}
+
+func NewOnePasswordParser() *OnePasswordParser {
+	return &OnePasswordParser{}
+}
+
+func (p *OnePasswordParser) Name() string {
+	return "1password"
+}
+
+func (p *OnePasswordParser) Parse(reader io.Reader) ([]types.Credential, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read 1PUX export: %w", err)
+	}
+
+	// .1pux为zip压缩包，也接受已解压的export.data
+	exportData := data
+	if bytes.HasPrefix(data, []byte("PK")) {
+		exportData, err = p.readExportData(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var onePasswordData OnePasswordData
+	if err := json.Unmarshal(exportData, &onePasswordData); err != nil {
+		return nil, fmt.Errorf("failed to decode 1Password export.data: %w", err)
+	}
+
+	var credentials []types.Credential
+
+	for _, account := range onePasswordData.Accounts {
+		for _, vault := range account.Vaults {
+			for _, item := range vault.Items {
+				// 跳过不符合条件的数据
+				if !p.shouldProcessItem(item) {
+					continue
+				}
+
+				// 提取字段数据
+				credential := p.extractCredential(item, vault.Attrs.Name)
+
+				// 验证必要字段
+				if isValidCredential(credential) {
+					credentials = append(credentials, credential)
+				}
+			}
+		}
+	}
+
+	return credentials, nil
+}
+
+func (p *OnePasswordParser) SupportedFormats() []string {
+	return []string{"1pux"}
+}
+
+// Detect .1pux压缩包需包含export.data，未压缩时需有accounts数组
+func (p *OnePasswordParser) Detect(reader io.ReadSeeker) (float64, error) {
+	magic := make([]byte, 2)
+	n, _ := io.ReadFull(reader, magic)
+	if _, err := reader.Seek(0, io.SeekStart); err != nil {
+		return parser.ConfidenceNone, err
+	}
+
+	if bytes.Equal(magic[:n], []byte("PK")) {
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return parser.ConfidenceNone, err
+		}
+		archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return parser.ConfidenceNone, fmt.Errorf("invalid zip archive: %w", err)
+		}
+		for _, file := range archive.File {
+			if file.Name == onePasswordExportData {
+				return parser.ConfidenceCertain, nil
+			}
+		}
+		return parser.ConfidenceNone, fmt.Errorf("zip archive does not contain %s", onePasswordExportData)
+	}
+
+	decoder := json.NewDecoder(reader)
+	confidence := parser.ConfidenceNone
+	reason := errors.New("missing 1Password accounts array")
+	err := parser.WalkJSONObject(decoder, func(key string) (bool, error) {
+		if key != "accounts" {
+			return true, parser.SkipJSONValue(decoder)
+		}
+
+		// CXF导出同样有accounts数组，以账户下的attrs和vaults区分
+		accountKey, empty, err := parser.FindJSONArrayObjectKey(decoder, "attrs", "vaults", "items", "collections")
+		if err != nil {
+			return false, err
+		}
+		switch {
+		case accountKey == "attrs" || accountKey == "vaults":
+			confidence, reason = parser.ConfidenceHigh, nil
+		case empty:
+			confidence, reason = parser.ConfidenceLow, nil
+		default:
+			reason = errors.New("accounts do not contain 1Password vaults")
+		}
+		return false, nil
+	})
+	if err != nil {
+		return parser.ConfidenceNone, err
+	}
+	return confidence, reason
+}
+
+// readExportData 从1PUX压缩包中读取export.data
+func (p *OnePasswordParser) readExportData(data []byte) ([]byte, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open 1PUX archive: %w", err)
+	}
+
+	for _, file := range archive.File {
+		if file.Name != onePasswordExportData {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", onePasswordExportData, err)
+		}
+		defer rc.Close()
+		return io.ReadAll(rc)
+	}
+
+	return nil, fmt.Errorf("1PUX archive does not contain %s", onePasswordExportData)
+}
+
+// shouldProcessItem 判断是否应该处理该项目
+func (p *OnePasswordParser) shouldProcessItem(item OnePasswordItem) bool {
+	// 跳过已归档或已删除的数据
+	if item.State != "" && item.State != "active" {
+		return false
+	}
+
+	// 只处理login类型的数据
+	if item.CategoryUUID != OnePasswordCategoryLogin {
+		return false
+	}
+
+	return true
+}
+
+// extractCredential 从1Password项目中提取凭据信息
+func (p *OnePasswordParser) extractCredential(item OnePasswordItem, vaultName string) types.Credential {
+	credential := types.Credential{
+		ID:    item.UUID,
+		Title: item.Overview.Title,
+	}
+
+	// 登录字段：按designation映射用户名和密码
+	var email string
+	for _, field := range item.Details.LoginFields {
+		if strings.TrimSpace(field.Value) == "" {
+			continue
+		}
+		switch {
+		case field.Designation == "username":
+			credential.Username = field.Value
+		case field.Designation == "password":
+			credential.Password = field.Value
+		case field.FieldType == "E" && email == "":
+			email = field.Value
+		}
+	}
+	// 如果没有username，使用email作为username
+	if credential.Username == "" {
+		credential.Username = email
+	}
+
+	// 收集所有URL
+	var urls []string
+	for _, u := range item.Overview.URLs {
+		if value := strings.TrimSpace(u.URL); value != "" {
+			urls = append(urls, value)
+		}
+	}
+	if len(urls) == 0 && strings.TrimSpace(item.Overview.URL) != "" {
+		urls = append(urls, strings.TrimSpace(item.Overview.URL))
+	}
+	if len(urls) > 0 {
+		credential.URLs = urls
+		credential.URL = urls[0] // 第一个URL作为主URL，保持向后兼容
+	}
+
+	// Passkey信息
+	if passkey := item.Details.Passkey; passkey != nil {
+		credential.Passkey = onePasswordPasskeyID(passkey)
+	}
+
+	// 保管库名称和项目标签作为标签
+	var tags []string
+	if vaultName != "" {
+		tags = append(tags, vaultName)
+	}
+	tags = append(tags, item.Overview.Tags...)
+	if len(tags) > 0 {
+		credential.Tags = tags
+	}
+
+	// 用于收集备注信息的字段
+	var notes []string
+	if value := strings.TrimSpace(item.Details.NotesPlain); value != "" {
+		notes = append(notes, value)
+	}
+
+	for _, section := range item.Details.Sections {
+		for _, field := range section.Fields {
+			p.processSectionField(&credential, &notes, field)
+		}
+	}
+
+	// 合并备注信息
+	if len(notes) > 0 {
+		credential.Notes = strings.Join(notes, "; ")
+	}
+
+	return credential
+}
+
+// processSectionField 处理自定义分区中的单个字段
+// 字段值是只有一个键的对象，键名表示字段类型（string、concealed、totp、passkey等）
+func (p *OnePasswordParser) processSectionField(credential *types.Credential, notes *[]string, field OnePasswordSectionField) {
+	for kind, raw := range field.Value {
+		switch kind {
+		case "totp":
+			var value string
+			if json.Unmarshal(raw, &value) == nil && strings.TrimSpace(value) != "" && credential.TOTP == "" {
+				credential.TOTP = strings.TrimSpace(value)
+			}
+
+		case "passkey":
+			var passkey OnePasswordPasskey
+			if json.Unmarshal(raw, &passkey) == nil && credential.Passkey == "" {
+				credential.Passkey = onePasswordPasskeyID(&passkey)
+			}
+
+		default:
+			// 其他可读为字符串的字段作为备注
+			var value string
+			if json.Unmarshal(raw, &value) != nil || strings.TrimSpace(value) == "" {
+				continue
+			}
+			label := field.Title
+			if label == "" {
+				label = field.ID
+			}
+			if label != "" {
+				*notes = append(*notes, fmt.Sprintf("%s: %s", label, value))
+			}
+		}
+	}
+}
+
+// onePasswordPasskeyID 返回Passkey的依赖方ID，缺失时回退到凭据ID
+func onePasswordPasskeyID(passkey *OnePasswordPasskey) string {
+	if passkey.RpID != "" {
+		return passkey.RpID
+	}
+	return passkey.CredentialID
+}
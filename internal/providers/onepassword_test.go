@@ -0,0 +1,206 @@
+package providers
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const testOnePasswordExportData = `{
+	"accounts": [
+		{
+			"attrs": { "accountName": "Work", "name": "Alice", "email": "alice@example.com", "uuid": "acc-1" },
+			"vaults": [
+				{
+					"attrs": { "uuid": "vault-1", "name": "Engineering", "type": "E" },
+					"items": [
+						{
+							"uuid": "op-uuid-1",
+							"state": "active",
+							"categoryUuid": "001",
+							"details": {
+								"loginFields": [
+									{ "value": "alice", "name": "username", "fieldType": "T", "designation": "username" },
+									{ "value": "hunter2", "name": "password", "fieldType": "P", "designation": "password" }
+								],
+								"notesPlain": "Main account",
+								"sections": [
+									{
+										"title": "",
+										"name": "add more",
+										"fields": [
+											{ "title": "one-time password", "id": "TOTP_1", "value": { "totp": "otpauth://totp/GitHub:alice?secret=JBSWY3DPEHPK3PXP" } },
+											{ "title": "Recovery PIN", "id": "pin", "value": { "concealed": "9876" } },
+											{ "title": "Created", "id": "date", "value": { "date": 1614298956 } }
+										]
+									}
+								],
+								"passkey": { "credentialId": "cred-1", "rpId": "github.com", "userHandle": "handle" }
+							},
+							"overview": {
+								"title": "GitHub",
+								"url": "https://github.com",
+								"urls": [
+									{ "label": "website", "url": "https://github.com" },
+									{ "label": "", "url": "https://gist.github.com" }
+								],
+								"tags": ["dev"]
+							}
+						},
+						{
+							"uuid": "op-uuid-2",
+							"state": "archived",
+							"categoryUuid": "001",
+							"details": { "loginFields": [ { "value": "old", "designation": "username" } ] },
+							"overview": { "title": "Archived" }
+						},
+						{
+							"uuid": "op-uuid-3",
+							"state": "active",
+							"categoryUuid": "003",
+							"details": { "notesPlain": "secure note" },
+							"overview": { "title": "Note" }
+						},
+						{
+							"uuid": "op-uuid-4",
+							"state": "active",
+							"categoryUuid": "001",
+							"details": {
+								"loginFields": [
+									{ "value": "bob@example.com", "name": "email", "fieldType": "E" },
+									{ "value": "pw", "name": "password", "fieldType": "P", "designation": "password" }
+								],
+								"sections": [
+									{ "fields": [ { "title": "passkey", "id": "pk", "value": { "passkey": { "credentialId": "cred-2", "rpId": "example.com" } } } ] }
+								]
+							},
+							"overview": { "title": "Example", "url": "https://example.com" }
+						}
+					]
+				}
+			]
+		}
+	]
+}`
+
+func buildOnePUX(t *testing.T, exportData string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, content := range map[string]string{
+		"export.attributes": `{"version": 3, "description": "1Password Unencrypted Export"}`,
+		"export.data":       exportData,
+	} {
+		w, err := archive.Create(name)
+		if err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestOnePasswordParser_Parse(t *testing.T) {
+	parser := NewOnePasswordParser()
+
+	credentials, err := parser.Parse(bytes.NewReader(buildOnePUX(t, testOnePasswordExportData)))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	// 应该只有2个有效凭据（跳过archived和非login的）
+	if len(credentials) != 2 {
+		t.Fatalf("Expected 2 credentials, got %d", len(credentials))
+	}
+
+	cred1 := credentials[0]
+	if cred1.ID != "op-uuid-1" || cred1.Title != "GitHub" {
+		t.Errorf("Unexpected ID/title: '%s'/'%s'", cred1.ID, cred1.Title)
+	}
+	if cred1.Username != "alice" || cred1.Password != "hunter2" {
+		t.Errorf("Unexpected username/password: '%s'/'%s'", cred1.Username, cred1.Password)
+	}
+	if len(cred1.URLs) != 2 || cred1.URL != "https://github.com" {
+		t.Errorf("Unexpected URLs: '%s' / %v", cred1.URL, cred1.URLs)
+	}
+	if !strings.HasPrefix(cred1.TOTP, "otpauth://") {
+		t.Errorf("Expected TOTP URI, got '%s'", cred1.TOTP)
+	}
+	if cred1.Passkey != "github.com" {
+		t.Errorf("Expected Passkey 'github.com', got '%s'", cred1.Passkey)
+	}
+	if len(cred1.Tags) != 2 || cred1.Tags[0] != "Engineering" || cred1.Tags[1] != "dev" {
+		t.Errorf("Expected tags [Engineering dev], got %v", cred1.Tags)
+	}
+	if !strings.Contains(cred1.Notes, "Main account") || !strings.Contains(cred1.Notes, "Recovery PIN: 9876") {
+		t.Errorf("Unexpected notes: '%s'", cred1.Notes)
+	}
+
+	cred2 := credentials[1]
+	if cred2.Username != "bob@example.com" {
+		t.Errorf("Expected email as username, got '%s'", cred2.Username)
+	}
+	if cred2.URL != "https://example.com" {
+		t.Errorf("Expected fallback overview URL, got '%s'", cred2.URL)
+	}
+	if cred2.Passkey != "example.com" {
+		t.Errorf("Expected Passkey from section field, got '%s'", cred2.Passkey)
+	}
+}
+
+func TestOnePasswordParser_PasskeyOnly(t *testing.T) {
+	parser := NewOnePasswordParser()
+
+	exportData := `{"accounts": [{"vaults": [{"attrs": {"name": "Private"}, "items": [
+		{
+			"uuid": "op-passkey",
+			"state": "active",
+			"categoryUuid": "001",
+			"details": { "passkey": { "credentialId": "cred-3", "rpId": "passkeys.example.com" } },
+			"overview": { "title": "Passkey Demo", "url": "https://passkeys.example.com" }
+		}
+	]}]}]}`
+
+	credentials, err := parser.Parse(bytes.NewReader(buildOnePUX(t, exportData)))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(credentials) != 1 {
+		t.Fatalf("Expected the passkey-only login to be kept, got %d credentials", len(credentials))
+	}
+	if credentials[0].Passkey != "passkeys.example.com" {
+		t.Errorf("Expected Passkey 'passkeys.example.com', got '%s'", credentials[0].Passkey)
+	}
+}
+
+func TestOnePasswordParser_PlainExportData(t *testing.T) {
+	parser := NewOnePasswordParser()
+
+	credentials, err := parser.Parse(strings.NewReader(testOnePasswordExportData))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(credentials) != 2 {
+		t.Errorf("Expected 2 credentials, got %d", len(credentials))
+	}
+}
+
+func TestOnePasswordParser_MissingExportData(t *testing.T) {
+	parser := NewOnePasswordParser()
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	archive.Create("export.attributes")
+	archive.Close()
+
+	if _, err := parser.Parse(bytes.NewReader(buf.Bytes())); err == nil {
+		t.Error("Expected error for archive without export.data")
+	}
+}
@@ -0,0 +1,399 @@
+package providers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/yourorg/unpass/internal/parser"
+	"github.com/yourorg/unpass/internal/types"
+)
+
+// Proton Pass导出压缩包中的数据文件，加密导出为data.pgp
+const (
+	protonPassDataJSON = "data.json"
+	protonPassDataPGP  = "data.pgp"
+)
+
+// ProtonPassItemState Proton Pass项目状态枚举
+type ProtonPassItemState int
+
+const (
+	ProtonPassItemActive  ProtonPassItemState = 1
+	ProtonPassItemTrashed ProtonPassItemState = 2
+)
+
+// ProtonPassData Proton Pass导出的data.json数据结构，vaults以共享ID为键
+type ProtonPassData struct {
+	Version   string                     `json:"version"`
+	UserID    string                     `json:"userId"`
+	Encrypted bool                       `json:"encrypted"`
+	Vaults    map[string]ProtonPassVault `json:"vaults"`
+}
+
+type ProtonPassVault struct {
+	Name  string           `json:"name"`
+	Items []ProtonPassItem `json:"items"`
+}
+
+type ProtonPassItem struct {
+	ItemID string              `json:"itemId"`
+	State  ProtonPassItemState `json:"state"`
+	Data   ProtonPassItemData  `json:"data"`
+}
+
+type ProtonPassItemData struct {
+	Type     string `json:"type"`
+	Metadata struct {
+		Name string `json:"name"`
+		Note string `json:"note"`
+	} `json:"metadata"`
+	Content     ProtonPassLogin        `json:"content"`
+	ExtraFields []ProtonPassExtraField `json:"extraFields"`
+}
+
+type ProtonPassLogin struct {
+	ItemEmail    string              `json:"itemEmail"`
+	ItemUsername string              `json:"itemUsername"`
+	Username     string              `json:"username"` // 旧版本导出
+	Password     string              `json:"password"`
+	URLs         []string            `json:"urls"`
+	TOTPURI      string              `json:"totpUri"`
+	Passkeys     []ProtonPassPasskey `json:"passkeys"`
+}
+
+type ProtonPassPasskey struct {
+	KeyID        string `json:"keyId"`
+	RpID         string `json:"rpId"`
+	RpName       string `json:"rpName"`
+	Domain       string `json:"domain"`
+	UserName     string `json:"userName"`
+	CredentialID string `json:"credentialId"`
+	CreateTime   int64  `json:"createTime"`
+}
+
+type ProtonPassExtraField struct {
+	FieldName string `json:"fieldName"`
+	Type      string `json:"type"` // text、hidden、totp、timestamp
+	Data      struct {
+		Content string `json:"content"`
+		TOTPURI string `json:"totpUri"`
+	} `json:"data"`
+}
+
+// ProtonPassParser Proton Pass导出解析器，支持zip、JSON以及PGP对称加密的导出
+type ProtonPassParser struct {
+	passphrase parser.PassphraseFunc
+}
+
+func NewProtonPassParser() *ProtonPassParser {
+	return &ProtonPassParser{}
+}
+
+// SetPassphraseFunc 设置加密导出文件的口令来源
+func (p *ProtonPassParser) SetPassphraseFunc(fn parser.PassphraseFunc) {
+	p.passphrase = fn
+}
+
+func (p *ProtonPassParser) Name() string {
+	return "protonpass"
+}
+
+func (p *ProtonPassParser) Parse(reader io.Reader) ([]types.Credential, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Proton Pass export: %w", err)
+	}
+
+	exportData, err := p.unwrap(data)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(exportData)
+
+	var protonPassData ProtonPassData
+	if err := json.Unmarshal(exportData, &protonPassData); err != nil {
+		return nil, fmt.Errorf("failed to decode Proton Pass JSON: %w", err)
+	}
+	if protonPassData.Encrypted && protonPassData.Vaults == nil {
+		return nil, fmt.Errorf("encrypted Proton Pass export is missing data.pgp")
+	}
+
+	var credentials []types.Credential
+
+	// 保管库以共享ID为键，按键排序保证输出顺序稳定
+	for _, shareID := range slices.Sorted(maps.Keys(protonPassData.Vaults)) {
+		vault := protonPassData.Vaults[shareID]
+		for _, item := range vault.Items {
+			// 跳过不符合条件的数据
+			if !p.shouldProcessItem(item) {
+				continue
+			}
+
+			// 提取字段数据
+			credential := p.extractCredential(item, vault.Name)
+
+			// 验证必要字段
+			if isValidCredential(credential) {
+				credentials = append(credentials, credential)
+			}
+		}
+	}
+
+	return credentials, nil
+}
+
+// unwrap 解开压缩包和PGP加密，返回data.json的内容
+// 加密导出可能是压缩包中的data.pgp，也可能是整个压缩包被加密为.pgp文件
+func (p *ProtonPassParser) unwrap(data []byte) ([]byte, error) {
+	for range 3 {
+		switch {
+		case bytes.HasPrefix(data, []byte("PK")):
+			name, content, err := p.readArchive(data)
+			if err != nil {
+				return nil, err
+			}
+			data = content
+			if name == protonPassDataJSON {
+				return data, nil
+			}
+
+		case isPGPMessage(data):
+			decrypted, err := p.decrypt(data)
+			if err != nil {
+				return nil, err
+			}
+			data = decrypted
+
+		default:
+			return data, nil
+		}
+	}
+	return nil, fmt.Errorf("Proton Pass export is nested too deeply")
+}
+
+// readArchive 从导出压缩包中读取data.json或data.pgp
+func (p *ProtonPassParser) readArchive(data []byte) (string, []byte, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to open Proton Pass archive: %w", err)
+	}
+
+	for _, file := range archive.File {
+		name := path.Base(file.Name)
+		if name != protonPassDataJSON && name != protonPassDataPGP {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to open %s: %w", file.Name, err)
+		}
+		defer rc.Close()
+		content, err := io.ReadAll(rc)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+		}
+		return name, content, nil
+	}
+
+	return "", nil, fmt.Errorf("Proton Pass archive does not contain %s or %s", protonPassDataJSON, protonPassDataPGP)
+}
+
+// decrypt 解密PGP加密的导出，明文只存在于内存中
+func (p *ProtonPassParser) decrypt(data []byte) ([]byte, error) {
+	if p.passphrase == nil {
+		return nil, parser.ErrPassphraseRequired
+	}
+
+	passphrase, err := p.passphrase("Proton Pass export passphrase: ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	defer clear(passphrase)
+
+	return decryptProtonPassPGP(data, passphrase)
+}
+
+func (p *ProtonPassParser) SupportedFormats() []string {
+	return []string{"protonpass"}
+}
+
+// Detect 压缩包需包含Proton Pass目录下的数据文件，JSON需有以共享ID为键的vaults对象
+// PGP对称加密的消息无法在解密前确认来源，只给出中等置信度
+func (p *ProtonPassParser) Detect(reader io.ReadSeeker) (float64, error) {
+	header := make([]byte, len(pgpArmorHeader))
+	n, _ := io.ReadFull(reader, header)
+	if _, err := reader.Seek(0, io.SeekStart); err != nil {
+		return parser.ConfidenceNone, err
+	}
+	header = header[:n]
+
+	if bytes.HasPrefix(header, []byte("PK")) {
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return parser.ConfidenceNone, err
+		}
+		archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return parser.ConfidenceNone, fmt.Errorf("invalid zip archive: %w", err)
+		}
+		for _, file := range archive.File {
+			name := path.Base(file.Name)
+			if name == protonPassDataJSON || name == protonPassDataPGP {
+				return parser.ConfidenceCertain, nil
+			}
+		}
+		return parser.ConfidenceNone, fmt.Errorf("zip archive does not contain %s or %s", protonPassDataJSON, protonPassDataPGP)
+	}
+
+	if isPGPMessage(header) {
+		return parser.ConfidenceMedium, nil
+	}
+
+	// Bitwarden导出同样带有encrypted标记，以vaults对象区分，需高于Bitwarden的置信度
+	decoder := json.NewDecoder(reader)
+	hasUserID := false
+	confidence := parser.ConfidenceNone
+	reason := errors.New("missing Proton Pass vaults object")
+
+	err := parser.WalkJSONObject(decoder, func(key string) (bool, error) {
+		switch key {
+		case "userId":
+			hasUserID = true
+		case "vaults":
+			if err := parser.ExpectJSONDelim(decoder, '{'); err != nil {
+				return false, nil
+			}
+			confidence, reason = parser.ConfidenceHigh, nil
+			if hasUserID {
+				confidence = parser.ConfidenceCertain
+			}
+			return false, nil
+		}
+		return true, parser.SkipJSONValue(decoder)
+	})
+	if err != nil {
+		return parser.ConfidenceNone, err
+	}
+	return confidence, reason
+}
+
+// shouldProcessItem 判断是否应该处理该项目
+func (p *ProtonPassParser) shouldProcessItem(item ProtonPassItem) bool {
+	// 跳过回收站中的数据
+	if item.State == ProtonPassItemTrashed {
+		return false
+	}
+
+	// 只处理login类型的数据
+	if item.Data.Type != "login" {
+		return false
+	}
+
+	return true
+}
+
+// extractCredential 从Proton Pass项目中提取凭据信息
+func (p *ProtonPassParser) extractCredential(item ProtonPassItem, vaultName string) types.Credential {
+	login := item.Data.Content
+	credential := types.Credential{
+		ID:       item.ItemID,
+		Title:    item.Data.Metadata.Name,
+		Password: login.Password,
+		TOTP:     strings.TrimSpace(login.TOTPURI),
+	}
+
+	// 用户名优先，其次是邮箱，兼容旧版本的username字段
+	for _, username := range []string{login.ItemUsername, login.ItemEmail, login.Username} {
+		if strings.TrimSpace(username) != "" {
+			credential.Username = username
+			break
+		}
+	}
+
+	// 收集所有URL
+	var urls []string
+	for _, u := range login.URLs {
+		if value := strings.TrimSpace(u); value != "" {
+			urls = append(urls, value)
+		}
+	}
+	if len(urls) > 0 {
+		credential.URLs = urls
+		credential.URL = urls[0]
+	}
+
+	// Passkey信息：记录依赖方ID，缺失时回退到域名和凭据ID
+	var passkeys []string
+	for _, passkey := range login.Passkeys {
+		switch {
+		case passkey.RpID != "":
+			passkeys = append(passkeys, passkey.RpID)
+		case passkey.Domain != "":
+			passkeys = append(passkeys, passkey.Domain)
+		case passkey.CredentialID != "":
+			passkeys = append(passkeys, passkey.CredentialID)
+		}
+	}
+	if len(passkeys) > 0 {
+		credential.Passkey = strings.Join(passkeys, ", ")
+	}
+
+	// 没有用户名时使用Passkey中记录的用户名
+	if credential.Username == "" {
+		for _, passkey := range login.Passkeys {
+			if strings.TrimSpace(passkey.UserName) != "" {
+				credential.Username = passkey.UserName
+				break
+			}
+		}
+	}
+
+	// 保管库名称作为标签
+	if vaultName != "" {
+		credential.Tags = []string{vaultName}
+	}
+
+	// 用于收集备注信息的字段
+	var notes []string
+	if value := strings.TrimSpace(item.Data.Metadata.Note); value != "" {
+		notes = append(notes, value)
+	}
+
+	for _, field := range item.Data.ExtraFields {
+		p.processExtraField(&credential, &notes, field)
+	}
+
+	// 合并备注信息
+	if len(notes) > 0 {
+		credential.Notes = strings.Join(notes, "; ")
+	}
+
+	return credential
+}
+
+// processExtraField 处理自定义字段，TOTP字段在没有主TOTP时使用
+func (p *ProtonPassParser) processExtraField(credential *types.Credential, notes *[]string, field ProtonPassExtraField) {
+	if field.Type == "totp" {
+		value := strings.TrimSpace(field.Data.TOTPURI)
+		if value == "" {
+			value = strings.TrimSpace(field.Data.Content)
+		}
+		if value != "" && credential.TOTP == "" {
+			credential.TOTP = value
+		}
+		return
+	}
+
+	// 其他字段作为备注
+	if value := strings.TrimSpace(field.Data.Content); value != "" && field.FieldName != "" {
+		*notes = append(*notes, fmt.Sprintf("%s: %s", field.FieldName, value))
+	}
+}
@@ -0,0 +1,76 @@
+package providers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	pgperrors "github.com/ProtonMail/go-crypto/openpgp/errors"
+)
+
+// pgpArmorHeader ASCII armor格式的PGP消息头
+const pgpArmorHeader = "-----BEGIN PGP MESSAGE-----"
+
+// ErrProtonPassWrongPassword 口令错误，无法解开对称加密的会话密钥
+var ErrProtonPassWrongPassword = errors.New("invalid passphrase for encrypted Proton Pass export")
+
+// isPGPMessage 判断数据是否为PGP消息：ASCII armor，或以对称密钥加密的会话密钥包开头
+func isPGPMessage(data []byte) bool {
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	if bytes.HasPrefix(trimmed, []byte(pgpArmorHeader)) {
+		return true
+	}
+	// 新格式包头0xC3或旧格式包头0x8C（tag 3，Symmetric-Key Encrypted Session Key），
+	// 后跟一字节长度和包版本号（4、5或6）
+	if len(data) < 3 || (data[0] != 0xc3 && data[0] != 0x8c) {
+		return false
+	}
+	return data[2] >= 4 && data[2] <= 6
+}
+
+// decryptProtonPassPGP 用口令解密PGP对称加密的消息，明文只存在于内存中
+func decryptProtonPassPGP(data, passphrase []byte) ([]byte, error) {
+	var reader io.Reader = bytes.NewReader(data)
+	if bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte(pgpArmorHeader)) {
+		block, err := armor.Decode(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode PGP armor: %w", err)
+		}
+		reader = block.Body
+	}
+
+	// 口令错误时openpgp会反复调用提示函数，第二次调用即说明口令错误
+	attempted := false
+	prompt := func(keys []openpgp.Key, symmetric bool) ([]byte, error) {
+		if !symmetric {
+			return nil, fmt.Errorf("PGP message is not encrypted with a passphrase")
+		}
+		if attempted {
+			return nil, ErrProtonPassWrongPassword
+		}
+		attempted = true
+		return passphrase, nil
+	}
+
+	// 旧版本的会话密钥包没有校验值，口令错误时通常表现为解密后的数据无法解析
+	message, err := openpgp.ReadMessage(reader, nil, prompt, nil)
+	if err != nil {
+		var sessionKeyErr pgperrors.DecryptWithSessionKeyError
+		if errors.Is(err, ErrProtonPassWrongPassword) || errors.Is(err, pgperrors.ErrKeyIncorrect) || errors.As(err, &sessionKeyErr) {
+			return nil, ErrProtonPassWrongPassword
+		}
+		return nil, fmt.Errorf("failed to read PGP message: %w", err)
+	}
+
+	// 读取到末尾时才校验完整性（MDC或AEAD），失败时作为读取错误返回
+	plaintext, err := io.ReadAll(message.UnverifiedBody)
+	if err != nil {
+		clear(plaintext)
+		return nil, fmt.Errorf("failed to decrypt PGP message: %w", err)
+	}
+
+	return plaintext, nil
+}
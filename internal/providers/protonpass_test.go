@@ -0,0 +1,269 @@
+package providers
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+
+	"github.com/yourorg/unpass/internal/parser"
+)
+
+const testProtonPassExport = `{
+	"version": "1.21.2",
+	"userId": "user-1",
+	"encrypted": false,
+	"vaults": {
+		"share-1": {
+			"name": "Personal",
+			"description": "",
+			"items": [
+				{
+					"itemId": "item-1",
+					"shareId": "share-1",
+					"state": 1,
+					"data": {
+						"type": "login",
+						"metadata": {"name": "GitHub", "note": "Work account", "itemUuid": "a1"},
+						"extraFields": [
+							{"fieldName": "Recovery email", "type": "text", "data": {"content": "backup@example.com"}}
+						],
+						"content": {
+							"itemEmail": "alice@example.com",
+							"itemUsername": "alice",
+							"password": "hunter2",
+							"urls": ["https://github.com/login", "https://gist.github.com"],
+							"totpUri": "otpauth://totp/GitHub:alice?secret=JBSWY3DPEHPK3PXP&issuer=GitHub",
+							"passkeys": [
+								{"keyId": "k1", "rpId": "github.com", "rpName": "GitHub", "domain": "github.com", "userName": "alice", "credentialId": "Y3JlZA", "createTime": 1700000000}
+							]
+						}
+					}
+				},
+				{
+					"itemId": "item-2",
+					"shareId": "share-1",
+					"state": 1,
+					"data": {
+						"type": "login",
+						"metadata": {"name": "Example", "note": ""},
+						"extraFields": [
+							{"fieldName": "2FA", "type": "totp", "data": {"totpUri": "otpauth://totp/Example?secret=GEZDGNBV"}}
+						],
+						"content": {"username": "bob", "password": "pw", "urls": ["https://example.com"], "totpUri": "", "passkeys": []}
+					}
+				},
+				{
+					"itemId": "item-3",
+					"shareId": "share-1",
+					"state": 2,
+					"data": {
+						"type": "login",
+						"metadata": {"name": "Deleted", "note": ""},
+						"content": {"itemUsername": "carol", "password": "pw", "urls": []}
+					}
+				},
+				{
+					"itemId": "item-4",
+					"shareId": "share-1",
+					"state": 1,
+					"data": {
+						"type": "note",
+						"metadata": {"name": "Secure note", "note": "text"},
+						"content": {}
+					}
+				}
+			]
+		}
+	}
+}`
+
+// buildProtonPassZip 构造与Proton Pass导出相同目录结构的压缩包
+func buildProtonPassZip(t *testing.T, name string, content []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	w, err := archive.Create("Proton Pass/" + name)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if _, err := w.Write(content); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// encryptProtonPassPGP 用口令对称加密并输出ASCII armor格式的PGP消息
+func encryptProtonPassPGP(t *testing.T, plaintext []byte, passphrase string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	armored, err := armor.Encode(&buf, "PGP MESSAGE", nil)
+	if err != nil {
+		t.Fatalf("armor.Encode failed: %v", err)
+	}
+	w, err := openpgp.SymmetricallyEncrypt(armored, []byte(passphrase), nil, nil)
+	if err != nil {
+		t.Fatalf("SymmetricallyEncrypt failed: %v", err)
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := armored.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestProtonPassParser_Parse(t *testing.T) {
+	parser := NewProtonPassParser()
+	credentials, err := parser.Parse(bytes.NewReader(buildProtonPassZip(t, "data.json", []byte(testProtonPassExport))))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	// 回收站中的项目和非login类型应该被跳过
+	if len(credentials) != 2 {
+		t.Fatalf("Expected 2 credentials, got %d", len(credentials))
+	}
+
+	cred1 := credentials[0]
+	if cred1.ID != "item-1" || cred1.Title != "GitHub" || cred1.Username != "alice" || cred1.Password != "hunter2" {
+		t.Errorf("Unexpected credential: %+v", cred1)
+	}
+	if len(cred1.URLs) != 2 || cred1.URL != "https://github.com/login" {
+		t.Errorf("Expected 2 URLs, got %v", cred1.URLs)
+	}
+	if !strings.HasPrefix(cred1.TOTP, "otpauth://totp/") {
+		t.Errorf("Expected totpUri mapped to TOTP, got '%s'", cred1.TOTP)
+	}
+	if cred1.Passkey != "github.com" {
+		t.Errorf("Expected passkey rpId, got '%s'", cred1.Passkey)
+	}
+	if len(cred1.Tags) != 1 || cred1.Tags[0] != "Personal" {
+		t.Errorf("Expected vault name as tag, got %v", cred1.Tags)
+	}
+	if cred1.Notes != "Work account; Recovery email: backup@example.com" {
+		t.Errorf("Unexpected notes: '%s'", cred1.Notes)
+	}
+
+	// 旧版本的username字段和自定义TOTP字段
+	cred2 := credentials[1]
+	if cred2.Username != "bob" || cred2.TOTP != "otpauth://totp/Example?secret=GEZDGNBV" {
+		t.Errorf("Unexpected credential: %+v", cred2)
+	}
+}
+
+// 只有Passkey的登录项应保留，用户名取自Passkey
+func TestProtonPassParser_PasskeyOnly(t *testing.T) {
+	export := `{"version": "1.21.2", "encrypted": false, "vaults": {"share-1": {"name": "Personal", "items": [
+		{
+			"itemId": "item-5",
+			"state": 1,
+			"data": {
+				"type": "login",
+				"metadata": {"name": "Passkey Demo"},
+				"content": {
+					"itemEmail": "", "itemUsername": "", "password": "", "urls": ["https://passkeys.example.com"],
+					"passkeys": [{"keyId": "k2", "rpId": "passkeys.example.com", "userName": "dave", "credentialId": "Y3JlZDI"}]
+				}
+			}
+		}
+	]}}}`
+
+	credentials, err := NewProtonPassParser().Parse(bytes.NewReader(buildProtonPassZip(t, "data.json", []byte(export))))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(credentials) != 1 {
+		t.Fatalf("Expected the passkey-only login to be kept, got %d credentials", len(credentials))
+	}
+	if cred := credentials[0]; cred.Username != "dave" || cred.Passkey != "passkeys.example.com" {
+		t.Errorf("Unexpected credential: %+v", cred)
+	}
+}
+
+func TestProtonPassParser_EncryptedExport(t *testing.T) {
+	testCases := []struct {
+		name string
+		data func(t *testing.T) []byte
+	}{
+		{
+			name: "data.pgp in archive",
+			data: func(t *testing.T) []byte {
+				return buildProtonPassZip(t, "data.pgp", encryptProtonPassPGP(t, []byte(testProtonPassExport), "correct horse"))
+			},
+		},
+		{
+			name: "encrypted archive",
+			data: func(t *testing.T) []byte {
+				return encryptProtonPassPGP(t, buildProtonPassZip(t, "data.json", []byte(testProtonPassExport)), "correct horse")
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser := NewProtonPassParser()
+			parser.SetPassphraseFunc(staticPassphrase("correct horse"))
+
+			credentials, err := parser.Parse(bytes.NewReader(tc.data(t)))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if len(credentials) != 2 || credentials[0].Passkey != "github.com" {
+				t.Errorf("Unexpected credentials: %+v", credentials)
+			}
+		})
+	}
+}
+
+func TestProtonPassParser_WrongPassphrase(t *testing.T) {
+	data := buildProtonPassZip(t, "data.pgp", encryptProtonPassPGP(t, []byte(testProtonPassExport), "correct horse"))
+
+	protonPass := NewProtonPassParser()
+	protonPass.SetPassphraseFunc(staticPassphrase("wrong"))
+	if _, err := protonPass.Parse(bytes.NewReader(data)); !errors.Is(err, ErrProtonPassWrongPassword) {
+		t.Errorf("Expected ErrProtonPassWrongPassword, got %v", err)
+	}
+
+	// 没有口令来源时不应尝试解密
+	if _, err := NewProtonPassParser().Parse(bytes.NewReader(data)); !errors.Is(err, parser.ErrPassphraseRequired) {
+		t.Errorf("Expected ErrPassphraseRequired, got %v", err)
+	}
+}
+
+func TestRegistry_DetectProtonPass(t *testing.T) {
+	testCases := []struct {
+		name string
+		data []byte
+	}{
+		{name: "json", data: []byte(testProtonPassExport)},
+		{name: "archive", data: buildProtonPassZip(t, "data.json", []byte(testProtonPassExport))},
+		{name: "pgp", data: encryptProtonPassPGP(t, []byte(testProtonPassExport), "pw")},
+	}
+
+	registry := newTestRegistry()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			detected, err := registry.Detect(bytes.NewReader(tc.data))
+			if err != nil {
+				t.Fatalf("Detect failed: %v", err)
+			}
+			if detected.Name() != "protonpass" {
+				t.Errorf("Expected parser 'protonpass', got '%s'", detected.Name())
+			}
+		})
+	}
+}
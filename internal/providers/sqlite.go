@@ -0,0 +1,404 @@
+package providers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// 只读的SQLite数据库解析，仅支持按顺序遍历表中的行，用于读取NSS的key4.db
+// 数据库整个读入内存，不处理WAL文件中尚未写回的修改
+
+const (
+	sqliteHeaderSize = 100
+	sqliteMagic      = "SQLite format 3\x00"
+
+	sqlitePageInteriorTable = 0x05
+	sqlitePageLeafTable     = 0x0d
+
+	// sqliteMaxDepth B树的最大深度，防止损坏的文件造成死循环
+	sqliteMaxDepth = 64
+)
+
+// sqliteDB 内存中的SQLite数据库
+type sqliteDB struct {
+	data       []byte
+	pageSize   int
+	usableSize int
+}
+
+// sqliteTable sqlite_schema中记录的表
+type sqliteTable struct {
+	name     string
+	rootPage int
+	columns  []string
+	rowidCol int // INTEGER PRIMARY KEY列的下标，值存放在rowid中；没有时为-1
+}
+
+// openSQLite 校验文件头并返回数据库
+func openSQLite(data []byte) (*sqliteDB, error) {
+	if len(data) < sqliteHeaderSize || !bytes.HasPrefix(data, []byte(sqliteMagic)) {
+		return nil, errors.New("not a SQLite database")
+	}
+
+	pageSize := int(binary.BigEndian.Uint16(data[16:18]))
+	if pageSize == 1 {
+		pageSize = 65536
+	}
+	if pageSize < 512 || pageSize&(pageSize-1) != 0 {
+		return nil, fmt.Errorf("invalid SQLite page size: %d", pageSize)
+	}
+	if encoding := binary.BigEndian.Uint32(data[56:60]); encoding > 1 {
+		return nil, fmt.Errorf("unsupported SQLite text encoding: %d", encoding)
+	}
+
+	return &sqliteDB{
+		data:       data,
+		pageSize:   pageSize,
+		usableSize: pageSize - int(data[20]),
+	}, nil
+}
+
+// page 返回页号对应的数据（页号从1开始）
+func (db *sqliteDB) page(number int) ([]byte, error) {
+	start := (number - 1) * db.pageSize
+	if number < 1 || start+db.pageSize > len(db.data) {
+		return nil, fmt.Errorf("SQLite page %d out of range", number)
+	}
+	return db.data[start : start+db.pageSize], nil
+}
+
+// table 从sqlite_schema中查找表及其列名
+func (db *sqliteDB) table(name string) (*sqliteTable, error) {
+	schema := &sqliteTable{name: "sqlite_schema", rootPage: 1, rowidCol: -1}
+
+	var found *sqliteTable
+	err := db.scan(schema, func(row []any) (bool, error) {
+		if len(row) < 5 {
+			return true, nil
+		}
+		kind, _ := row[0].(string)
+		tableName, _ := row[1].(string)
+		if kind != "table" || !strings.EqualFold(tableName, name) {
+			return true, nil
+		}
+		rootPage, _ := row[3].(int64)
+		sql, _ := row[4].(string)
+		columns, rowidCol := parseSQLiteColumns(sql)
+		found = &sqliteTable{name: tableName, rootPage: int(rootPage), columns: columns, rowidCol: rowidCol}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, fmt.Errorf("SQLite table %s not found", name)
+	}
+	return found, nil
+}
+
+// rows 按列名返回表中所有行
+func (db *sqliteDB) rows(table *sqliteTable) ([]map[string]any, error) {
+	var rows []map[string]any
+	err := db.scan(table, func(row []any) (bool, error) {
+		values := make(map[string]any, len(table.columns))
+		for i, column := range table.columns {
+			if i < len(row) {
+				values[column] = row[i]
+			}
+		}
+		rows = append(rows, values)
+		return true, nil
+	})
+	return rows, err
+}
+
+// scan 按rowid顺序遍历表的B树，visit返回false时停止
+func (db *sqliteDB) scan(table *sqliteTable, visit func(row []any) (bool, error)) error {
+	_, err := db.scanPage(table, table.rootPage, 0, visit)
+	return err
+}
+
+func (db *sqliteDB) scanPage(table *sqliteTable, number, depth int, visit func(row []any) (bool, error)) (bool, error) {
+	if depth > sqliteMaxDepth {
+		return false, errors.New("SQLite b-tree is too deep")
+	}
+
+	page, err := db.page(number)
+	if err != nil {
+		return false, err
+	}
+
+	// 第一页前100字节是文件头
+	offset := 0
+	if number == 1 {
+		offset = sqliteHeaderSize
+	}
+	if offset+8 > len(page) {
+		return false, fmt.Errorf("SQLite page %d is truncated", number)
+	}
+
+	kind := page[offset]
+	cellCount := int(binary.BigEndian.Uint16(page[offset+3 : offset+5]))
+	headerSize := 8
+	if kind == sqlitePageInteriorTable {
+		headerSize = 12
+	}
+	if offset+headerSize+cellCount*2 > len(page) {
+		return false, fmt.Errorf("SQLite page %d has an invalid cell count", number)
+	}
+
+	for i := 0; i < cellCount; i++ {
+		pointer := offset + headerSize + i*2
+		cellOffset := int(binary.BigEndian.Uint16(page[pointer : pointer+2]))
+		if cellOffset >= len(page) {
+			return false, fmt.Errorf("SQLite page %d has an invalid cell pointer", number)
+		}
+		cell := page[cellOffset:]
+
+		switch kind {
+		case sqlitePageInteriorTable:
+			if len(cell) < 4 {
+				return false, fmt.Errorf("SQLite page %d has a truncated cell", number)
+			}
+			next, err := db.scanPage(table, int(binary.BigEndian.Uint32(cell[:4])), depth+1, visit)
+			if err != nil || !next {
+				return next, err
+			}
+
+		case sqlitePageLeafTable:
+			row, err := db.leafRow(table, cell)
+			if err != nil {
+				return false, fmt.Errorf("SQLite page %d: %w", number, err)
+			}
+			next, err := visit(row)
+			if err != nil || !next {
+				return next, err
+			}
+
+		default:
+			return false, fmt.Errorf("SQLite page %d is not a table page (type %#x)", number, kind)
+		}
+	}
+
+	if kind == sqlitePageInteriorTable {
+		rightMost := int(binary.BigEndian.Uint32(page[offset+8 : offset+12]))
+		return db.scanPage(table, rightMost, depth+1, visit)
+	}
+	return true, nil
+}
+
+// leafRow 解析叶子页中的单元格，超出本页的内容从溢出页读取
+func (db *sqliteDB) leafRow(table *sqliteTable, cell []byte) ([]any, error) {
+	payloadSize, n := sqliteVarint(cell)
+	if n == 0 {
+		return nil, errors.New("truncated cell")
+	}
+	rowid, m := sqliteVarint(cell[n:])
+	if m == 0 {
+		return nil, errors.New("truncated cell")
+	}
+	cell = cell[n+m:]
+
+	payload, err := db.payload(cell, int(payloadSize))
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := decodeSQLiteRecord(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	// INTEGER PRIMARY KEY列保存为NULL，实际值是rowid
+	if table.rowidCol >= 0 && table.rowidCol < len(row) && row[table.rowidCol] == nil {
+		row[table.rowidCol] = int64(rowid)
+	}
+	return row, nil
+}
+
+// payload 拼接本页内容和溢出页链
+func (db *sqliteDB) payload(cell []byte, size int) ([]byte, error) {
+	if size < 0 || size > len(db.data) {
+		return nil, errors.New("invalid payload size")
+	}
+
+	maxLocal := db.usableSize - 35
+	local := size
+	if size > maxLocal {
+		minLocal := (db.usableSize-12)*32/255 - 23
+		local = minLocal + (size-minLocal)%(db.usableSize-4)
+		if local > maxLocal {
+			local = minLocal
+		}
+	}
+
+	if size == local {
+		if len(cell) < size {
+			return nil, errors.New("truncated payload")
+		}
+		return cell[:size], nil
+	}
+
+	if len(cell) < local+4 {
+		return nil, errors.New("truncated payload")
+	}
+	payload := make([]byte, 0, size)
+	payload = append(payload, cell[:local]...)
+
+	next := int(binary.BigEndian.Uint32(cell[local : local+4]))
+	for len(payload) < size {
+		if next == 0 {
+			return nil, errors.New("overflow chain ends early")
+		}
+		page, err := db.page(next)
+		if err != nil {
+			return nil, err
+		}
+		chunk := min(size-len(payload), db.usableSize-4)
+		payload = append(payload, page[4:4+chunk]...)
+		next = int(binary.BigEndian.Uint32(page[:4]))
+	}
+	return payload, nil
+}
+
+// decodeSQLiteRecord 解析记录格式：头部为各列的序列类型，随后是列值
+// 整数返回int64，浮点数返回float64，文本返回string，BLOB返回[]byte，NULL返回nil
+func decodeSQLiteRecord(payload []byte) ([]any, error) {
+	headerSize, n := sqliteVarint(payload)
+	if n == 0 || int(headerSize) > len(payload) || int(headerSize) < n {
+		return nil, errors.New("invalid record header")
+	}
+
+	header := payload[n:headerSize]
+	body := payload[headerSize:]
+
+	var row []any
+	for len(header) > 0 {
+		serialType, n := sqliteVarint(header)
+		if n == 0 {
+			return nil, errors.New("invalid record header")
+		}
+		header = header[n:]
+
+		size := sqliteSerialSize(serialType)
+		if size > len(body) {
+			return nil, errors.New("record body is truncated")
+		}
+		value := body[:size]
+		body = body[size:]
+
+		switch {
+		case serialType == 0:
+			row = append(row, nil)
+		case serialType >= 1 && serialType <= 6:
+			row = append(row, sqliteInt(value))
+		case serialType == 7:
+			row = append(row, math.Float64frombits(binary.BigEndian.Uint64(value)))
+		case serialType == 8:
+			row = append(row, int64(0))
+		case serialType == 9:
+			row = append(row, int64(1))
+		case serialType >= 12 && serialType%2 == 0:
+			row = append(row, value)
+		case serialType >= 13:
+			row = append(row, string(value))
+		default:
+			return nil, fmt.Errorf("invalid serial type %d", serialType)
+		}
+	}
+	return row, nil
+}
+
+// sqliteSerialSize 返回序列类型对应的值长度
+func sqliteSerialSize(serialType uint64) int {
+	switch {
+	case serialType <= 4:
+		return int(serialType)
+	case serialType == 5:
+		return 6
+	case serialType == 6 || serialType == 7:
+		return 8
+	case serialType >= 12:
+		return int((serialType - 12) / 2)
+	}
+	return 0
+}
+
+// sqliteInt 解析大端序的有符号整数
+func sqliteInt(value []byte) int64 {
+	var v int64
+	if len(value) > 0 && value[0]&0x80 != 0 {
+		v = -1
+	}
+	for _, b := range value {
+		v = v<<8 | int64(b)
+	}
+	return v
+}
+
+// sqliteVarint 解析SQLite的变长整数（1到9字节），返回值和读取的字节数，数据不足时返回0
+func sqliteVarint(data []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < 9 && i < len(data); i++ {
+		if i == 8 {
+			return v<<8 | uint64(data[i]), 9
+		}
+		v = v<<7 | uint64(data[i]&0x7f)
+		if data[i]&0x80 == 0 {
+			return v, i + 1
+		}
+	}
+	return 0, 0
+}
+
+// parseSQLiteColumns 从CREATE TABLE语句中提取列名，以及INTEGER PRIMARY KEY列的下标
+func parseSQLiteColumns(sql string) ([]string, int) {
+	start, end := strings.Index(sql, "("), strings.LastIndex(sql, ")")
+	if start < 0 || end <= start {
+		return nil, -1
+	}
+
+	// 按顶层逗号拆分列定义
+	var defs []string
+	depth, last := 0, start+1
+	for i := start + 1; i < end; i++ {
+		switch sql[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				defs = append(defs, sql[last:i])
+				last = i + 1
+			}
+		}
+	}
+	defs = append(defs, sql[last:end])
+
+	var columns []string
+	rowidCol := -1
+	for _, def := range defs {
+		fields := strings.Fields(def)
+		if len(fields) == 0 {
+			continue
+		}
+		// 跳过表级约束
+		switch strings.ToUpper(fields[0]) {
+		case "CONSTRAINT", "PRIMARY", "UNIQUE", "CHECK", "FOREIGN":
+			continue
+		}
+
+		name := strings.Trim(fields[0], "`\"[]")
+		upper := strings.ToUpper(strings.Join(fields[1:], " "))
+		if strings.HasPrefix(upper, "INTEGER PRIMARY KEY") && !strings.Contains(upper, "DESC") {
+			rowidCol = len(columns)
+		}
+		columns = append(columns, name)
+	}
+	return columns, rowidCol
+}
@@ -78,6 +78,22 @@ func (g *TableGenerator) Generate(writer io.Writer, report *types.AuditReport) e
 	fmt.Fprintf(writer, "  Issues Found:         %d\n", report.Summary.IssuesFound)
 	fmt.Fprintln(writer)
 
+	// 输入来源
+	if len(report.Summary.Sources) > 0 {
+		fmt.Fprintln(writer, "Sources:")
+		for _, source := range report.Summary.Sources {
+			fmt.Fprintf(writer, "  %s (%s): %d credentials", source.File, source.Provider, source.Credentials)
+			if source.Duplicates > 0 {
+				fmt.Fprintf(writer, ", %d duplicates", source.Duplicates)
+			}
+			fmt.Fprintln(writer)
+		}
+		fmt.Fprintln(writer)
+	}
+
+	// 多个来源时在每条结果后标注来源文件
+	showSources := len(report.Summary.Sources) > 1
+
 	// 问题统计
 	if len(report.Summary.ByType) > 0 {
 		fmt.Fprintln(writer, "Issues by Category:")
@@ -89,6 +105,60 @@ func (g *TableGenerator) Generate(writer io.Writer, report *types.AuditReport) e
 		if count, exists := report.Summary.ByType[types.DetectionMissingPasskey]; exists && count > 0 {
 			fmt.Fprintf(writer, "  Missing Passkey:      %d\n", count)
 		}
+
+		if count, exists := report.Summary.ByType[types.DetectionPwnedPassword]; exists && count > 0 {
+			fmt.Fprintf(writer, "  Pwned Passwords:      %d\n", count)
+		}
+
+		if count, exists := report.Summary.ByType[types.DetectionPasswordReuse]; exists && count > 0 {
+			fmt.Fprintf(writer, "  Reused Passwords:     %d\n", count)
+		}
+
+		if count, exists := report.Summary.ByType[types.DetectionSimilarPassword]; exists && count > 0 {
+			fmt.Fprintf(writer, "  Similar Passwords:    %d\n", count)
+		}
+
+		if count, exists := report.Summary.ByType[types.DetectionWeakPassword]; exists && count > 0 {
+			fmt.Fprintf(writer, "  Weak Passwords:       %d\n", count)
+		}
+
+		if count, exists := report.Summary.ByType[types.DetectionIdentityInPassword]; exists && count > 0 {
+			fmt.Fprintf(writer, "  Identity in Password: %d\n", count)
+		}
+
+		if count, exists := report.Summary.ByType[types.DetectionInsecureURL]; exists && count > 0 {
+			fmt.Fprintf(writer, "  Insecure Login URLs:  %d\n", count)
+		}
+
+		if count, exists := report.Summary.ByType[types.DetectionLookalikeDomain]; exists && count > 0 {
+			fmt.Fprintf(writer, "  Lookalike Domains:    %d\n", count)
+		}
+
+		if count, exists := report.Summary.ByType[types.DetectionTOTPMisconfigured]; exists && count > 0 {
+			fmt.Fprintf(writer, "  TOTP Misconfigured:   %d\n", count)
+		}
+
+		if count, exists := report.Summary.ByType[types.DetectionWeakSecondFactor]; exists && count > 0 {
+			fmt.Fprintf(writer, "  Weak Second Factors:  %d\n", count)
+		}
+
+		if count, exists := report.Summary.ByType[types.DetectionSecretInNotes]; exists && count > 0 {
+			fmt.Fprintf(writer, "  Secrets in Notes:     %d\n", count)
+		}
+		fmt.Fprintln(writer)
+	}
+
+	// 按凭据类别统计，只有一个类别时不显示
+	if len(report.Summary.ByCategory) > 1 {
+		fmt.Fprintln(writer, "Issues by Item Category:")
+		categories := make([]string, 0, len(report.Summary.ByCategory))
+		for category := range report.Summary.ByCategory {
+			categories = append(categories, category)
+		}
+		sort.Strings(categories)
+		for _, category := range categories {
+			fmt.Fprintf(writer, "  %-21s %d\n", category+":", report.Summary.ByCategory[category])
+		}
 		fmt.Fprintln(writer)
 	}
 
@@ -97,6 +167,16 @@ func (g *TableGenerator) Generate(writer io.Writer, report *types.AuditReport) e
 		// 按类型分组
 		twofaResults := []types.DetectionResult{}
 		passkeyResults := []types.DetectionResult{}
+		pwnedResults := []types.DetectionResult{}
+		reuseResults := []types.DetectionResult{}
+		similarResults := []types.DetectionResult{}
+		weakResults := []types.DetectionResult{}
+		identityResults := []types.DetectionResult{}
+		insecureURLResults := []types.DetectionResult{}
+		lookalikeResults := []types.DetectionResult{}
+		totpResults := []types.DetectionResult{}
+		weakFactorResults := []types.DetectionResult{}
+		secretResults := []types.DetectionResult{}
 
 		for _, result := range report.Results {
 			switch result.Type {
@@ -104,20 +184,110 @@ func (g *TableGenerator) Generate(writer io.Writer, report *types.AuditReport) e
 				twofaResults = append(twofaResults, result)
 			case types.DetectionMissingPasskey:
 				passkeyResults = append(passkeyResults, result)
+			case types.DetectionPwnedPassword:
+				pwnedResults = append(pwnedResults, result)
+			case types.DetectionPasswordReuse:
+				reuseResults = append(reuseResults, result)
+			case types.DetectionSimilarPassword:
+				similarResults = append(similarResults, result)
+			case types.DetectionWeakPassword:
+				weakResults = append(weakResults, result)
+			case types.DetectionIdentityInPassword:
+				identityResults = append(identityResults, result)
+			case types.DetectionInsecureURL:
+				insecureURLResults = append(insecureURLResults, result)
+			case types.DetectionLookalikeDomain:
+				lookalikeResults = append(lookalikeResults, result)
+			case types.DetectionTOTPMisconfigured:
+				totpResults = append(totpResults, result)
+			case types.DetectionWeakSecondFactor:
+				weakFactorResults = append(weakFactorResults, result)
+			case types.DetectionSecretInNotes:
+				secretResults = append(secretResults, result)
 			}
 		}
 
+		// 已泄露密码最紧急，排在最前
+		if len(pwnedResults) > 0 {
+			fmt.Fprintf(writer, "%s\n", bold(red(fmt.Sprintf("Pwned Password Issues (%d total):", len(pwnedResults)))))
+			g.generatePwnedResults(writer, pwnedResults, showSources)
+			fmt.Fprintln(writer)
+		}
+
+		// 仿冒域名可能意味着凭据已在钓鱼页面上填写
+		if len(lookalikeResults) > 0 {
+			fmt.Fprintf(writer, "%s\n", bold(red(fmt.Sprintf("Lookalike Domains (%d total):", len(lookalikeResults)))))
+			g.generateLookalikeResults(writer, lookalikeResults, showSources)
+			fmt.Fprintln(writer)
+		}
+
+		// 备注中的私钥、助记词和令牌本身就可直接使用
+		if len(secretResults) > 0 {
+			fmt.Fprintf(writer, "%s\n", bold(red(fmt.Sprintf("Secrets in Notes (%d total):", len(secretResults)))))
+			g.generateSecretResults(writer, secretResults, showSources)
+			fmt.Fprintln(writer)
+		}
+
+		// 密码复用问题
+		if len(reuseResults) > 0 {
+			fmt.Fprintf(writer, "%s\n", bold(red(fmt.Sprintf("Password Reuse Issues (%d total):", len(reuseResults)))))
+			g.generateReuseResults(writer, reuseResults, showSources)
+			fmt.Fprintln(writer)
+		}
+
+		// 相似密码家族
+		if len(similarResults) > 0 {
+			fmt.Fprintf(writer, "%s\n", bold(yellow(fmt.Sprintf("Similar Password Families (%d total):", len(similarResults)))))
+			g.generateSimilarResults(writer, similarResults, showSources)
+			fmt.Fprintln(writer)
+		}
+
+		// 弱密码
+		if len(weakResults) > 0 {
+			fmt.Fprintf(writer, "%s\n", bold(yellow(fmt.Sprintf("Weak Passwords (%d total):", len(weakResults)))))
+			g.generateWeakResults(writer, weakResults, showSources)
+			fmt.Fprintln(writer)
+		}
+
+		// 包含身份信息的密码
+		if len(identityResults) > 0 {
+			fmt.Fprintf(writer, "%s\n", bold(yellow(fmt.Sprintf("Passwords Containing Identity (%d total):", len(identityResults)))))
+			g.generateIdentityResults(writer, identityResults, showSources)
+			fmt.Fprintln(writer)
+		}
+
+		// 不安全的登录地址
+		if len(insecureURLResults) > 0 {
+			fmt.Fprintf(writer, "%s\n", bold(yellow(fmt.Sprintf("Insecure Login URLs (%d total):", len(insecureURLResults)))))
+			g.generateInsecureURLResults(writer, insecureURLResults, showSources)
+			fmt.Fprintln(writer)
+		}
+
+		// TOTP配置问题，无法使用的TOTP同时会出现在2FA问题中
+		if len(totpResults) > 0 {
+			fmt.Fprintf(writer, "%s\n", bold(yellow(fmt.Sprintf("TOTP Configuration Issues (%d total):", len(totpResults)))))
+			g.generateTOTPResults(writer, totpResults, showSources)
+			fmt.Fprintln(writer)
+		}
+
 		// 2FA问题
 		if len(twofaResults) > 0 {
 			fmt.Fprintf(writer, "%s\n", bold(red(fmt.Sprintf("Two-Factor Authentication Issues (%d total):", len(twofaResults)))))
-			g.generateClusteredResults(writer, twofaResults)
+			g.generateClusteredResults(writer, twofaResults, showSources)
+			fmt.Fprintln(writer)
+		}
+
+		// 只提供短信、电话或邮件验证的站点
+		if len(weakFactorResults) > 0 {
+			fmt.Fprintf(writer, "%s\n", bold(yellow(fmt.Sprintf("SMS/Email-only Second Factors (%d total):", len(weakFactorResults)))))
+			g.generateWeakFactorResults(writer, weakFactorResults, showSources)
 			fmt.Fprintln(writer)
 		}
 
 		// Passkey问题
 		if len(passkeyResults) > 0 {
 			fmt.Fprintf(writer, "%s\n", bold(green(fmt.Sprintf("Passkey Authentication Issues (%d total):", len(passkeyResults)))))
-			g.generateClusteredResults(writer, passkeyResults)
+			g.generateClusteredResults(writer, passkeyResults, showSources)
 			fmt.Fprintln(writer)
 		}
 	}
@@ -126,7 +296,7 @@ func (g *TableGenerator) Generate(writer io.Writer, report *types.AuditReport) e
 }
 
 // generateClusteredResults 生成基于相似度聚类的结果
-func (g *TableGenerator) generateClusteredResults(writer io.Writer, results []types.DetectionResult) {
+func (g *TableGenerator) generateClusteredResults(writer io.Writer, results []types.DetectionResult, showSources bool) {
 	if len(results) == 0 {
 		return
 	}
@@ -158,11 +328,214 @@ func (g *TableGenerator) generateClusteredResults(writer io.Writer, results []ty
 			if domain != "-" && domain != "" {
 				fmt.Fprintf(writer, " %s", purple(fmt.Sprintf("(%s)", domain)))
 			}
+			if showSources && len(result.Sources) > 0 {
+				fmt.Fprintf(writer, " [%s]", g.formatSources(result.Sources))
+			}
 			fmt.Fprintln(writer)
 		}
 	}
 }
 
+// generatePwnedResults 按泄露次数从多到少列出已泄露的密码
+func (g *TableGenerator) generatePwnedResults(writer io.Writer, results []types.DetectionResult, showSources bool) {
+	sort.SliceStable(results, func(i, j int) bool {
+		return g.breachCount(results[i].Metadata) > g.breachCount(results[j].Metadata)
+	})
+
+	for _, result := range results {
+		fmt.Fprintf(writer, "  %s", blue(result.Title))
+		if domain := g.extractDomain(result.Metadata); domain != "-" && domain != "" {
+			fmt.Fprintf(writer, " %s", purple(fmt.Sprintf("(%s)", domain)))
+		}
+		fmt.Fprintf(writer, " %s", yellow(fmt.Sprintf("[%s, seen %d times]", result.Severity, g.breachCount(result.Metadata))))
+		if showSources && len(result.Sources) > 0 {
+			fmt.Fprintf(writer, " [%s]", g.formatSources(result.Sources))
+		}
+		fmt.Fprintln(writer)
+	}
+}
+
+// generateReuseResults 逐条列出复用的密码及共用该密码的站点
+func (g *TableGenerator) generateReuseResults(writer io.Writer, results []types.DetectionResult, showSources bool) {
+	for _, result := range results {
+		fmt.Fprintf(writer, "  %s", blue(result.Title))
+		if domain := g.extractDomain(result.Metadata); domain != "-" && domain != "" {
+			fmt.Fprintf(writer, " %s", purple(fmt.Sprintf("(%s)", domain)))
+		}
+		others, _ := result.Metadata["reused_with"].([]string)
+		fmt.Fprintf(writer, " %s", yellow(fmt.Sprintf("[%s, shared with %d others]", result.Severity, len(others))))
+		if domains, ok := result.Metadata["domains"].([]string); ok && len(domains) > 0 {
+			fmt.Fprintf(writer, " %s", strings.Join(domains, ", "))
+		}
+		if showSources && len(result.Sources) > 0 {
+			fmt.Fprintf(writer, " [%s]", g.formatSources(result.Sources))
+		}
+		fmt.Fprintln(writer)
+	}
+}
+
+// generateSimilarResults 按家族分组列出结构相近的密码
+func (g *TableGenerator) generateSimilarResults(writer io.Writer, results []types.DetectionResult, showSources bool) {
+	lastFamily := 0
+	for _, result := range results {
+		if family, _ := result.Metadata["family_id"].(int); family != lastFamily {
+			patterns, _ := result.Metadata["patterns"].([]string)
+			fmt.Fprintf(writer, "\n[%s] %s\n", bold(cyan(fmt.Sprintf("Family %d", family))), yellow(strings.Join(patterns, ", ")))
+			lastFamily = family
+		}
+		fmt.Fprintf(writer, "  %s", blue(result.Title))
+		if domain := g.extractDomain(result.Metadata); domain != "-" && domain != "" {
+			fmt.Fprintf(writer, " %s", purple(fmt.Sprintf("(%s)", domain)))
+		}
+		if similarity, ok := result.Metadata["similarity"].(float64); ok {
+			fmt.Fprintf(writer, " %s", yellow(fmt.Sprintf("[%s, similarity %.2f]", result.Severity, similarity)))
+		}
+		if showSources && len(result.Sources) > 0 {
+			fmt.Fprintf(writer, " [%s]", g.formatSources(result.Sources))
+		}
+		fmt.Fprintln(writer)
+	}
+}
+
+// generateWeakResults 按估算的猜测次数从少到多列出弱密码
+func (g *TableGenerator) generateWeakResults(writer io.Writer, results []types.DetectionResult, showSources bool) {
+	guessesLog10 := func(result types.DetectionResult) float64 {
+		value, _ := result.Metadata["guesses_log10"].(float64)
+		return value
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return guessesLog10(results[i]) < guessesLog10(results[j])
+	})
+
+	for _, result := range results {
+		fmt.Fprintf(writer, "  %s", blue(result.Title))
+		if domain := g.extractDomain(result.Metadata); domain != "-" && domain != "" {
+			fmt.Fprintf(writer, " %s", purple(fmt.Sprintf("(%s)", domain)))
+		}
+		crackTime, _ := result.Metadata["crack_time_display"].(string)
+		fmt.Fprintf(writer, " %s", yellow(fmt.Sprintf("[%s, cracked in %s]", result.Severity, crackTime)))
+		if patterns, ok := result.Metadata["patterns"].([]string); ok && len(patterns) > 0 {
+			fmt.Fprintf(writer, " %s", strings.Join(patterns, ", "))
+		}
+		if showSources && len(result.Sources) > 0 {
+			fmt.Fprintf(writer, " [%s]", g.formatSources(result.Sources))
+		}
+		fmt.Fprintln(writer)
+	}
+}
+
+// generateIdentityResults 列出包含身份信息的密码及泄露的信息来源
+func (g *TableGenerator) generateIdentityResults(writer io.Writer, results []types.DetectionResult, showSources bool) {
+	for _, result := range results {
+		fmt.Fprintf(writer, "  %s", blue(result.Title))
+		if domain := g.extractDomain(result.Metadata); domain != "-" && domain != "" {
+			fmt.Fprintf(writer, " %s", purple(fmt.Sprintf("(%s)", domain)))
+		}
+		components, _ := result.Metadata["components"].([]string)
+		fmt.Fprintf(writer, " %s", yellow(fmt.Sprintf("[%s, %s]", result.Severity, strings.Join(components, ", "))))
+		if showSources && len(result.Sources) > 0 {
+			fmt.Fprintf(writer, " [%s]", g.formatSources(result.Sources))
+		}
+		fmt.Fprintln(writer)
+	}
+}
+
+// generateInsecureURLResults 列出不安全的登录地址及其问题，地址已去掉userinfo和查询串
+func (g *TableGenerator) generateInsecureURLResults(writer io.Writer, results []types.DetectionResult, showSources bool) {
+	for _, result := range results {
+		fmt.Fprintf(writer, "  %s", blue(result.Title))
+		if url, _ := result.Metadata["url"].(string); url != "" {
+			fmt.Fprintf(writer, " %s", purple(fmt.Sprintf("(%s)", url)))
+		}
+		issues, _ := result.Metadata["issues"].([]string)
+		fmt.Fprintf(writer, " %s", yellow(fmt.Sprintf("[%s, %s]", result.Severity, strings.Join(issues, ", "))))
+		if showSources && len(result.Sources) > 0 {
+			fmt.Fprintf(writer, " [%s]", g.formatSources(result.Sources))
+		}
+		fmt.Fprintln(writer)
+	}
+}
+
+// generateLookalikeResults 列出仿冒域名及被仿冒的已知站点
+func (g *TableGenerator) generateLookalikeResults(writer io.Writer, results []types.DetectionResult, showSources bool) {
+	for _, result := range results {
+		fmt.Fprintf(writer, "  %s", blue(result.Title))
+		domain, _ := result.Metadata["domain"].(string)
+		target, _ := result.Metadata["target"].(string)
+		fmt.Fprintf(writer, " %s", purple(fmt.Sprintf("(%s → %s)", domain, target)))
+		techniques, _ := result.Metadata["techniques"].([]string)
+		fmt.Fprintf(writer, " %s", yellow(fmt.Sprintf("[%s, %s]", result.Severity, strings.Join(techniques, ", "))))
+		if showSources && len(result.Sources) > 0 {
+			fmt.Fprintf(writer, " [%s]", g.formatSources(result.Sources))
+		}
+		fmt.Fprintln(writer)
+	}
+}
+
+// generateTOTPResults 列出TOTP配置问题
+func (g *TableGenerator) generateTOTPResults(writer io.Writer, results []types.DetectionResult, showSources bool) {
+	for _, result := range results {
+		fmt.Fprintf(writer, "  %s", blue(result.Title))
+		if domain := g.extractDomain(result.Metadata); domain != "-" && domain != "" {
+			fmt.Fprintf(writer, " %s", purple(fmt.Sprintf("(%s)", domain)))
+		}
+		issues, _ := result.Metadata["issues"].([]string)
+		fmt.Fprintf(writer, " %s", yellow(fmt.Sprintf("[%s, %s]", result.Severity, strings.Join(issues, ", "))))
+		if showSources && len(result.Sources) > 0 {
+			fmt.Fprintf(writer, " [%s]", g.formatSources(result.Sources))
+		}
+		fmt.Fprintln(writer)
+	}
+}
+
+// generateWeakFactorResults 列出只提供可被截获的第二因素的站点及其方法
+func (g *TableGenerator) generateWeakFactorResults(writer io.Writer, results []types.DetectionResult, showSources bool) {
+	for _, result := range results {
+		fmt.Fprintf(writer, "  %s", blue(result.Title))
+		if domain := g.extractDomain(result.Metadata); domain != "-" && domain != "" {
+			fmt.Fprintf(writer, " %s", purple(fmt.Sprintf("(%s)", domain)))
+		}
+		methods, _ := result.Metadata["supported_methods"].([]string)
+		fmt.Fprintf(writer, " %s", yellow(fmt.Sprintf("[%s, %s]", result.Severity, strings.Join(methods, ", "))))
+		if showSources && len(result.Sources) > 0 {
+			fmt.Fprintf(writer, " [%s]", g.formatSources(result.Sources))
+		}
+		fmt.Fprintln(writer)
+	}
+}
+
+// generateSecretResults 列出备注中的敏感信息及脱敏片段
+func (g *TableGenerator) generateSecretResults(writer io.Writer, results []types.DetectionResult, showSources bool) {
+	for _, result := range results {
+		fmt.Fprintf(writer, "  %s", blue(result.Title))
+		if domain := g.extractDomain(result.Metadata); domain != "-" && domain != "" {
+			fmt.Fprintf(writer, " %s", purple(fmt.Sprintf("(%s)", domain)))
+		}
+		category, _ := result.Metadata["category"].(string)
+		excerpt, _ := result.Metadata["excerpt"].(string)
+		fmt.Fprintf(writer, " %s %s", yellow(fmt.Sprintf("[%s, %s]", result.Severity, category)), excerpt)
+		if showSources && len(result.Sources) > 0 {
+			fmt.Fprintf(writer, " [%s]", g.formatSources(result.Sources))
+		}
+		fmt.Fprintln(writer)
+	}
+}
+
+// breachCount 读取结果中的泄露次数
+func (g *TableGenerator) breachCount(metadata map[string]interface{}) int {
+	count, _ := metadata["breach_count"].(int)
+	return count
+}
+
+// formatSources 格式化结果的来源文件列表
+func (g *TableGenerator) formatSources(sources []types.CredentialSource) string {
+	files := make([]string, len(sources))
+	for i, source := range sources {
+		files[i] = source.File
+	}
+	return strings.Join(files, ", ")
+}
+
 // Cluster 聚类结构
 type Cluster struct {
 	Results  []types.DetectionResult
@@ -0,0 +1,161 @@
+package secrets
+
+import (
+	"crypto/sha256"
+	_ "embed"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// BIP39英文词表，行号（从0开始）即单词的11位索引
+//
+//go:embed data/bip39_english.txt
+var bip39List string
+
+// bip39Lengths 助记词的合法单词数
+var bip39Lengths = []int{24, 21, 18, 15, 12}
+
+const minMnemonicWords = 12
+
+var (
+	bip39Once  sync.Once
+	bip39Index map[string]int
+)
+
+func loadBIP39() map[string]int {
+	bip39Once.Do(func() {
+		words := strings.Fields(bip39List)
+		bip39Index = make(map[string]int, len(words))
+		for i, word := range words {
+			bip39Index[word] = i
+		}
+	})
+	return bip39Index
+}
+
+// mnemonicMatch 文本中连续出现的BIP39单词
+type mnemonicMatch struct {
+	start         int // 首个单词的字节位置
+	words         int
+	checksumValid bool
+}
+
+// findMnemonics 查找至少12个连续的词表单词；单词之间只能有空白，单词可以带列表编号（如"1."）和末尾的标点
+// 其他任何内容（包括数字与字母混合的片段）都会打断连续的单词
+// 合法长度的窗口通过校验和时按该窗口报告，否则报告整段
+func findMnemonics(text string, index map[string]int) []mnemonicMatch {
+	type word struct {
+		start int
+		value string
+	}
+	var matches []mnemonicMatch
+	var run []word
+	flush := func() {
+		if len(run) >= minMnemonicWords {
+			match := mnemonicMatch{start: run[0].start, words: len(run)}
+		search:
+			for _, length := range bip39Lengths {
+				for i := 0; i+length <= len(run); i++ {
+					values := make([]string, length)
+					for j := range values {
+						values[j] = run[i+j].value
+					}
+					if validMnemonicChecksum(values, index) {
+						match = mnemonicMatch{start: run[i].start, words: length, checksumValid: true}
+						break search
+					}
+				}
+			}
+			matches = append(matches, match)
+		}
+		run = run[:0]
+	}
+
+	start := -1
+	for i, r := range text + " " {
+		if !unicode.IsSpace(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start < 0 {
+			continue
+		}
+		value, numbering := mnemonicToken(text[start:i])
+		switch {
+		case numbering:
+			// 单独的列表编号不打断连续的单词
+		case value != "":
+			if _, exists := index[value]; exists {
+				run = append(run, word{start: start, value: value})
+			} else {
+				flush()
+			}
+		default:
+			flush()
+		}
+		start = -1
+	}
+	flush()
+	return matches
+}
+
+// mnemonicToken 去掉列表编号和首尾标点后返回小写单词；token只是列表编号时numbering为true
+// 去掉编号和标点后仍含有非字母字符时返回空
+func mnemonicToken(token string) (string, bool) {
+	token = strings.TrimLeft(token, "(")
+	token = strings.TrimRight(token, ".,;:!?)")
+	digits := strings.TrimLeftFunc(token, unicode.IsDigit)
+	if digits != token {
+		// 编号后必须紧跟分隔符，如"1."、"2)"，避免把"7EXAMPLE"这类片段当成单词
+		rest := strings.TrimLeft(digits, ".):")
+		if rest == "" {
+			return "", true
+		}
+		if rest == digits {
+			return "", false
+		}
+		token = rest
+	}
+	for _, r := range token {
+		if !unicode.IsLetter(r) {
+			return "", false
+		}
+	}
+	return strings.ToLower(token), false
+}
+
+// validMnemonicChecksum 按BIP39校验：11位索引拼接后，末尾的校验位等于熵的SHA-256前若干位
+func validMnemonicChecksum(words []string, index map[string]int) bool {
+	if !slices.Contains(bip39Lengths, len(words)) {
+		return false
+	}
+	totalBits := len(words) * 11
+	checksumBits := totalBits / 33
+	entropyBits := totalBits - checksumBits
+
+	bits := make([]byte, (totalBits+7)/8)
+	for i, word := range words {
+		value := index[word]
+		for b := 0; b < 11; b++ {
+			if value&(1<<(10-b)) != 0 {
+				pos := i*11 + b
+				bits[pos/8] |= 0x80 >> (pos % 8)
+			}
+		}
+	}
+
+	hash := sha256.Sum256(bits[:entropyBits/8])
+	for b := 0; b < checksumBits; b++ {
+		pos := entropyBits + b
+		expected := hash[b/8]&(0x80>>(b%8)) != 0
+		actual := bits[pos/8]&(0x80>>(pos%8)) != 0
+		if expected != actual {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,403 @@
+// Package secrets 扫描备注和自定义文本字段中不应与密码放在一起的敏感信息：
+// 云服务和API令牌、PEM私钥、BIP39助记词、2FA备用码以及密保问题的答案
+// 结果只包含脱敏后的片段，不包含敏感信息本身
+package secrets
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/yourorg/unpass/internal/types"
+)
+
+// Category 敏感信息的类别
+type Category string
+
+const (
+	CategoryAPIToken       Category = "api_token"
+	CategoryPrivateKey     Category = "private_key"
+	CategorySeedPhrase     Category = "seed_phrase"
+	CategoryBackupCodes    Category = "backup_codes"
+	CategorySecurityAnswer Category = "security_answer"
+)
+
+// 规则名称，出现在Finding的Rule字段
+const (
+	RuleAWSAccessKey   = "aws_access_key"
+	RuleAWSSecretKey   = "aws_secret_key"
+	RuleGitHubToken    = "github_token"
+	RuleSlackToken     = "slack_token"
+	RuleSlackWebhook   = "slack_webhook"
+	RuleStripeKey      = "stripe_key"
+	RuleStripeTestKey  = "stripe_test_key"
+	RulePrivateKey     = "pem_private_key"
+	RuleSeedPhrase     = "bip39_mnemonic"
+	RuleBackupCodes    = "backup_codes"
+	RuleSecurityAnswer = "security_answer"
+)
+
+const (
+	// maskLength 脱敏片段固定使用的星号数，不透露原值长度
+	maskLength = 8
+	// redactedAnswer 密保答案在片段中的占位
+	redactedAnswer = "[redacted]"
+)
+
+// Finding 文本中发现的一处敏感信息
+type Finding struct {
+	Category    Category
+	Rule        string
+	Description string // 如"a GitHub token"，用于拼接提示信息
+	Excerpt     string // 脱敏后的片段
+	Severity    types.Severity
+
+	offset int // 在文本中的字节位置，用于排序
+}
+
+// tokenRule 按正则匹配的令牌，第一个分组为令牌本身，保留前keep个字符作为可公开的前缀
+type tokenRule struct {
+	rule        string
+	description string
+	pattern     *regexp.Regexp
+	keep        int
+	severity    types.Severity
+}
+
+var tokenRules = []tokenRule{
+	{
+		rule:        RuleAWSAccessKey,
+		description: "an AWS access key ID",
+		pattern:     regexp.MustCompile(`\b((?:AKIA|ASIA|AGPA|AIDA|AROA|AIPA|ANPA|ANVA)[0-9A-Z]{16})\b`),
+		keep:        4,
+		severity:    types.SeverityHigh,
+	},
+	{
+		// 秘密访问密钥没有固定前缀，只在附近出现aws和secret字样时认定
+		rule:        RuleAWSSecretKey,
+		description: "an AWS secret access key",
+		pattern:     regexp.MustCompile(`(?i)aws[\w .-]{0,20}?secret[\w .-]{0,20}?[:=\s"']+([A-Za-z0-9/+]{40})(?:[^A-Za-z0-9/+=]|$)`),
+		keep:        0,
+		severity:    types.SeverityCritical,
+	},
+	{
+		rule:        RuleGitHubToken,
+		description: "a GitHub token",
+		pattern:     regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,255})\b`),
+		keep:        4,
+		severity:    types.SeverityCritical,
+	},
+	{
+		rule:        RuleGitHubToken,
+		description: "a GitHub token",
+		pattern:     regexp.MustCompile(`\b(github_pat_[A-Za-z0-9_]{22,255})\b`),
+		keep:        11,
+		severity:    types.SeverityCritical,
+	},
+	{
+		rule:        RuleSlackToken,
+		description: "a Slack token",
+		pattern:     regexp.MustCompile(`\b(xox[abeoprs]-[A-Za-z0-9-]{10,})`),
+		keep:        5,
+		severity:    types.SeverityHigh,
+	},
+	{
+		rule:        RuleSlackWebhook,
+		description: "a Slack webhook URL",
+		pattern:     regexp.MustCompile(`(https://hooks\.slack\.com/(?:services|workflows|triggers)/[A-Za-z0-9_/-]+)`),
+		keep:        len("https://hooks.slack.com/"),
+		severity:    types.SeverityHigh,
+	},
+	{
+		rule:        RuleStripeKey,
+		description: "a Stripe live secret key",
+		pattern:     regexp.MustCompile(`\b((?:sk|rk)_live_[A-Za-z0-9]{20,})\b`),
+		keep:        8,
+		severity:    types.SeverityCritical,
+	},
+	{
+		rule:        RuleStripeTestKey,
+		description: "a Stripe test secret key",
+		pattern:     regexp.MustCompile(`\b((?:sk|rk)_test_[A-Za-z0-9]{20,})\b`),
+		keep:        8,
+		severity:    types.SeverityMedium,
+	},
+}
+
+var (
+	// pemPattern 第一个分组为密钥类型（RSA、EC、OPENSSH、ENCRYPTED等），第二个分组为BEGIN之后的内容
+	pemPattern = regexp.MustCompile(`-----BEGIN ((?:[A-Z0-9]+ )*)PRIVATE KEY-----([\s\S]{0,200})`)
+
+	// backupKeywordPattern 备用码的标签，如"Backup codes"、"Recovery codes:"、"scratch code"
+	backupKeywordPattern = regexp.MustCompile(`(?i)\b(?:backup|recovery|scratch|emergency|one[- ]time|2fa|mfa)[ \t-]*(?:login[ \t]+|verification[ \t]+|security[ \t]+)?codes?\b`)
+	// codePattern 单个备用码：3~12位字母数字分组，可带一个连字符，如12345678、a1b2c-3d4e5、ABCD-EFGH-1234
+	codePattern = regexp.MustCompile(`^[A-Za-z0-9]{3,12}(?:-[A-Za-z0-9]{3,12}){0,2}$`)
+	// listMarkerPattern 编号列表的序号，如"1."、"2)"、"-"
+	listMarkerPattern = regexp.MustCompile(`^(?:\d{1,2}[.)]|[-*•])$`)
+
+	// answerLabelPattern "Answer: ..."、"Security answer 2 = ..."
+	answerLabelPattern = regexp.MustCompile(`(?i)^((?:security[ \t]+|secret[ \t]+)?answer(?:[ \t]*#?\d+)?|a\d?)[ \t]*[:=][ \t]*(\S.*)$`)
+	// questionPattern 以问号结尾并紧跟答案的问题，如"What was your first pet's name? Rex"
+	questionPattern = regexp.MustCompile(`(?i)^(?:(?:security[ \t]+)?question(?:[ \t]*#?\d+)?[ \t]*[:=][ \t]*|q\d?[ \t]*[:.][ \t]*)?([^?]{5,120}\?)[ \t]*(?:a\d?[ \t]*[:.][ \t]*|[:=-][ \t]*)?(\S.*)$`)
+	// questionTopicPattern 常见密保问题涉及的内容，避免把普通问句当成密保问题
+	questionTopicPattern = regexp.MustCompile(`(?i)\b(?:pet|maiden|born|birth|city|town|school|street|teacher|friend|car|mother|father|grand(?:mother|father)|nickname|hero|favou?rite|first|childhood|honeymoon|wedding|spouse|sibling)\b`)
+	// knownQuestionPattern 不带问号的常见密保问题，需用冒号或等号与答案分隔
+	// 单独的"Security Question: My first pet name"只是问题本身，不会匹配
+	knownQuestionPattern = regexp.MustCompile(`(?i)^(?:(?:security[ \t]+)?question(?:[ \t]*#?\d+)?[ \t]*[:=][ \t]*)?((?:my[ \t]+|your[ \t]+)?(?:mother'?s[ \t]+maiden[ \t]+name|maiden[ \t]+name|first[ \t]+pet'?s?[ \t]+name|name[ \t]+of[ \t]+(?:my[ \t]+|your[ \t]+)?first[ \t]+pet|first[ \t]+(?:pet|school|car|teacher|boss)|childhood[ \t]+(?:nickname|best[ \t]+friend|street|hero)|(?:city|town|place)[ \t]+of[ \t]+birth|birth[ \t]?(?:city|place)|favou?rite[ \t]+(?:teacher|book|movie|food|colou?r|sports[ \t]+team)))[ \t]*[:=][ \t]*(\S.*)$`)
+)
+
+const (
+	// minLabeledCodes 标签后至少出现的备用码个数
+	minLabeledCodes = 3
+	// minUnlabeledCodes 没有标签时，至少需要这么多个形状相同的码
+	minUnlabeledCodes = 6
+
+	// minLabeledCodeLength 标签后的码可以较短，如Google按"1234 5678"分成两组显示
+	minLabeledCodeLength = 4
+	// minUnlabeledCodeLength 没有标签时排除年份、短编号等
+	minUnlabeledCodeLength = 6
+)
+
+// Scanner 敏感信息扫描器，规则和词表在所有扫描器间共享，可以并发使用
+type Scanner struct {
+	bip39 map[string]int
+}
+
+func NewScanner() *Scanner {
+	return &Scanner{bip39: loadBIP39()}
+}
+
+// Scan 扫描文本，按出现位置返回发现
+func (s *Scanner) Scan(text string) []Finding {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+
+	var findings []Finding
+	// 同一令牌在备注中出现多次时只报告一次
+	seen := make(map[string]bool)
+	for _, rule := range tokenRules {
+		for _, match := range rule.pattern.FindAllStringSubmatchIndex(text, -1) {
+			token := text[match[2]:match[3]]
+			if seen[token] {
+				continue
+			}
+			seen[token] = true
+			findings = append(findings, Finding{
+				Category:    CategoryAPIToken,
+				Rule:        rule.rule,
+				Description: rule.description,
+				Excerpt:     mask(token, rule.keep),
+				Severity:    rule.severity,
+				offset:      match[2],
+			})
+		}
+	}
+
+	for _, match := range pemPattern.FindAllStringSubmatchIndex(text, -1) {
+		kind := strings.TrimSpace(text[match[2]:match[3]])
+		body := text[match[4]:match[5]]
+		encrypted := kind == "ENCRYPTED" || strings.Contains(body, "Proc-Type: 4,ENCRYPTED")
+		finding := Finding{
+			Category:    CategoryPrivateKey,
+			Rule:        RulePrivateKey,
+			Description: "a private key",
+			Excerpt:     text[match[0]:match[4]],
+			Severity:    types.SeverityCritical,
+			offset:      match[0],
+		}
+		if encrypted {
+			finding.Description = "an encrypted private key"
+			finding.Severity = types.SeverityMedium
+		}
+		findings = append(findings, finding)
+	}
+
+	for _, match := range findMnemonics(text, s.bip39) {
+		finding := Finding{
+			Category:    CategorySeedPhrase,
+			Rule:        RuleSeedPhrase,
+			Description: "a seed phrase",
+			Excerpt:     fmt.Sprintf("(%d words)", match.words),
+			Severity:    types.SeverityHigh,
+			offset:      match.start,
+		}
+		// 校验和通过时几乎可以确定是钱包助记词；单词数不是合法长度时可能只是碰巧连续的词表单词
+		switch {
+		case match.checksumValid:
+			finding.Description = "a valid BIP39 seed phrase"
+			finding.Severity = types.SeverityCritical
+		case !slices.Contains(bip39Lengths, match.words):
+			finding.Description = "a possible seed phrase"
+			finding.Severity = types.SeverityMedium
+		}
+		findings = append(findings, finding)
+	}
+
+	findings = append(findings, scanBackupCodes(text)...)
+	findings = append(findings, scanSecurityAnswers(text)...)
+
+	slices.SortStableFunc(findings, func(a, b Finding) int {
+		return cmp.Compare(a.offset, b.offset)
+	})
+	return findings
+}
+
+// scanBackupCodes 查找标签后紧跟的备用码，以及没有标签但连续出现的一组形状相同的码
+func scanBackupCodes(text string) []Finding {
+	var findings []Finding
+	covered := -1
+
+	for _, match := range backupKeywordPattern.FindAllStringIndex(text, -1) {
+		if match[0] < covered {
+			continue
+		}
+		codes, end := collectCodes(text[match[1]:])
+		if len(codes) < minLabeledCodes {
+			continue
+		}
+		covered = match[1] + end
+		findings = append(findings, Finding{
+			Category:    CategoryBackupCodes,
+			Rule:        RuleBackupCodes,
+			Description: "2FA backup codes",
+			Excerpt:     fmt.Sprintf("%s: %s (%d codes)", text[match[0]:match[1]], codeShape(codes[0]), len(codes)),
+			Severity:    types.SeverityHigh,
+			offset:      match[0],
+		})
+	}
+
+	// 没有标签的码：逐个字段查找形状相同的连续码
+	fields := fieldsWithOffsets(text)
+	for i := 0; i < len(fields); {
+		if fields[i].offset < covered || !isCode(fields[i].value, minUnlabeledCodeLength) {
+			i++
+			continue
+		}
+		shape := codeShape(fields[i].value)
+		j := i + 1
+		for j < len(fields) && isCode(fields[j].value, minUnlabeledCodeLength) && codeShape(fields[j].value) == shape {
+			j++
+		}
+		if j-i >= minUnlabeledCodes {
+			findings = append(findings, Finding{
+				Category:    CategoryBackupCodes,
+				Rule:        RuleBackupCodes,
+				Description: "what look like 2FA backup codes",
+				Excerpt:     fmt.Sprintf("%s (%d codes)", shape, j-i),
+				Severity:    types.SeverityHigh,
+				offset:      fields[i].offset,
+			})
+		}
+		i = j
+	}
+	return findings
+}
+
+// collectCodes 从标签之后收集连续的备用码，跳过分隔符和列表序号，返回码和结束位置
+func collectCodes(text string) ([]string, int) {
+	var codes []string
+	end := 0
+	for _, field := range fieldsWithOffsets(text) {
+		if listMarkerPattern.MatchString(field.value) {
+			continue
+		}
+		if !isCode(field.value, minLabeledCodeLength) {
+			break
+		}
+		codes = append(codes, field.value)
+		end = field.offset + len(field.value)
+	}
+	return codes, end
+}
+
+type textField struct {
+	value  string
+	offset int
+}
+
+// fieldsWithOffsets 按空白、逗号、分号和冒号切分文本
+func fieldsWithOffsets(text string) []textField {
+	var fields []textField
+	start := -1
+	for i, r := range text + " " {
+		separator := r == ',' || r == ';' || r == ':' || r == '|' || strings.ContainsRune(" \t\r\n", r)
+		if !separator {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			fields = append(fields, textField{value: text[start:i], offset: start})
+			start = -1
+		}
+	}
+	return fields
+}
+
+// isCode 形如备用码且至少包含一个数字，排除普通单词
+func isCode(field string, minLength int) bool {
+	return len(field) >= minLength && codePattern.MatchString(field) && strings.ContainsAny(field, "0123456789")
+}
+
+// codeShape 码的形状：每个字母数字替换为星号，保留连字符，如"****-****"
+func codeShape(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' {
+			return r
+		}
+		return '*'
+	}, code)
+}
+
+// scanSecurityAnswers 逐行（以及各提供商合并字段时使用的分号）查找密保问题的答案
+func scanSecurityAnswers(text string) []Finding {
+	var findings []Finding
+	offset := 0
+	for _, line := range strings.FieldsFunc(text, func(r rune) bool { return r == '\n' || r == ';' }) {
+		lineOffset := strings.Index(text[offset:], line) + offset
+		offset = lineOffset + len(line)
+
+		trimmed := strings.TrimSpace(line)
+		var label string
+		switch {
+		case answerLabelPattern.MatchString(trimmed):
+			label = answerLabelPattern.FindStringSubmatch(trimmed)[1]
+		case knownQuestionPattern.MatchString(trimmed):
+			label = knownQuestionPattern.FindStringSubmatch(trimmed)[1]
+		case questionPattern.MatchString(trimmed):
+			question := strings.TrimSpace(questionPattern.FindStringSubmatch(trimmed)[1])
+			if !strings.ContainsAny(question, " \t") || !questionTopicPattern.MatchString(question) {
+				continue
+			}
+			label = question
+		default:
+			continue
+		}
+
+		label = strings.TrimSpace(label)
+		if !strings.HasSuffix(label, "?") {
+			label += ":"
+		}
+		findings = append(findings, Finding{
+			Category:    CategorySecurityAnswer,
+			Rule:        RuleSecurityAnswer,
+			Description: "a security question answer",
+			Excerpt:     fmt.Sprintf("%s %s", label, redactedAnswer),
+			Severity:    types.SeverityMedium,
+			offset:      lineOffset,
+		})
+	}
+	return findings
+}
+
+// mask 保留前keep个字符，其余替换为固定个数的星号
+func mask(value string, keep int) string {
+	if keep > len(value) {
+		keep = len(value)
+	}
+	return value[:keep] + strings.Repeat("*", maskLength)
+}
@@ -0,0 +1,71 @@
+package strength
+
+import (
+	_ "embed"
+	"strings"
+	"sync"
+)
+
+// 内置词表按使用频率从高到低排列，行号即排名
+var (
+	//go:embed data/passwords.txt
+	passwordsList string
+	//go:embed data/english.txt
+	englishList string
+	//go:embed data/names.txt
+	namesList string
+)
+
+// 字典名称，出现在匹配结果的Dictionary字段
+const (
+	DictionaryPasswords  = "passwords"
+	DictionaryEnglish    = "english"
+	DictionaryNames      = "names"
+	DictionaryUserInputs = "user_inputs"
+)
+
+// rankedDictionary 单词到排名（从1开始）的映射
+type rankedDictionary struct {
+	name      string
+	ranks     map[string]int
+	maxLength int
+}
+
+var (
+	builtinOnce         sync.Once
+	builtinDictionaries []*rankedDictionary
+)
+
+// loadBuiltinDictionaries 首次使用时解析内置词表，之后共享只读的结果
+func loadBuiltinDictionaries() []*rankedDictionary {
+	builtinOnce.Do(func() {
+		builtinDictionaries = []*rankedDictionary{
+			newRankedDictionary(DictionaryPasswords, strings.Split(passwordsList, "\n")),
+			newRankedDictionary(DictionaryEnglish, strings.Split(englishList, "\n")),
+			newRankedDictionary(DictionaryNames, strings.Split(namesList, "\n")),
+		}
+	})
+	return builtinDictionaries
+}
+
+// newRankedDictionary 按顺序编号，重复的单词保留最靠前的排名
+func newRankedDictionary(name string, words []string) *rankedDictionary {
+	dictionary := &rankedDictionary{
+		name:  name,
+		ranks: make(map[string]int, len(words)),
+	}
+	rank := 0
+	for _, word := range words {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word == "" {
+			continue
+		}
+		rank++
+		if _, exists := dictionary.ranks[word]; exists {
+			continue
+		}
+		dictionary.ranks[word] = rank
+		dictionary.maxLength = max(dictionary.maxLength, len([]rune(word)))
+	}
+	return dictionary
+}
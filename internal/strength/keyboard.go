@@ -0,0 +1,105 @@
+package strength
+
+import "strings"
+
+// 键盘布局，每个键写作"未按Shift的字符+按Shift的字符"，小键盘每个键一个字符
+const (
+	qwertyLayout = "`~ 1! 2@ 3# 4$ 5% 6^ 7& 8* 9( 0) -_ =+\n" +
+		"    qQ wW eE rR tT yY uU iI oO pP [{ ]} \\|\n" +
+		"     aA sS dD fF gG hH jJ kK lL ;: '\"\n" +
+		"      zZ xX cC vV bB nN mM ,< .> /?"
+
+	keypadLayout = "  / * -\n" +
+		"7 8 9 +\n" +
+		"4 5 6\n" +
+		"1 2 3\n" +
+		"  0 ."
+)
+
+// keyboardGraph 键盘上每个字符的相邻键，按方向排列，没有相邻键的方向为空串
+type keyboardGraph struct {
+	name              string
+	adjacency         map[rune][]string
+	shifted           map[rune]bool // 需要按Shift输入的字符
+	startingPositions float64
+	averageDegree     float64
+}
+
+var keyboardGraphs = []*keyboardGraph{
+	newKeyboardGraph("qwerty", qwertyLayout, true),
+	newKeyboardGraph("keypad", keypadLayout, false),
+}
+
+type keyPosition struct{ x, y int }
+
+// newKeyboardGraph 由布局文本计算相邻关系
+// 主键盘逐行错开（slanted），每个键有6个方向的相邻键；小键盘对齐排列，有8个方向
+func newKeyboardGraph(name, layout string, slanted bool) *keyboardGraph {
+	positions := make(map[keyPosition]string)
+	var order []keyPosition
+	tokenSize := 0
+
+	for y, line := range strings.Split(layout, "\n") {
+		// 错开的布局每行多缩进一格
+		slant := 0
+		if slanted {
+			slant = y
+		}
+		for offset := 0; offset < len(line); {
+			if line[offset] == ' ' {
+				offset++
+				continue
+			}
+			end := strings.IndexByte(line[offset:], ' ')
+			if end < 0 {
+				end = len(line) - offset
+			}
+			token := line[offset : offset+end]
+			tokenSize = len(token)
+			position := keyPosition{x: (offset - slant) / (tokenSize + 1), y: y}
+			positions[position] = token
+			order = append(order, position)
+			offset += end
+		}
+	}
+
+	graph := &keyboardGraph{
+		name:      name,
+		adjacency: make(map[rune][]string),
+		shifted:   make(map[rune]bool),
+	}
+	degree := 0
+	for _, position := range order {
+		var neighbours []string
+		for _, neighbour := range adjacentPositions(position, slanted) {
+			token := positions[neighbour]
+			if token != "" {
+				degree++
+			}
+			neighbours = append(neighbours, token)
+		}
+		for index, char := range []rune(positions[position]) {
+			graph.adjacency[char] = neighbours
+			if index == 1 {
+				graph.shifted[char] = true
+			}
+		}
+	}
+	graph.startingPositions = float64(len(order))
+	graph.averageDegree = float64(degree) / float64(len(order))
+	return graph
+}
+
+// adjacentPositions 按顺时针方向列出相邻位置，方向的序号用于统计转向次数
+func adjacentPositions(p keyPosition, slanted bool) []keyPosition {
+	if slanted {
+		return []keyPosition{
+			{p.x - 1, p.y}, {p.x, p.y - 1}, {p.x + 1, p.y - 1},
+			{p.x + 1, p.y}, {p.x, p.y + 1}, {p.x - 1, p.y + 1},
+		}
+	}
+	return []keyPosition{
+		{p.x - 1, p.y}, {p.x - 1, p.y - 1}, {p.x, p.y - 1}, {p.x + 1, p.y - 1},
+		{p.x + 1, p.y}, {p.x + 1, p.y + 1}, {p.x, p.y + 1}, {p.x - 1, p.y + 1},
+	}
+}
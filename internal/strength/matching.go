@@ -0,0 +1,517 @@
+package strength
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// l33tTable 常见的替代字符及其可能代表的字母
+var l33tTable = map[rune][]rune{
+	'4': {'a'}, '@': {'a'},
+	'8': {'b'},
+	'(': {'c'}, '{': {'c'}, '[': {'c'}, '<': {'c'},
+	'3': {'e'},
+	'6': {'g'}, '9': {'g'},
+	'1': {'i', 'l'}, '!': {'i'}, '|': {'i', 'l'},
+	'7': {'l', 't'},
+	'0': {'o'},
+	'$': {'s'}, '5': {'s'},
+	'+': {'t'},
+	'%': {'x'},
+	'2': {'z'},
+}
+
+// 日期中年份的合理范围
+const (
+	dateMinYear = 1000
+	dateMaxYear = 2050
+)
+
+// dateSplits 无分隔符的数字串按长度拆分为三段的位置
+var dateSplits = map[int][][2]int{
+	4: {{1, 2}, {2, 3}},
+	5: {{1, 3}, {2, 3}},
+	6: {{1, 2}, {2, 4}, {4, 5}},
+	7: {{1, 3}, {2, 3}, {4, 5}, {4, 6}},
+	8: {{2, 4}, {4, 6}},
+}
+
+// omnimatch 找出密码中所有可能的模式片段，片段之间可以重叠
+func (e *Estimator) omnimatch(password []rune, dictionaries []*rankedDictionary) []*Match {
+	var matches []*Match
+	matches = append(matches, dictionaryMatches(password, dictionaries)...)
+	matches = append(matches, reverseDictionaryMatches(password, dictionaries)...)
+	matches = append(matches, l33tMatches(password, dictionaries)...)
+	matches = append(matches, spatialMatches(password)...)
+	matches = append(matches, e.repeatMatches(password, dictionaries)...)
+	matches = append(matches, sequenceMatches(password)...)
+	matches = append(matches, yearMatches(password)...)
+	matches = append(matches, dateMatches(password, e.referenceYear)...)
+
+	slices.SortStableFunc(matches, func(a, b *Match) int {
+		if a.I != b.I {
+			return a.I - b.I
+		}
+		return a.J - b.J
+	})
+	return matches
+}
+
+// dictionaryMatches 不区分大小写地查找字典中的单词
+func dictionaryMatches(password []rune, dictionaries []*rankedDictionary) []*Match {
+	lower := []rune(strings.ToLower(string(password)))
+	if len(lower) != len(password) {
+		// 个别字符小写后长度变化时逐个转换，保持下标对齐
+		lower = make([]rune, len(password))
+		for i, r := range password {
+			lower[i] = unicode.ToLower(r)
+		}
+	}
+	return lookupWords(password, lower, dictionaries)
+}
+
+// lookupWords 在translated中查找单词，Token取自original中相同位置的字符
+func lookupWords(original, translated []rune, dictionaries []*rankedDictionary) []*Match {
+	var matches []*Match
+	for _, dictionary := range dictionaries {
+		for i := range translated {
+			for j := i; j < len(translated) && j-i < dictionary.maxLength; j++ {
+				word := string(translated[i : j+1])
+				rank, exists := dictionary.ranks[word]
+				if !exists {
+					continue
+				}
+				matches = append(matches, &Match{
+					Pattern:    PatternDictionary,
+					I:          i,
+					J:          j,
+					Token:      string(original[i : j+1]),
+					Dictionary: dictionary.name,
+					Word:       word,
+					Rank:       rank,
+				})
+			}
+		}
+	}
+	return matches
+}
+
+// reverseDictionaryMatches 查找倒写的单词，如drowssap
+func reverseDictionaryMatches(password []rune, dictionaries []*rankedDictionary) []*Match {
+	reversed := slices.Clone(password)
+	slices.Reverse(reversed)
+
+	matches := dictionaryMatches(reversed, dictionaries)
+	for _, match := range matches {
+		match.I, match.J = len(password)-1-match.J, len(password)-1-match.I
+		match.Token = string(password[match.I : match.J+1])
+		match.Reversed = true
+	}
+	return matches
+}
+
+// l33tMatches 还原字母替代后查找单词，如p@ssw0rd
+// 一个替代字符可能代表多个字母（如1代表i或l），逐一尝试每种还原方式
+func l33tMatches(password []rune, dictionaries []*rankedDictionary) []*Match {
+	var chars []rune
+	for _, r := range password {
+		if _, exists := l33tTable[r]; exists && !slices.Contains(chars, r) {
+			chars = append(chars, r)
+		}
+	}
+	if len(chars) == 0 {
+		return nil
+	}
+
+	var matches []*Match
+	seen := make(map[string]bool)
+	sub := make(map[rune]rune, len(chars))
+
+	var enumerate func(k int)
+	enumerate = func(k int) {
+		if k < len(chars) {
+			for _, letter := range l33tTable[chars[k]] {
+				sub[chars[k]] = letter
+				enumerate(k + 1)
+			}
+			return
+		}
+
+		translated := make([]rune, len(password))
+		for i, r := range password {
+			if letter, exists := sub[r]; exists {
+				translated[i] = letter
+			} else {
+				translated[i] = unicode.ToLower(r)
+			}
+		}
+		for _, match := range lookupWords(password, translated, dictionaries) {
+			// 单个字符的替代没有意义
+			if match.J == match.I {
+				continue
+			}
+			used := make(map[rune]rune)
+			for _, r := range password[match.I : match.J+1] {
+				if letter, exists := sub[r]; exists {
+					used[r] = letter
+				}
+			}
+			if len(used) == 0 {
+				continue
+			}
+
+			key := fmt.Sprintf("%d:%d:%s:%s", match.I, match.J, match.Dictionary, match.Word)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			match.L33t = true
+			match.Sub = used
+			matches = append(matches, match)
+		}
+	}
+	enumerate(0)
+
+	return matches
+}
+
+// spatialMatches 查找键盘上连续相邻的按键，至少3个字符
+func spatialMatches(password []rune) []*Match {
+	var matches []*Match
+	for _, graph := range keyboardGraphs {
+		matches = append(matches, spatialMatchesInGraph(password, graph)...)
+	}
+	return matches
+}
+
+func spatialMatchesInGraph(password []rune, graph *keyboardGraph) []*Match {
+	var matches []*Match
+
+	for i := 0; i < len(password)-1; {
+		j := i + 1
+		lastDirection := -1
+		turns := 0
+		shifted := 0
+		if graph.shifted[password[i]] {
+			shifted++
+		}
+
+		for {
+			found := false
+			if j < len(password) {
+				current := string(password[j])
+				for direction, neighbour := range graph.adjacency[password[j-1]] {
+					index := strings.Index(neighbour, current)
+					if neighbour == "" || index < 0 {
+						continue
+					}
+					found = true
+					// 邻键的第二个字符需要按Shift
+					if index == 1 {
+						shifted++
+					}
+					if direction != lastDirection {
+						turns++
+						lastDirection = direction
+					}
+					break
+				}
+			}
+
+			if found {
+				j++
+				continue
+			}
+			if j-i > 2 {
+				matches = append(matches, &Match{
+					Pattern:      PatternSpatial,
+					I:            i,
+					J:            j - 1,
+					Token:        string(password[i:j]),
+					Graph:        graph.name,
+					Turns:        turns,
+					ShiftedCount: shifted,
+				})
+			}
+			i = j
+			break
+		}
+	}
+
+	return matches
+}
+
+// repeatMatches 查找连续重复的片段，取覆盖最长的重复单元（相同长度时取较短的单元）
+func (e *Estimator) repeatMatches(password []rune, dictionaries []*rankedDictionary) []*Match {
+	var matches []*Match
+
+	for i := 0; i < len(password); {
+		bestLength, bestCount := 0, 0
+		for length := 1; i+2*length <= len(password); length++ {
+			base := password[i : i+length]
+			count := 1
+			for start := i + length; start+length <= len(password) && slices.Equal(password[start:start+length], base); start += length {
+				count++
+			}
+			if count >= 2 && count*length > bestCount*bestLength {
+				bestLength, bestCount = length, count
+			}
+		}
+		if bestCount < 2 {
+			i++
+			continue
+		}
+
+		// 重复单元本身按同样的方法估算
+		base := password[i : i+bestLength]
+		baseGuesses, _ := e.mostGuessableSequence(base, e.omnimatch(base, dictionaries))
+		end := i + bestLength*bestCount
+		matches = append(matches, &Match{
+			Pattern:     PatternRepeat,
+			I:           i,
+			J:           end - 1,
+			Token:       string(password[i:end]),
+			BaseGuesses: baseGuesses,
+			RepeatCount: bestCount,
+		})
+		i = end
+	}
+
+	return matches
+}
+
+// sequenceMatches 查找字符编码等差（公差1~5）的序列，至少3个字符
+func sequenceMatches(password []rune) []*Match {
+	const maxDelta = 5
+	var matches []*Match
+
+	for i := 0; i+2 < len(password); {
+		delta := int(password[i+1]) - int(password[i])
+		if delta == 0 || delta > maxDelta || delta < -maxDelta {
+			i++
+			continue
+		}
+		j := i + 1
+		for j+1 < len(password) && int(password[j+1])-int(password[j]) == delta {
+			j++
+		}
+		if j-i < 2 {
+			i++
+			continue
+		}
+		matches = append(matches, &Match{
+			Pattern:   PatternSequence,
+			I:         i,
+			J:         j,
+			Token:     string(password[i : j+1]),
+			Ascending: delta > 0,
+		})
+		i = j
+	}
+
+	return matches
+}
+
+// yearMatches 查找19xx、20xx形式的年份
+func yearMatches(password []rune) []*Match {
+	var matches []*Match
+	for i := 0; i+4 <= len(password); i++ {
+		token := string(password[i : i+4])
+		if !isDigits(password[i:i+4]) || (!strings.HasPrefix(token, "19") && !strings.HasPrefix(token, "20")) {
+			continue
+		}
+		year, _ := strconv.Atoi(token)
+		matches = append(matches, &Match{
+			Pattern: PatternYear,
+			I:       i,
+			J:       i + 3,
+			Token:   token,
+			Year:    year,
+		})
+	}
+	return matches
+}
+
+// dateMatches 查找日期，如1991-03-15、15.3.91、19910315
+// 被更长的日期包含的日期片段不单独保留
+func dateMatches(password []rune, referenceYear int) []*Match {
+	var matches []*Match
+
+	// 无分隔符：4~8位数字，多种拆分方式中取年份最接近参考年份的一种
+	for i := 0; i+4 <= len(password); i++ {
+		for j := i + 3; j < i+8 && j < len(password); j++ {
+			token := password[i : j+1]
+			if !isDigits(token) {
+				continue
+			}
+			var best *Match
+			for _, split := range dateSplits[len(token)] {
+				ints := [3]int{
+					atoi(token[:split[0]]),
+					atoi(token[split[0]:split[1]]),
+					atoi(token[split[1]:]),
+				}
+				year, month, day, ok := mapIntsToDate(ints)
+				if !ok {
+					continue
+				}
+				if best == nil || abs(year-referenceYear) < abs(best.Year-referenceYear) {
+					best = &Match{Year: year, Month: month, Day: day}
+				}
+			}
+			if best != nil {
+				best.Pattern, best.I, best.J, best.Token = PatternDate, i, j, string(token)
+				matches = append(matches, best)
+			}
+		}
+	}
+
+	// 有分隔符：两个分隔符必须相同
+	for i := 0; i+6 <= len(password); i++ {
+		for j := i + 5; j < i+10 && j < len(password); j++ {
+			year, month, day, separator, ok := parseSeparatedDate(password[i : j+1])
+			if !ok {
+				continue
+			}
+			matches = append(matches, &Match{
+				Pattern:   PatternDate,
+				I:         i,
+				J:         j,
+				Token:     string(password[i : j+1]),
+				Year:      year,
+				Month:     month,
+				Day:       day,
+				Separator: separator,
+			})
+		}
+	}
+
+	return slices.DeleteFunc(matches, func(match *Match) bool {
+		for _, other := range matches {
+			if other != match && other.I <= match.I && other.J >= match.J && other.J-other.I > match.J-match.I {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// parseSeparatedDate 解析"数字 分隔符 数字 分隔符 数字"形式的日期
+func parseSeparatedDate(token []rune) (year, month, day int, separator string, ok bool) {
+	isSeparator := func(r rune) bool { return strings.ContainsRune(" /\\_.-", r) }
+
+	var parts [3][]rune
+	var separators []rune
+	part := 0
+	for _, r := range token {
+		switch {
+		case unicode.IsDigit(r) && r < unicode.MaxASCII:
+			parts[part] = append(parts[part], r)
+		case isSeparator(r) && part < 2 && len(parts[part]) > 0:
+			separators = append(separators, r)
+			part++
+		default:
+			return 0, 0, 0, "", false
+		}
+	}
+	if part != 2 || separators[0] != separators[1] ||
+		len(parts[0]) > 4 || len(parts[1]) > 2 || len(parts[2]) == 0 || len(parts[2]) > 4 {
+		return 0, 0, 0, "", false
+	}
+
+	year, month, day, ok = mapIntsToDate([3]int{atoi(parts[0]), atoi(parts[1]), atoi(parts[2])})
+	return year, month, day, string(separators[0]), ok
+}
+
+// mapIntsToDate 判断三个整数能否组成日期，年份在首或尾，两位年份补全为19xx或20xx
+func mapIntsToDate(ints [3]int) (year, month, day int, ok bool) {
+	// 中间的数字只能是月或日
+	if ints[1] > 31 || ints[1] <= 0 {
+		return 0, 0, 0, false
+	}
+	over12, over31, under1 := 0, 0, 0
+	for _, value := range ints {
+		if (value > 99 && value < dateMinYear) || value > dateMaxYear {
+			return 0, 0, 0, false
+		}
+		if value > 31 {
+			over31++
+		}
+		if value > 12 {
+			over12++
+		}
+		if value <= 0 {
+			under1++
+		}
+	}
+	if over31 >= 2 || over12 == 3 || under1 >= 2 {
+		return 0, 0, 0, false
+	}
+
+	candidates := [][3]int{
+		{ints[2], ints[0], ints[1]},
+		{ints[0], ints[1], ints[2]},
+	}
+	// 先尝试四位年份
+	for _, candidate := range candidates {
+		if candidate[0] >= dateMinYear && candidate[0] <= dateMaxYear {
+			if month, day, ok := mapIntsToMonthDay(candidate[1], candidate[2]); ok {
+				return candidate[0], month, day, true
+			}
+			// 四位年份的位置确定，剩余两个数不能组成月日时不再尝试
+			return 0, 0, 0, false
+		}
+	}
+	for _, candidate := range candidates {
+		if month, day, ok := mapIntsToMonthDay(candidate[1], candidate[2]); ok {
+			return twoToFourDigitYear(candidate[0]), month, day, true
+		}
+	}
+	return 0, 0, 0, false
+}
+
+func mapIntsToMonthDay(a, b int) (month, day int, ok bool) {
+	for _, pair := range [][2]int{{a, b}, {b, a}} {
+		day, month := pair[0], pair[1]
+		if day >= 1 && day <= 31 && month >= 1 && month <= 12 {
+			return month, day, true
+		}
+	}
+	return 0, 0, false
+}
+
+func twoToFourDigitYear(year int) int {
+	switch {
+	case year > 99:
+		return year
+	case year > 50:
+		return year + 1900
+	default:
+		return year + 2000
+	}
+}
+
+func isDigits(runes []rune) bool {
+	for _, r := range runes {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return len(runes) > 0
+}
+
+func atoi(runes []rune) int {
+	value, _ := strconv.Atoi(string(runes))
+	return value
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
@@ -0,0 +1,311 @@
+package strength
+
+import (
+	"math"
+	"unicode"
+)
+
+const (
+	// 非整个密码的片段至少需要的猜测次数，避免短片段被低估
+	minSubmatchGuessesSingleChar = 10
+	minSubmatchGuessesMultiChar  = 50
+	// minYearSpace 年份与参考年份相差很近时仍按20年的范围估算
+	minYearSpace = 20
+	// bruteforceCardinality 未匹配字符按每位10种可能估算
+	bruteforceCardinality = 10
+	// minGuessesBeforeGrowingSequence 每多拆出一个片段附加的猜测次数，倾向于较少的片段
+	minGuessesBeforeGrowingSequence = 10000
+)
+
+// mostGuessableSequence 用动态规划找出总猜测次数最少的不重叠拆分方式，未被覆盖的字符按穷举计算
+// 拆成l个片段的猜测次数为 l! × ∏片段猜测次数 + 10000^(l-1)
+func (e *Estimator) mostGuessableSequence(password []rune, matches []*Match) (float64, []*Match) {
+	n := len(password)
+	if n == 0 {
+		return 1, nil
+	}
+
+	matchesByEnd := make([][]*Match, n)
+	for _, match := range matches {
+		matchesByEnd[match.J] = append(matchesByEnd[match.J], match)
+	}
+
+	// optimal*[k][l] 覆盖前k+1个字符、最后一个片段为第l个时的最优解
+	optimalMatch := make([]map[int]*Match, n)
+	optimalProduct := make([]map[int]float64, n)
+	optimalGuesses := make([]map[int]float64, n)
+	for k := range n {
+		optimalMatch[k] = make(map[int]*Match)
+		optimalProduct[k] = make(map[int]float64)
+		optimalGuesses[k] = make(map[int]float64)
+	}
+
+	update := func(match *Match, l int) {
+		k := match.J
+		product := e.estimateGuesses(match, n)
+		if l > 1 {
+			product *= optimalProduct[match.I-1][l-1]
+		}
+		guesses := factorial(l)*product + math.Pow(minGuessesBeforeGrowingSequence, float64(l-1))
+
+		// 片段更少且猜测次数不更多的解已经存在时丢弃
+		for competingL, competingGuesses := range optimalGuesses[k] {
+			if competingL <= l && competingGuesses <= guesses {
+				return
+			}
+		}
+		optimalMatch[k][l] = match
+		optimalProduct[k][l] = product
+		optimalGuesses[k][l] = guesses
+	}
+
+	// 以k结尾的穷举片段，只接在非穷举片段之后，相邻的穷举片段合并为一个
+	bruteforceUpdate := func(k int) {
+		update(bruteforceMatch(password, 0, k), 1)
+		for i := 1; i <= k; i++ {
+			for l, last := range optimalMatch[i-1] {
+				if last.Pattern == PatternBruteforce {
+					continue
+				}
+				update(bruteforceMatch(password, i, k), l+1)
+			}
+		}
+	}
+
+	for k := range n {
+		for _, match := range matchesByEnd[k] {
+			if match.I > 0 {
+				for l := range optimalMatch[match.I-1] {
+					update(match, l+1)
+				}
+			} else {
+				update(match, 1)
+			}
+		}
+		bruteforceUpdate(k)
+	}
+
+	// 取最后一个字符处猜测次数最少的解，相同时取片段较少的
+	bestL := 0
+	bestGuesses := math.Inf(1)
+	for l, guesses := range optimalGuesses[n-1] {
+		if guesses < bestGuesses || (guesses == bestGuesses && l < bestL) {
+			bestL, bestGuesses = l, guesses
+		}
+	}
+
+	sequence := make([]*Match, bestL)
+	for k, l := n-1, bestL; k >= 0; l-- {
+		match := optimalMatch[k][l]
+		sequence[l-1] = match
+		k = match.I - 1
+	}
+
+	return bestGuesses, sequence
+}
+
+func bruteforceMatch(password []rune, i, j int) *Match {
+	return &Match{
+		Pattern: PatternBruteforce,
+		I:       i,
+		J:       j,
+		Token:   string(password[i : j+1]),
+	}
+}
+
+// estimateGuesses 计算单个片段的猜测次数，结果缓存在Guesses中
+func (e *Estimator) estimateGuesses(match *Match, passwordLength int) float64 {
+	if match.Guesses > 0 {
+		return match.Guesses
+	}
+
+	length := match.J - match.I + 1
+	minGuesses := 1.0
+	if length < passwordLength {
+		if length == 1 {
+			minGuesses = minSubmatchGuessesSingleChar
+		} else {
+			minGuesses = minSubmatchGuessesMultiChar
+		}
+	}
+
+	var guesses float64
+	switch match.Pattern {
+	case PatternBruteforce:
+		guesses = bruteforceGuesses(length)
+	case PatternDictionary:
+		guesses = dictionaryGuesses(match)
+	case PatternSpatial:
+		guesses = spatialGuesses(match)
+	case PatternRepeat:
+		guesses = match.BaseGuesses * float64(match.RepeatCount)
+	case PatternSequence:
+		guesses = sequenceGuesses(match)
+	case PatternYear:
+		guesses = float64(max(abs(match.Year-e.referenceYear), minYearSpace))
+	case PatternDate:
+		guesses = float64(max(abs(match.Year-e.referenceYear), minYearSpace)) * 365
+		if match.Separator != "" {
+			guesses *= 4
+		}
+	}
+
+	match.Guesses = math.Max(guesses, minGuesses)
+	return match.Guesses
+}
+
+func bruteforceGuesses(length int) float64 {
+	guesses := math.Pow(bruteforceCardinality, float64(length))
+	if math.IsInf(guesses, 1) {
+		guesses = math.MaxFloat64
+	}
+	// 穷举片段略多于同长度的其他模式，使其他模式在相同条件下优先
+	if length == 1 {
+		return math.Max(guesses, minSubmatchGuessesSingleChar+1)
+	}
+	return math.Max(guesses, minSubmatchGuessesMultiChar+1)
+}
+
+// dictionaryGuesses 单词排名乘以大小写、字母替代和倒写带来的变化
+func dictionaryGuesses(match *Match) float64 {
+	token := []rune(match.Token)
+	guesses := float64(match.Rank) * uppercaseVariations(token)
+	if match.L33t {
+		guesses *= l33tVariations(match, token)
+	}
+	if match.Reversed {
+		guesses *= 2
+	}
+	return guesses
+}
+
+// uppercaseVariations 全小写不增加猜测次数；首字母、末字母或全部大写只需多猜一倍；其余按大写字母位置的组合数计算
+func uppercaseVariations(token []rune) float64 {
+	upper, lower := 0, 0
+	for _, r := range token {
+		switch {
+		case unicode.IsUpper(r):
+			upper++
+		case unicode.IsLower(r):
+			lower++
+		}
+	}
+	if upper == 0 {
+		return 1
+	}
+	if lower == 0 {
+		return 2
+	}
+	first, last := unicode.IsUpper(token[0]), unicode.IsUpper(token[len(token)-1])
+	if upper == 1 && (first || last) {
+		return 2
+	}
+
+	variations := 0.0
+	for i := 1; i <= min(upper, lower); i++ {
+		variations += binomial(upper+lower, i)
+	}
+	return variations
+}
+
+// l33tVariations 每个被替代的字母按替代位置的组合数计算，全部替代时只需多猜一倍
+func l33tVariations(match *Match, token []rune) float64 {
+	variations := 1.0
+	for substitute, letter := range match.Sub {
+		substituted, unsubstituted := 0, 0
+		for _, r := range token {
+			switch unicode.ToLower(r) {
+			case substitute:
+				substituted++
+			case letter:
+				unsubstituted++
+			}
+		}
+		if substituted == 0 || unsubstituted == 0 {
+			variations *= 2
+			continue
+		}
+		possibilities := 0.0
+		for i := 1; i <= min(substituted, unsubstituted); i++ {
+			possibilities += binomial(substituted+unsubstituted, i)
+		}
+		variations *= possibilities
+	}
+	return variations
+}
+
+// spatialGuesses 按路径长度、转向次数和键盘的平均相邻键数估算
+func spatialGuesses(match *Match) float64 {
+	var graph *keyboardGraph
+	for _, candidate := range keyboardGraphs {
+		if candidate.name == match.Graph {
+			graph = candidate
+		}
+	}
+
+	length := match.J - match.I + 1
+	guesses := 0.0
+	for i := 2; i <= length; i++ {
+		for j := 1; j <= min(match.Turns, i-1); j++ {
+			guesses += binomial(i-1, j-1) * graph.startingPositions * math.Pow(graph.averageDegree, float64(j))
+		}
+	}
+
+	if shifted := match.ShiftedCount; shifted > 0 {
+		unshifted := length - shifted
+		if unshifted == 0 {
+			guesses *= 2
+		} else {
+			variations := 0.0
+			for i := 1; i <= min(shifted, unshifted); i++ {
+				variations += binomial(shifted+unshifted, i)
+			}
+			guesses *= variations
+		}
+	}
+	return guesses
+}
+
+// sequenceGuesses 从常见起点（a、z、0、1、9）开始的序列更容易被猜到，倒序的序列多猜一倍
+func sequenceGuesses(match *Match) float64 {
+	token := []rune(match.Token)
+	first := token[0]
+
+	var base float64
+	switch {
+	case first == 'a' || first == 'A' || first == 'z' || first == 'Z' || first == '0' || first == '1' || first == '9':
+		base = 4
+	case first >= '0' && first <= '9':
+		base = 10
+	default:
+		base = 26
+	}
+	if !match.Ascending {
+		base *= 2
+	}
+	return base * float64(len(token))
+}
+
+func binomial(n, k int) float64 {
+	if k > n {
+		return 0
+	}
+	if k == 0 {
+		return 1
+	}
+	result := 1.0
+	for d := 1; d <= k; d++ {
+		result *= float64(n)
+		result /= float64(d)
+		n--
+	}
+	return result
+}
+
+func factorial(n int) float64 {
+	result := 1.0
+	for i := 2; i <= n; i++ {
+		result *= float64(i)
+	}
+	return result
+}
@@ -0,0 +1,224 @@
+// Package strength 参照zxcvbn的思路估算密码强度
+// 密码被拆成字典单词、键盘路径、重复、序列、日期等模式的组合，
+// 取猜测次数最少的拆分方式作为攻击者需要尝试的次数
+package strength
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultGuessesPerSecond 破解时间按离线攻击慢哈希（bcrypt、PBKDF2等）每秒一万次估算
+const DefaultGuessesPerSecond = 1e4
+
+// maxPasswordLength 超出部分不参与匹配，避免超长输入的二次方开销；这样长的密码已远超最高评分
+const maxPasswordLength = 100
+
+// Pattern 匹配到的密码模式
+type Pattern string
+
+const (
+	PatternDictionary Pattern = "dictionary"
+	PatternSpatial    Pattern = "spatial"  // 键盘上相邻键组成的路径，如qwerty、zxcvbn
+	PatternRepeat     Pattern = "repeat"   // 重复的字符或片段，如aaa、abcabc
+	PatternSequence   Pattern = "sequence" // 等差的字符序列，如abcd、9753
+	PatternDate       Pattern = "date"
+	PatternYear       Pattern = "year"
+	PatternBruteforce Pattern = "bruteforce" // 未匹配任何模式，按穷举估算
+)
+
+// Match 密码中匹配到某种模式的片段，I、J为首尾字符（rune）的下标
+type Match struct {
+	Pattern Pattern
+	I, J    int
+	Token   string
+	Guesses float64
+
+	// 字典匹配
+	Dictionary string
+	Word       string // 还原大小写和字母替代后的单词
+	Rank       int
+	Reversed   bool
+	L33t       bool
+	Sub        map[rune]rune // 替代字符到原字母
+
+	// 键盘路径
+	Graph        string
+	Turns        int
+	ShiftedCount int
+
+	// 重复
+	BaseGuesses float64
+	RepeatCount int
+
+	// 序列
+	Ascending bool
+
+	// 日期和年份
+	Year      int
+	Month     int
+	Day       int
+	Separator string
+}
+
+// Result 密码的强度估算结果
+type Result struct {
+	Guesses          float64
+	GuessesLog10     float64
+	Score            int // 0~4，猜测次数分别低于10^3、10^6、10^8、10^10时为0~3
+	CrackTimeSeconds float64
+	Sequence         []*Match // 猜测次数最少的拆分方式
+}
+
+// Patterns 结果中出现的模式名称（不含穷举），按字母排序
+// 字典匹配按来源细分：user_input表示包含凭据自身的标题、用户名或域名，l33t表示使用了字母替代
+func (r Result) Patterns() []string {
+	// 没有可识别的模式时返回空切片，JSON报告中为[]而不是null
+	patterns := []string{}
+	add := func(pattern string) {
+		if !slices.Contains(patterns, pattern) {
+			patterns = append(patterns, pattern)
+		}
+	}
+	for _, match := range r.Sequence {
+		switch match.Pattern {
+		case PatternBruteforce:
+			continue
+		case PatternDictionary:
+			if match.Dictionary == DictionaryUserInputs {
+				add("user_input")
+			} else {
+				add(string(PatternDictionary))
+			}
+			if match.L33t {
+				add("l33t")
+			}
+		default:
+			add(string(match.Pattern))
+		}
+	}
+	slices.Sort(patterns)
+	return patterns
+}
+
+// ContainsUserInput 密码是否包含估算时提供的用户信息
+func (r Result) ContainsUserInput() bool {
+	for _, match := range r.Sequence {
+		if match.Pattern == PatternDictionary && match.Dictionary == DictionaryUserInputs {
+			return true
+		}
+	}
+	return false
+}
+
+// Estimator 密码强度估算器，内置词表在所有估算器间共享，可以并发使用
+type Estimator struct {
+	dictionaries     []*rankedDictionary
+	guessesPerSecond float64
+	referenceYear    int
+}
+
+func NewEstimator() *Estimator {
+	return &Estimator{
+		dictionaries:     loadBuiltinDictionaries(),
+		guessesPerSecond: DefaultGuessesPerSecond,
+		referenceYear:    time.Now().Year(),
+	}
+}
+
+// SetGuessesPerSecond 设置估算破解时间时攻击者每秒的猜测次数
+func (e *Estimator) SetGuessesPerSecond(rate float64) {
+	if rate > 0 {
+		e.guessesPerSecond = rate
+	}
+}
+
+// Estimate 估算密码的猜测次数和破解时间
+// userInputs 为与凭据相关的信息（标题、用户名、域名等），作为排名最靠前的字典，包含它们的密码会被大幅降低评分
+func (e *Estimator) Estimate(password string, userInputs []string) Result {
+	runes := []rune(password)
+	if len(runes) > maxPasswordLength {
+		runes = runes[:maxPasswordLength]
+	}
+
+	dictionaries := e.dictionaries
+	var inputs []string
+	for _, input := range userInputs {
+		if input = strings.ToLower(strings.TrimSpace(input)); input != "" {
+			inputs = append(inputs, input)
+		}
+	}
+	if len(inputs) > 0 {
+		dictionaries = append([]*rankedDictionary{newRankedDictionary(DictionaryUserInputs, inputs)}, dictionaries...)
+	}
+
+	guesses, sequence := e.mostGuessableSequence(runes, e.omnimatch(runes, dictionaries))
+	return Result{
+		Guesses:          guesses,
+		GuessesLog10:     math.Log10(guesses),
+		Score:            scoreFromGuesses(guesses),
+		CrackTimeSeconds: guesses / e.guessesPerSecond,
+		Sequence:         sequence,
+	}
+}
+
+// scoreFromGuesses 留出少量余量，让刚好落在阈值上的猜测次数归入较低的等级
+func scoreFromGuesses(guesses float64) int {
+	const delta = 5
+	switch {
+	case guesses < 1e3+delta:
+		return 0
+	case guesses < 1e6+delta:
+		return 1
+	case guesses < 1e8+delta:
+		return 2
+	case guesses < 1e10+delta:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// DisplayTime 将秒数转换为便于阅读的近似时间
+func DisplayTime(seconds float64) string {
+	const (
+		minute  = 60
+		hour    = minute * 60
+		day     = hour * 24
+		month   = day * 31
+		year    = month * 12
+		century = year * 100
+	)
+
+	var (
+		value float64
+		unit  string
+	)
+	switch {
+	case seconds < 1:
+		return "less than a second"
+	case seconds < minute:
+		value, unit = seconds, "second"
+	case seconds < hour:
+		value, unit = seconds/minute, "minute"
+	case seconds < day:
+		value, unit = seconds/hour, "hour"
+	case seconds < month:
+		value, unit = seconds/day, "day"
+	case seconds < year:
+		value, unit = seconds/month, "month"
+	case seconds < century:
+		value, unit = seconds/year, "year"
+	default:
+		return "centuries"
+	}
+
+	rounded := int(math.Round(value))
+	if rounded != 1 {
+		unit += "s"
+	}
+	return fmt.Sprintf("%d %s", rounded, unit)
+}
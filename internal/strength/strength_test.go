@@ -0,0 +1,157 @@
+package strength
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func newTestEstimator() *Estimator {
+	estimator := NewEstimator()
+	estimator.referenceYear = 2026
+	return estimator
+}
+
+func TestEstimatePatterns(t *testing.T) {
+	estimator := newTestEstimator()
+
+	tests := []struct {
+		password string
+		maxScore int
+		minScore int
+		patterns []string
+	}{
+		{"password", 0, 0, []string{"dictionary"}},
+		{"P@ssw0rd", 0, 0, []string{"dictionary", "l33t"}},
+		{"drowssap", 0, 0, []string{"dictionary"}},
+		{"zxcvbnm,./", 1, 0, []string{"spatial"}},
+		{"aaaaaaaa", 0, 0, []string{"repeat"}},
+		{"abcabcabc", 0, 0, []string{"repeat"}},
+		{"abcdefg", 0, 0, []string{"sequence"}},
+		{"1991-03-15", 1, 1, []string{"date"}},
+		{"correcthorsebatterystaple", 4, 4, []string{"dictionary"}},
+		{"kX9#mQ2$vL7@pR4!", 4, 4, nil},
+	}
+
+	for _, test := range tests {
+		result := estimator.Estimate(test.password, nil)
+		if result.Score < test.minScore || result.Score > test.maxScore {
+			t.Errorf("%q: expected score %d~%d, got %d (guesses 10^%.1f)", test.password, test.minScore, test.maxScore, result.Score, result.GuessesLog10)
+		}
+		if patterns := result.Patterns(); !slices.Equal(patterns, test.patterns) {
+			t.Errorf("%q: expected patterns %v, got %v", test.password, test.patterns, patterns)
+		}
+	}
+}
+
+func TestEstimateSequenceCoversPassword(t *testing.T) {
+	estimator := newTestEstimator()
+	password := "Tr0ub4dour&3-qwerty-2019"
+
+	result := estimator.Estimate(password, nil)
+	var tokens []string
+	next := 0
+	for _, match := range result.Sequence {
+		if match.I != next {
+			t.Fatalf("Sequence has a gap or overlap at %d: %+v", next, match)
+		}
+		tokens = append(tokens, match.Token)
+		next = match.J + 1
+	}
+	if joined := strings.Join(tokens, ""); joined != password {
+		t.Errorf("Sequence tokens %q do not rebuild the password", joined)
+	}
+}
+
+func TestEstimateUserInputs(t *testing.T) {
+	estimator := newTestEstimator()
+
+	without := estimator.Estimate("Acmecorp!2019", nil)
+	with := estimator.Estimate("Acmecorp!2019", []string{"AcmeCorp", "alice"})
+	if !with.ContainsUserInput() || without.ContainsUserInput() {
+		t.Fatalf("Expected only the estimate with user inputs to match them")
+	}
+	if with.Guesses >= without.Guesses {
+		t.Errorf("Expected user inputs to lower the guesses, got %.0f >= %.0f", with.Guesses, without.Guesses)
+	}
+	if !slices.Contains(with.Patterns(), "user_input") {
+		t.Errorf("Expected user_input pattern, got %v", with.Patterns())
+	}
+}
+
+func TestDateMatches(t *testing.T) {
+	tests := []struct {
+		token            string
+		year, month, day int
+	}{
+		{"1991-03-15", 1991, 3, 15},
+		{"15.3.91", 1991, 3, 15},
+		{"19910315", 1991, 3, 15},
+		{"3/15/2001", 2001, 3, 15},
+	}
+
+	for _, test := range tests {
+		matches := dateMatches([]rune(test.token), 2026)
+		if len(matches) != 1 {
+			t.Errorf("%q: expected one date match, got %d", test.token, len(matches))
+			continue
+		}
+		match := matches[0]
+		if match.Year != test.year || match.Month != test.month || match.Day != test.day || match.Token != test.token {
+			t.Errorf("%q: unexpected date %d-%d-%d (%q)", test.token, match.Year, match.Month, match.Day, match.Token)
+		}
+	}
+
+	for _, match := range dateMatches([]rune("1991-03.15"), 2026) {
+		if match.Separator != "" {
+			t.Errorf("Expected mixed separators not to match, got %q", match.Token)
+		}
+	}
+}
+
+func TestSpatialMatches(t *testing.T) {
+	matches := spatialMatches([]rune("xqwertyx"))
+	if len(matches) != 1 || matches[0].Token != "qwerty" || matches[0].Turns != 1 {
+		t.Fatalf("Expected a single straight qwerty match, got %+v", matches)
+	}
+
+	matches = spatialMatches([]rune("!QAZ"))
+	if len(matches) != 1 || matches[0].ShiftedCount != 4 {
+		t.Fatalf("Expected a fully shifted match, got %+v", matches)
+	}
+
+	// 789在主键盘上也相邻，只有小键盘能覆盖整个7896
+	matches = spatialMatches([]rune("7896"))
+	if !slices.ContainsFunc(matches, func(match *Match) bool { return match.Graph == "keypad" && match.Token == "7896" }) {
+		t.Errorf("Expected a keypad match, got %+v", matches)
+	}
+}
+
+func TestEstimateLongPassword(t *testing.T) {
+	estimator := newTestEstimator()
+	result := estimator.Estimate(strings.Repeat("Zq8!", 1000), nil)
+	if result.Score > 1 || !slices.Equal(result.Patterns(), []string{"repeat"}) {
+		t.Errorf("Expected a long repeat to be weak, got score %d %v", result.Score, result.Patterns())
+	}
+	// 只分析前maxPasswordLength个字符
+	if last := result.Sequence[len(result.Sequence)-1]; last.J != maxPasswordLength-1 {
+		t.Errorf("Expected the estimate to stop at %d characters, got %d", maxPasswordLength, last.J+1)
+	}
+}
+
+func TestDisplayTime(t *testing.T) {
+	tests := map[float64]string{
+		0.5:               "less than a second",
+		1:                 "1 second",
+		90:                "2 minutes",
+		3600:              "1 hour",
+		86400 * 3:         "3 days",
+		86400*31*12*5 + 1: "5 years",
+		1e12:              "centuries",
+	}
+	for seconds, expected := range tests {
+		if display := DisplayTime(seconds); display != expected {
+			t.Errorf("DisplayTime(%v) = %q, expected %q", seconds, display, expected)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+// Package totp 解析凭据中保存的一次性密码配置并按RFC 4226/6238生成验证码
+// 支持裸base32密钥、otpauth:// URI以及Bitwarden、Enpass导出的steam://密钥
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 密钥的保存形式
+const (
+	FormatBase32  = "base32"
+	FormatOTPAuth = "otpauth"
+	FormatSteam   = "steam"
+)
+
+// 一次性密码类型
+const (
+	TypeTOTP = "totp"
+	TypeHOTP = "hotp"
+)
+
+const (
+	DefaultAlgorithm = "SHA1"
+	DefaultDigits    = 6
+	DefaultPeriod    = 30 // 秒
+
+	// steamDigits Steam令牌固定为5个字符
+	steamDigits = 5
+	// maxDigits 31位截断值最多10位十进制数
+	maxDigits = 10
+)
+
+// steamAlphabet Steam令牌使用的26个字符
+const steamAlphabet = "23456789BCDFGHJKMNPQRTVWXY"
+
+var (
+	ErrInvalidURI           = errors.New("invalid otpauth URI")
+	ErrUndecodableSecret    = errors.New("secret is not valid base32")
+	ErrUnsupportedAlgorithm = errors.New("unsupported algorithm")
+)
+
+// Key 解析后的一次性密码配置
+type Key struct {
+	Format    string
+	Type      string
+	Secret    []byte
+	Issuer    string
+	Account   string
+	Algorithm string // SHA1、SHA256或SHA512
+	Digits    int
+	Period    int
+	Counter   uint64 // 仅HOTP
+	Steam     bool
+}
+
+// SecretBits 密钥的位数
+func (k *Key) SecretBits() int {
+	return len(k.Secret) * 8
+}
+
+// Parse 解析TOTP字段；结构错误（无法解析的URI、无法解码的密钥、不支持的算法）返回包装了对应哨兵错误的error
+// 位数和周期不合常规时不报错，由调用方判断
+func Parse(raw string) (*Key, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, fmt.Errorf("%w: empty value", ErrInvalidURI)
+	}
+
+	scheme, rest, found := strings.Cut(raw, "://")
+	if !found {
+		return parseSecret(FormatBase32, raw)
+	}
+	switch strings.ToLower(scheme) {
+	case "otpauth":
+		return parseOTPAuth(raw)
+	case "steam":
+		key, err := parseSecret(FormatSteam, rest)
+		if err != nil {
+			return nil, err
+		}
+		key.Steam = true
+		key.Digits = steamDigits
+		key.Issuer = "Steam"
+		return key, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown scheme %q", ErrInvalidURI, scheme)
+	}
+}
+
+func parseSecret(format, secret string) (*Key, error) {
+	decoded, err := decodeBase32(secret)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{
+		Format:    format,
+		Type:      TypeTOTP,
+		Secret:    decoded,
+		Algorithm: DefaultAlgorithm,
+		Digits:    DefaultDigits,
+		Period:    DefaultPeriod,
+	}, nil
+}
+
+// parseOTPAuth 解析otpauth://TYPE/LABEL?secret=...&issuer=...&algorithm=...&digits=...&period=...
+// KeePassXC等客户端用encoder=steam表示Steam令牌
+func parseOTPAuth(raw string) (*Key, error) {
+	// url.Parse的错误包含原始URI，不能透出密钥
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed URI", ErrInvalidURI)
+	}
+
+	otpType := strings.ToLower(u.Host)
+	if otpType != TypeTOTP && otpType != TypeHOTP {
+		return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidURI, u.Host)
+	}
+
+	query := u.Query()
+	secret := query.Get("secret")
+	if secret == "" {
+		return nil, fmt.Errorf("%w: missing secret", ErrInvalidURI)
+	}
+	key, err := parseSecret(FormatOTPAuth, secret)
+	if err != nil {
+		return nil, err
+	}
+	key.Type = otpType
+
+	label := strings.TrimPrefix(u.Path, "/")
+	if issuer, account, found := strings.Cut(label, ":"); found {
+		key.Issuer, key.Account = strings.TrimSpace(issuer), strings.TrimSpace(account)
+	} else {
+		key.Account = strings.TrimSpace(label)
+	}
+	if issuer := query.Get("issuer"); issuer != "" {
+		key.Issuer = issuer
+	}
+
+	if algorithm := query.Get("algorithm"); algorithm != "" {
+		key.Algorithm = strings.ToUpper(strings.ReplaceAll(algorithm, "-", ""))
+		if newHash(key.Algorithm) == nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, algorithm)
+		}
+	}
+	if digits := query.Get("digits"); digits != "" {
+		if key.Digits, err = strconv.Atoi(digits); err != nil {
+			return nil, fmt.Errorf("%w: invalid digits %q", ErrInvalidURI, digits)
+		}
+	}
+	if period := query.Get("period"); period != "" {
+		if key.Period, err = strconv.Atoi(period); err != nil || key.Period <= 0 {
+			return nil, fmt.Errorf("%w: invalid period %q", ErrInvalidURI, period)
+		}
+	}
+	if counter := query.Get("counter"); counter != "" && otpType == TypeHOTP {
+		if key.Counter, err = strconv.ParseUint(counter, 10, 64); err != nil {
+			return nil, fmt.Errorf("%w: invalid counter %q", ErrInvalidURI, counter)
+		}
+	}
+	if strings.EqualFold(query.Get("encoder"), "steam") {
+		key.Steam = true
+		key.Digits = steamDigits
+	}
+
+	return key, nil
+}
+
+// decodeBase32 忽略大小写、空格、连字符和填充
+func decodeBase32(secret string) ([]byte, error) {
+	cleaned := strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' || r == '=' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(secret))
+	if cleaned == "" {
+		return nil, fmt.Errorf("%w: empty secret", ErrUndecodableSecret)
+	}
+	decoded, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(cleaned)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUndecodableSecret, err)
+	}
+	if len(decoded) == 0 {
+		return nil, fmt.Errorf("%w: empty secret", ErrUndecodableSecret)
+	}
+	return decoded, nil
+}
+
+func newHash(algorithm string) func() hash.Hash {
+	switch algorithm {
+	case "SHA1":
+		return sha1.New
+	case "SHA256":
+		return sha256.New
+	case "SHA512":
+		return sha512.New
+	default:
+		return nil
+	}
+}
+
+// Code 生成t时刻的验证码（HOTP使用Counter，与t无关）
+func (k *Key) Code(t time.Time) (string, error) {
+	hashFunc := newHash(k.Algorithm)
+	if hashFunc == nil {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, k.Algorithm)
+	}
+	if len(k.Secret) == 0 {
+		return "", fmt.Errorf("%w: empty secret", ErrUndecodableSecret)
+	}
+	if !k.Steam && (k.Digits < 1 || k.Digits > maxDigits) {
+		return "", fmt.Errorf("digits must be between 1 and %d, got %d", maxDigits, k.Digits)
+	}
+
+	counter := k.Counter
+	if k.Type != TypeHOTP {
+		if k.Period <= 0 {
+			return "", fmt.Errorf("period must be positive, got %d", k.Period)
+		}
+		counter = uint64(t.Unix()) / uint64(k.Period)
+	}
+
+	var message [8]byte
+	binary.BigEndian.PutUint64(message[:], counter)
+	mac := hmac.New(hashFunc, k.Secret)
+	mac.Write(message[:])
+	sum := mac.Sum(nil)
+
+	// RFC 4226 动态截断
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	if k.Steam {
+		code := make([]byte, steamDigits)
+		for i := range code {
+			code[i] = steamAlphabet[value%uint32(len(steamAlphabet))]
+			value /= uint32(len(steamAlphabet))
+		}
+		return string(code), nil
+	}
+
+	modulus := uint64(1)
+	for range k.Digits {
+		modulus *= 10
+	}
+	return fmt.Sprintf("%0*d", k.Digits, uint64(value)%modulus), nil
+}
@@ -0,0 +1,113 @@
+package totp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 6238 附录B的测试向量
+func TestCodeRFC6238(t *testing.T) {
+	secrets := map[string]string{
+		"SHA1":   "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+		"SHA256": "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZA",
+		"SHA512": "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNA",
+	}
+	testCases := []struct {
+		unix      int64
+		algorithm string
+		expected  string
+	}{
+		{59, "SHA1", "94287082"},
+		{59, "SHA256", "46119246"},
+		{59, "SHA512", "90693936"},
+		{1111111109, "SHA1", "07081804"},
+		{1234567890, "SHA256", "91819424"},
+		{2000000000, "SHA512", "38618901"},
+	}
+
+	for _, tc := range testCases {
+		key, err := Parse("otpauth://totp/Test?digits=8&algorithm=" + tc.algorithm + "&secret=" + secrets[tc.algorithm])
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		code, err := key.Code(time.Unix(tc.unix, 0))
+		if err != nil {
+			t.Fatalf("Code failed: %v", err)
+		}
+		if code != tc.expected {
+			t.Errorf("%s at %d: expected %s, got %s", tc.algorithm, tc.unix, tc.expected, code)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	key, err := Parse("otpauth://totp/ACME%20Co:alice@example.com?secret=JBSWY3DPEHPK3PXP&issuer=ACME+Co&period=60&digits=8")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if key.Format != FormatOTPAuth || key.Issuer != "ACME Co" || key.Account != "alice@example.com" {
+		t.Errorf("Unexpected label: %+v", key)
+	}
+	if key.Digits != 8 || key.Period != 60 || key.Algorithm != "SHA1" || key.SecretBits() != 80 {
+		t.Errorf("Unexpected parameters: %+v", key)
+	}
+
+	// 裸密钥忽略大小写、空格和填充
+	key, err = Parse("jbsw y3dp ehpk 3pxp")
+	if err != nil || key.Format != FormatBase32 || key.Digits != DefaultDigits || key.Period != DefaultPeriod {
+		t.Errorf("Unexpected raw secret result: %+v, %v", key, err)
+	}
+
+	for _, raw := range []string{"steam://JBSWY3DPEHPK3PXP", "otpauth://totp/Steam:alice?secret=JBSWY3DPEHPK3PXP&encoder=steam"} {
+		key, err := Parse(raw)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", raw, err)
+		}
+		code, err := key.Code(time.Unix(1700000000, 0))
+		if err != nil || !key.Steam || len(code) != 5 || strings.Trim(code, steamAlphabet) != "" {
+			t.Errorf("Unexpected Steam code for %q: %q, %v", raw, code, err)
+		}
+	}
+
+	key, err = Parse("otpauth://hotp/Test?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ&counter=1")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	// RFC 4226 附录D：计数器为1时的验证码
+	if code, _ := key.Code(time.Now()); code != "287082" {
+		t.Errorf("Expected HOTP code 287082, got %s", code)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	testCases := []struct {
+		raw      string
+		expected error
+	}{
+		{"", ErrInvalidURI},
+		{"otpauth://totp/Test", ErrInvalidURI},
+		{"otpauth://push/Test?secret=JBSWY3DPEHPK3PXP", ErrInvalidURI},
+		{"otpauth://totp/Test?secret=JBSWY3DPEHPK3PXP&period=0", ErrInvalidURI},
+		{"otpauth://totp/Test?secret=JBSWY3DPEHPK3PXP&digits=six", ErrInvalidURI},
+		{"https://example.com/2fa", ErrInvalidURI},
+		{"not-base32-1890!", ErrUndecodableSecret},
+		{"otpauth://totp/Test?secret=ABC1", ErrUndecodableSecret},
+		{"otpauth://totp/Test?secret=JBSWY3DPEHPK3PXP&algorithm=MD5", ErrUnsupportedAlgorithm},
+	}
+
+	for _, tc := range testCases {
+		if _, err := Parse(tc.raw); !errors.Is(err, tc.expected) {
+			t.Errorf("Parse(%q): expected %v, got %v", tc.raw, tc.expected, err)
+		}
+	}
+
+	key, err := Parse("otpauth://totp/Test?secret=JBSWY3DPEHPK3PXP&digits=12")
+	if err != nil {
+		t.Fatalf("Expected unusual digits to parse, got %v", err)
+	}
+	if _, err := key.Code(time.Now()); err == nil {
+		t.Error("Expected 12 digits to fail code generation")
+	}
+}
@@ -5,15 +5,27 @@ import "time"
 type DetectionType string
 
 const (
-	DetectionMissing2FA     DetectionType = "missing_2fa"
-	DetectionMissingPasskey DetectionType = "missing_passkey"
+	DetectionMissing2FA         DetectionType = "missing_2fa"
+	DetectionMissingPasskey     DetectionType = "missing_passkey"
+	DetectionPwnedPassword      DetectionType = "pwned_password"
+	DetectionPasswordReuse      DetectionType = "password_reuse"
+	DetectionSimilarPassword    DetectionType = "similar_password"
+	DetectionWeakPassword       DetectionType = "weak_password"
+	DetectionIdentityInPassword DetectionType = "identity_in_password"
+	DetectionInsecureURL        DetectionType = "insecure_url"
+	DetectionLookalikeDomain    DetectionType = "lookalike_domain"
+	DetectionTOTPMisconfigured  DetectionType = "totp_misconfigured"
+	DetectionWeakSecondFactor   DetectionType = "weak_second_factor"
+	DetectionSecretInNotes      DetectionType = "secret_in_notes"
 )
 
 type Severity string
 
 const (
-	SeverityMedium Severity = "medium"
-	SeverityHigh   Severity = "high"
+	SeverityLow      Severity = "low"
+	SeverityMedium   Severity = "medium"
+	SeverityHigh     Severity = "high"
+	SeverityCritical Severity = "critical"
 )
 
 // DetectionResult 检测结果
@@ -24,6 +36,8 @@ type DetectionResult struct {
 	Severity     Severity               `json:"severity"`
 	Message      string                 `json:"message"`
 	Metadata     map[string]interface{} `json:"metadata"`
+	Sources      []CredentialSource     `json:"sources,omitempty"`
+	Category     string                 `json:"category,omitempty"`
 }
 
 type AuditReport struct {
@@ -36,4 +50,13 @@ type AuditSummary struct {
 	TotalCredentials int                    `json:"total_credentials"`
 	IssuesFound      int                    `json:"issues_found"`
 	ByType           map[DetectionType]int  `json:"by_type"`
+	ByCategory       map[string]int         `json:"by_category,omitempty"` // 按凭据原始类别统计的问题数
+	Sources          []SourceSummary        `json:"sources,omitempty"`
+}
+
+// SourceSummary 单个输入来源的统计
+type SourceSummary struct {
+	CredentialSource
+	Credentials int `json:"credentials"` // 来自该来源的凭据数（含与其他来源重复的）
+	Duplicates  int `json:"duplicates"`  // 已在先前来源中出现而被合并的凭据数
 } 
\ No newline at end of file
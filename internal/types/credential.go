@@ -13,6 +13,14 @@ type Credential struct {
 	Tags     []string `json:"tags,omitempty"`
 	TOTP     string   `json:"totp,omitempty"`    // TOTP密钥或URI
 	Passkey  string   `json:"passkey,omitempty"` // Passkey信息
+	Category string   `json:"category,omitempty"` // 密码管理器中的原始类别
+	Sources  []CredentialSource `json:"sources,omitempty"` // 凭据来源，去重合并后可能有多个
+}
+
+// CredentialSource 凭据来源：输入文件及识别出的解析器
+type CredentialSource struct {
+	File     string `json:"file"`
+	Provider string `json:"provider"`
 }
 
 type AuditContext struct {